@@ -2,9 +2,11 @@ package config
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/erneap/models/v2/converters"
@@ -12,7 +14,36 @@ import (
 	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
-func ConnectDB() *mongo.Client {
+// Options are the database connection settings used by Open.
+type Options struct {
+	// URI is the mongo connection string, when blank it is built from the
+	// MONGO_* configuration values.
+	URI string
+	// ConnectTimeout limits each connection attempt, including the ping.
+	ConnectTimeout time.Duration
+	// ServerSelectionTimeout limits how long an operation waits for a server.
+	ServerSelectionTimeout time.Duration
+	// MinPoolSize and MaxPoolSize size the connection pool, zero uses the
+	// driver's defaults.
+	MinPoolSize uint64
+	MaxPoolSize uint64
+	// RetryAttempts is the number of extra connection attempts after the
+	// first one fails, with RetryDelay between each attempt.
+	RetryAttempts int
+	RetryDelay    time.Duration
+	// HealthInterval is how often the connection is checked in the
+	// background, logging when the check fails (the driver reconnects to the
+	// servers on its own).  Zero disables the background check.
+	HealthInterval time.Duration
+}
+
+// DefaultOptions provides the connection settings from the configuration
+// (environment or .env file), with defaults for any not given:
+// MONGO_URI or MONGO_USER/MONGO_PASSWD/MONGO_HOST/MONGO_PORT/MONGO_PREFIX,
+// MONGO_CONNECT_TIMEOUT (seconds), MONGO_SELECT_TIMEOUT (seconds),
+// MONGO_MIN_POOL, MONGO_MAX_POOL, MONGO_RETRIES, MONGO_RETRY_DELAY (seconds)
+// and MONGO_HEALTH_INTERVAL (seconds).
+func DefaultOptions() Options {
 	user := Config("MONGO_USER")
 	passwd := strings.TrimSpace(Config("MONGO_PASSWD"))
 	host := Config("MONGO_HOST")
@@ -22,22 +53,250 @@ func ConnectDB() *mongo.Client {
 	if user != "" {
 		uri = fmt.Sprintf("mongodb://%s:%s@%s:%s/?%s", user, passwd, host, port, prefix)
 	}
-	opts := options.Client().ApplyURI(uri)
-	ctx, _ := context.WithTimeout(context.Background(), 10*time.Second)
-	client, err := mongo.Connect(ctx, opts)
+	opts := Options{
+		URI:                    uri,
+		ConnectTimeout:         10 * time.Second,
+		ServerSelectionTimeout: 30 * time.Second,
+		RetryAttempts:          2,
+		RetryDelay:             2 * time.Second,
+	}
+	if value := Config("MONGO_CONNECT_TIMEOUT"); value != "" {
+		opts.ConnectTimeout = time.Duration(converters.ParseInt(value)) * time.Second
+	}
+	if value := Config("MONGO_SELECT_TIMEOUT"); value != "" {
+		opts.ServerSelectionTimeout = time.Duration(converters.ParseInt(value)) *
+			time.Second
+	}
+	if value := Config("MONGO_MIN_POOL"); value != "" {
+		opts.MinPoolSize = uint64(converters.ParseUint(value))
+	}
+	if value := Config("MONGO_MAX_POOL"); value != "" {
+		opts.MaxPoolSize = uint64(converters.ParseUint(value))
+	}
+	if value := Config("MONGO_RETRIES"); value != "" {
+		opts.RetryAttempts = converters.ParseInt(value)
+	}
+	if value := Config("MONGO_RETRY_DELAY"); value != "" {
+		opts.RetryDelay = time.Duration(converters.ParseInt(value)) * time.Second
+	}
+	if value := Config("MONGO_HEALTH_INTERVAL"); value != "" {
+		opts.HealthInterval = time.Duration(converters.ParseInt(value)) * time.Second
+	}
+	return opts
+}
+
+func (o Options) clientOptions() *options.ClientOptions {
+	opts := options.Client().ApplyURI(o.URI)
+	if o.ConnectTimeout > 0 {
+		opts.SetConnectTimeout(o.ConnectTimeout)
+	}
+	if o.ServerSelectionTimeout > 0 {
+		opts.SetServerSelectionTimeout(o.ServerSelectionTimeout)
+	}
+	if o.MinPoolSize > 0 {
+		opts.SetMinPoolSize(o.MinPoolSize)
+	}
+	if o.MaxPoolSize > 0 {
+		opts.SetMaxPoolSize(o.MaxPoolSize)
+	}
+	return opts
+}
+
+// ErrNotOpen is returned when the database is used after it was closed.
+var ErrNotOpen = errors.New("database connection is not open")
+
+// dbClient is the current database client.  It is nil until the database is
+// opened, either explicitly with Open or on first use of Client or
+// GetCollection, and is only used through Client (DB is a copy kept for older
+// code).
+var dbClient *mongo.Client
+
+// DB is the client last connected by Open, or nil when the database isn't
+// open.
+//
+// Deprecated: use Client, which opens the database when needed and gives the
+// error instead.
+var DB *mongo.Client
+
+var LogLevel int = SetLogLevel()
+
+var (
+	openMutex sync.Mutex
+	dbMutex   sync.RWMutex
+	dbClosed  bool
+	dbMonitor context.CancelFunc
+)
+
+// connect makes a single connection attempt, verified with a ping.
+func connect(ctx context.Context, opts Options) (*mongo.Client, error) {
+	if opts.ConnectTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, opts.ConnectTimeout)
+		defer cancel()
+	}
+	client, err := mongo.Connect(ctx, opts.clientOptions())
 	if err != nil {
-		log.Fatal(err)
+		return nil, err
 	}
 
 	// ping the database
-	err = client.Ping(ctx, nil)
+	if err = client.Ping(ctx, nil); err != nil {
+		client.Disconnect(context.Background())
+		return nil, err
+	}
+	return client, nil
+}
+
+// connectWithRetry makes the first connection attempt plus the number of
+// retries given in the options, stopping early if the context is done.
+func connectWithRetry(ctx context.Context, opts Options) (*mongo.Client, error) {
+	var err error
+	for attempt := 0; attempt <= opts.RetryAttempts; attempt++ {
+		if attempt > 0 {
+			log.Printf("database connection attempt %d failed: %s", attempt,
+				err.Error())
+			select {
+			case <-ctx.Done():
+				return nil, ctx.Err()
+			case <-time.After(opts.RetryDelay):
+			}
+		}
+		var client *mongo.Client
+		client, err = connect(ctx, opts)
+		if err == nil {
+			return client, nil
+		}
+	}
+	return nil, err
+}
+
+// Open connects to the database with the options given, replacing any current
+// connection.  Nothing is connected when the package is imported, so
+// applications call Open at startup (or rely on the first use of Client).
+func Open(ctx context.Context, opts Options) error {
+	client, err := connectWithRetry(ctx, opts)
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
 
+	dbMutex.Lock()
+	old := dbClient
+	dbClient = client
+	DB = client
+	dbClosed = false
+	if dbMonitor != nil {
+		dbMonitor()
+		dbMonitor = nil
+	}
+	if opts.HealthInterval > 0 {
+		var monitorCtx context.Context
+		monitorCtx, dbMonitor = context.WithCancel(context.Background())
+		go monitor(monitorCtx, opts.HealthInterval)
+	}
+	dbMutex.Unlock()
+
+	if old != nil {
+		old.Disconnect(ctx)
+	}
+	return nil
+}
+
+// Close stops the background health check and disconnects from the database.
+// The database can't be used again until Open is called.
+func Close(ctx context.Context) error {
+	dbMutex.Lock()
+	client := dbClient
+	dbClient = nil
+	DB = nil
+	dbClosed = true
+	if dbMonitor != nil {
+		dbMonitor()
+		dbMonitor = nil
+	}
+	dbMutex.Unlock()
+
+	if client == nil {
+		return nil
+	}
+	return client.Disconnect(ctx)
+}
+
+// Client provides the current database client, opening the database with the
+// default options if it hasn't been opened yet.
+func Client(ctx context.Context) (*mongo.Client, error) {
+	dbMutex.RLock()
+	client := dbClient
+	closed := dbClosed
+	dbMutex.RUnlock()
+	if client != nil {
+		return client, nil
+	}
+	if closed {
+		return nil, ErrNotOpen
+	}
+
+	// only one caller opens the database, the others wait for it
+	openMutex.Lock()
+	defer openMutex.Unlock()
+	dbMutex.RLock()
+	client = dbClient
+	dbMutex.RUnlock()
+	if client != nil {
+		return client, nil
+	}
+	if err := Open(ctx, DefaultOptions()); err != nil {
+		return nil, err
+	}
+	dbMutex.RLock()
+	defer dbMutex.RUnlock()
+	return dbClient, nil
+}
+
+// ConnectDB provides the current database client, opening the database with
+// the default options if it hasn't been opened yet, and stops the program if
+// it can't be opened.
+//
+// Deprecated: use Open at startup and Client, which give the error instead.
+func ConnectDB() *mongo.Client {
+	client, err := Client(context.Background())
+	if err != nil {
+		log.Fatal(err)
+	}
 	return client
 }
 
+// HealthCheck pings the database, returning an error if it can't be reached.
+func HealthCheck(ctx context.Context) error {
+	dbMutex.RLock()
+	client := dbClient
+	dbMutex.RUnlock()
+	if client == nil {
+		return ErrNotOpen
+	}
+	return client.Ping(ctx, nil)
+}
+
+// monitor checks the connection at each interval and reports when the check
+// fails, until the context is cancelled by Open or Close.  The client isn't
+// replaced, since others may be using it, and the driver reconnects on its
+// own once the servers can be reached.
+func monitor(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			checkCtx, cancel := context.WithTimeout(ctx, interval)
+			if err := HealthCheck(checkCtx); err != nil && ctx.Err() == nil {
+				log.Printf("database health check failed: %s", err.Error())
+			}
+			cancel()
+		}
+	}
+}
+
 func SetLogLevel() int {
 	answer := 0
 	level := Config("LOGLEVEL")
@@ -47,14 +306,31 @@ func SetLogLevel() int {
 	return answer
 }
 
-var DB *mongo.Client = ConnectDB()
-
-var LogLevel int = SetLogLevel()
+// Collection provides the requested database collection from the current
+// client, opening the database if needed.  The names are logical names, which
+// CollectionName turns into the names used in the database.
+func Collection(ctx context.Context, dbName,
+	collectionName string) (*mongo.Collection, error) {
+	client, err := Client(ctx)
+	if err != nil {
+		return nil, err
+	}
+	dbName, collectionName = CollectionName(dbName, collectionName)
+	return client.Database(dbName).Collection(collectionName), nil
+}
 
-// get the requested database collection
-func GetCollection(client *mongo.Client, dbName, collectionName string) *mongo.Collection {
-	collection := client.Database(dbName).Collection(collectionName)
-	return collection
+// GetCollection provides the requested database collection from the client
+// given, or from the current client when it is nil (opening the database if
+// needed), giving an error when the database can't be opened.
+//
+// Deprecated: use Collection, which takes a context.
+func GetCollection(client *mongo.Client, dbName,
+	collectionName string) (*mongo.Collection, error) {
+	if client == nil {
+		return Collection(context.Background(), dbName, collectionName)
+	}
+	dbName, collectionName = CollectionName(dbName, collectionName)
+	return client.Database(dbName).Collection(collectionName), nil
 }
 
 // no comment
@@ -0,0 +1,20 @@
+package config
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestGetCollectionClosed(t *testing.T) {
+	if err := Close(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	col, err := GetCollection(nil, "scheduler", "teams")
+	if !errors.Is(err, ErrNotOpen) || col != nil {
+		t.Errorf("GetCollection = %v, %v, want %v", col, err, ErrNotOpen)
+	}
+	if DB != nil {
+		t.Error("DB kept after Close")
+	}
+}
@@ -0,0 +1,107 @@
+package config
+
+import (
+	"strings"
+	"sync"
+)
+
+// The logical database and collection names used by the models.  The names
+// actually used in mongo come from CollectionName, so several environments
+// (dev, test, prod) can share one cluster by giving each a database prefix,
+// and any database or collection can be renamed by an override.
+const (
+	DBScheduler    = "scheduler"
+	DBAuthenticate = "authenticate"
+	DBGeneral      = "general"
+	DBMetrics      = "metrics2"
+
+	ColEmployees     = "employees"
+	ColEmployeeWork  = "employeework"
+	ColEmployeeAudit = "employeeaudit"
+	ColShiftSwaps    = "shiftswaps"
+	ColTeams         = "teams"
+	ColNotifications = "notifications"
+	ColUsers         = "users"
+	ColLogs          = "logs"
+	ColReports       = "reports"
+	ColReportTypes   = "reporttypes"
+	ColMigrations    = "migrations"
+	ColArchive       = "archive"
+	ColMissions      = "missions"
+	ColOutages       = "outages"
+)
+
+var (
+	namingOnce      sync.Once
+	namingMutex     sync.RWMutex
+	namingPrefix    string
+	namingOverrides = make(map[string]string)
+)
+
+// loadNaming reads the naming from the configuration (environment or .env
+// file) the first time it is needed: MONGO_DB_PREFIX is added to the front of
+// every database name, and MONGO_NAMES is a comma separated list of overrides
+// like "scheduler=sched,metrics2.outages=metrics2.groundoutages".
+func loadNaming() {
+	namingOnce.Do(func() {
+		prefix := Config("MONGO_DB_PREFIX")
+		names := Config("MONGO_NAMES")
+		namingMutex.Lock()
+		defer namingMutex.Unlock()
+		namingPrefix = prefix
+		for _, item := range strings.Split(names, ",") {
+			parts := strings.SplitN(item, "=", 2)
+			if len(parts) == 2 {
+				namingOverrides[strings.TrimSpace(parts[0])] =
+					strings.TrimSpace(parts[1])
+			}
+		}
+	})
+}
+
+// SetNamePrefix sets the prefix added to every database name, replacing the
+// configured one.
+func SetNamePrefix(prefix string) {
+	loadNaming()
+	namingMutex.Lock()
+	defer namingMutex.Unlock()
+	namingPrefix = prefix
+}
+
+// SetNameOverride renames a database ("scheduler") or a collection
+// ("scheduler.employees") to the name given.  A collection can be renamed
+// within its database ("emps") or moved to another ("sched.emps").  A blank
+// name removes the override.
+func SetNameOverride(logical, name string) {
+	loadNaming()
+	namingMutex.Lock()
+	defer namingMutex.Unlock()
+	if name == "" {
+		delete(namingOverrides, logical)
+	} else {
+		namingOverrides[logical] = name
+	}
+}
+
+// CollectionName provides the mongo database and collection names for a
+// logical database and collection, applying the overrides and then the
+// prefix.
+func CollectionName(dbName, collectionName string) (string, string) {
+	loadNaming()
+	namingMutex.RLock()
+	defer namingMutex.RUnlock()
+	db := dbName
+	if name, ok := namingOverrides[dbName]; ok {
+		db = name
+	}
+	col := collectionName
+	if name, ok := namingOverrides[dbName+"."+collectionName]; ok {
+		if parts := strings.SplitN(name, ".", 2); len(parts) == 2 {
+			db = parts[0]
+			col = parts[1]
+		} else {
+			col = name
+		}
+	}
+	return namingPrefix + db, col
+}
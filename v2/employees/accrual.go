@@ -0,0 +1,273 @@
+package employees
+
+import (
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/erneap/models/v2/labor"
+)
+
+// LeaveBalanceMonth is a month of a leave balance, with the hours available
+// at the end of the month.
+type LeaveBalanceMonth struct {
+	Month     time.Time `json:"month"`
+	Accrued   float64   `json:"accrued"`
+	Used      float64   `json:"used"`
+	Available float64   `json:"available"`
+}
+
+// LeaveBalance is the employee's balance of a leave code for a year:  the
+// hours carried over from the year before, accrued and used (the actual
+// leave taken) in the year, and available at the end of it.
+type LeaveBalance struct {
+	Year      int                 `json:"year"`
+	Code      string              `json:"code"`
+	Carryover float64             `json:"carryover"`
+	Accrued   float64             `json:"accrued"`
+	Used      float64             `json:"used"`
+	Available float64             `json:"available"`
+	Months    []LeaveBalanceMonth `json:"months"`
+}
+
+// GetServiceDate provides the date the employee's service started, the start
+// of the first assignment.
+func (e *Employee) GetServiceDate() time.Time {
+	answer := time.Time{}
+	for _, asgmt := range e.Assignments {
+		if answer.IsZero() || asgmt.StartDate.Before(answer) {
+			answer = asgmt.StartDate
+		}
+	}
+	return answer
+}
+
+// GetLeaveBalance computes the employee's balance of the policy's leave code
+// for the year.  The carryover is what was left of the previous year's
+// stored balance, limited by the policy, or nothing without one.  Accrual
+// starts after the waiting period (prorated for annual accrual), uses the
+// rate for the years of service on each period's end and stops while the
+// available hours are at the policy's maximum.
+func (e *Employee) GetLeaveBalance(policy labor.AccrualPolicy,
+	year int) LeaveBalance {
+	answer := LeaveBalance{
+		Year: year,
+		Code: policy.Code,
+	}
+	for _, al := range e.Balances {
+		if al.Year == year-1 && al.IsCode(policy.Code) {
+			left := al.Annual + al.Carryover - e.getLeaveUsed(policy.Code, year-1)
+			answer.Carryover = policy.GetCarryover(left)
+		}
+	}
+	for m := 1; m <= 12; m++ {
+		answer.Months = append(answer.Months, LeaveBalanceMonth{
+			Month: time.Date(year, time.Month(m), 1, 0, 0, 0, 0, time.UTC),
+		})
+	}
+
+	var used []LeaveDay
+	for _, lv := range e.Leaves {
+		if lv.LeaveDate.Year() == year && strings.EqualFold(lv.Code, policy.Code) &&
+			strings.EqualFold(lv.Status, "actual") {
+			used = append(used, lv)
+		}
+	}
+	sort.Sort(ByLeaveDay(used))
+
+	service := e.GetServiceDate()
+	eligible := service.AddDate(0, 0, policy.WaitingDays)
+	ends, share := policy.GetPeriodEnds(year)
+	available := answer.Carryover
+	u := 0
+	for _, end := range ends {
+		if end.Before(eligible) {
+			if policy.Frequency != labor.AccrueAnnually ||
+				eligible.Year() != year {
+				continue
+			}
+			// annual accrual for the rest of the year once eligible.
+			next := time.Date(year+1, 1, 1, 0, 0, 0, 0, time.UTC)
+			share = next.Sub(eligible).Hours() / next.Sub(end).Hours()
+			end = eligible
+		}
+		for ; u < len(used) && used[u].LeaveDate.Before(end); u++ {
+			available -= used[u].Hours
+		}
+		years := 0
+		for !service.IsZero() && !service.AddDate(years+1, 0, 0).After(end) {
+			years++
+		}
+		hours := policy.GetAnnualHours(years) * share
+		if policy.MaxBalance > 0.0 && available+hours > policy.MaxBalance {
+			hours = policy.MaxBalance - available
+			if hours < 0.0 {
+				hours = 0.0
+			}
+		}
+		available += hours
+		answer.Accrued += hours
+		answer.Months[end.Month()-1].Accrued += hours
+	}
+
+	for _, lv := range used {
+		answer.Used += lv.Hours
+		answer.Months[lv.LeaveDate.Month()-1].Used += lv.Hours
+	}
+	available = answer.Carryover
+	for m, month := range answer.Months {
+		available += month.Accrued - month.Used
+		month.Available = available
+		answer.Months[m] = month
+	}
+	answer.Available = available
+	return answer
+}
+
+// ApplyAccrualPolicy stores the year's annual leave of the policy's code as
+// the hours the policy accrues in the year, with the carryover from the year
+// before.  Vacation is stored without a code, like UpdateAnnualLeave.
+func (e *Employee) ApplyAccrualPolicy(policy labor.AccrualPolicy,
+	year int) AnnualLeave {
+	bal := e.GetLeaveBalance(policy, year)
+	code := policy.Code
+	if strings.EqualFold(code, "V") {
+		code = ""
+	}
+	e.updateAnnualLeave(code, year, bal.Accrued, bal.Carryover)
+	return AnnualLeave{
+		Year:      year,
+		Code:      code,
+		Annual:    bal.Accrued,
+		Carryover: bal.Carryover,
+	}
+}
+
+func (e *Employee) getLeaveUsed(code string, year int) float64 {
+	answer := 0.0
+	for _, lv := range e.Leaves {
+		if lv.LeaveDate.Year() == year && strings.EqualFold(lv.Code, code) &&
+			strings.EqualFold(lv.Status, "actual") {
+			answer += lv.Hours
+		}
+	}
+	return answer
+}
+
+// LeaveProjectionMonth is a month of a leave projection:  the hours accrued
+// (or expected to be), the leave taken, approved and requested, and the hours
+// projected available at the end of the month.  OverUsed flags a month ending
+// with more leave planned than available.
+type LeaveProjectionMonth struct {
+	Month     time.Time `json:"month"`
+	Accrued   float64   `json:"accrued"`
+	Taken     float64   `json:"taken"`
+	Approved  float64   `json:"approved"`
+	Requested float64   `json:"requested"`
+	Available float64   `json:"available"`
+	OverUsed  bool      `json:"overused,omitempty"`
+}
+
+// LeaveProjection is the employee's projected balance of a leave code through
+// the end of the year, as of a date.
+type LeaveProjection struct {
+	Year      int                    `json:"year"`
+	Code      string                 `json:"code"`
+	AsOf      time.Time              `json:"asof"`
+	Carryover float64                `json:"carryover"`
+	Accrued   float64                `json:"accrued"`
+	Taken     float64                `json:"taken"`
+	Approved  float64                `json:"approved"`
+	Requested float64                `json:"requested"`
+	Available float64                `json:"available"`
+	OverUsed  bool                   `json:"overused,omitempty"`
+	Months    []LeaveProjectionMonth `json:"months"`
+}
+
+// ProjectLeaveBalance projects the employee's vacation balance through the end
+// of the year, as of the date, by the default accrual policy.  Companies with
+// their own policies use ProjectLeaveBalanceWithPolicy.
+func (e *Employee) ProjectLeaveBalance(year int,
+	asOf time.Time) LeaveProjection {
+	return e.ProjectLeaveBalanceWithPolicy(labor.DefaultAccrualPolicy(), year,
+		asOf)
+}
+
+// ProjectLeaveBalanceWithPolicy projects the employee's balance of the
+// policy's leave code through the end of the year, as of the date.  The
+// year's stored annual leave, when there is one, gives the carryover and the
+// year's accrual (spread over the policy's periods), otherwise the policy's
+// balance does.  The leave taken is the actual leave, and the planned leave
+// is the approved leave not taken yet and the days of requests waiting for
+// approval from the date on.  A month ending with less than nothing available
+// is over used.
+func (e *Employee) ProjectLeaveBalanceWithPolicy(policy labor.AccrualPolicy,
+	year int, asOf time.Time) LeaveProjection {
+	asOf = time.Date(asOf.Year(), asOf.Month(), asOf.Day(), 0, 0, 0, 0,
+		time.UTC)
+	bal := e.GetLeaveBalance(policy, year)
+	answer := LeaveProjection{
+		Year:      year,
+		Code:      policy.Code,
+		AsOf:      asOf,
+		Carryover: bal.Carryover,
+	}
+	scale := 1.0
+	for _, al := range e.Balances {
+		if al.Year == year && al.IsCode(policy.Code) {
+			answer.Carryover = al.Carryover
+			if bal.Accrued > 0.0 {
+				scale = al.Annual / bal.Accrued
+			} else if al.Annual > 0.0 {
+				// nothing accrues by the policy, so the year's leave is given up
+				// front.
+				bal.Months[0].Accrued = al.Annual
+			}
+		}
+	}
+	for _, month := range bal.Months {
+		answer.Months = append(answer.Months, LeaveProjectionMonth{
+			Month:   month.Month,
+			Accrued: month.Accrued * scale,
+		})
+	}
+
+	for _, lv := range e.Leaves {
+		if lv.LeaveDate.Year() != year || !strings.EqualFold(lv.Code, policy.Code) {
+			continue
+		}
+		m := lv.LeaveDate.Month() - 1
+		if strings.EqualFold(lv.Status, "actual") {
+			answer.Months[m].Taken += lv.Hours
+		} else if strings.EqualFold(lv.Status, LeaveApproved) {
+			answer.Months[m].Approved += lv.Hours
+		}
+	}
+	for _, req := range e.Requests {
+		if req.GetStatus() != LeaveRequested {
+			continue
+		}
+		for _, day := range req.RequestedDays {
+			if day.LeaveDate.Year() == year && !day.LeaveDate.Before(asOf) &&
+				strings.EqualFold(day.Code, policy.Code) {
+				answer.Months[day.LeaveDate.Month()-1].Requested += day.Hours
+			}
+		}
+	}
+
+	available := answer.Carryover
+	for m, month := range answer.Months {
+		available += month.Accrued - month.Taken - month.Approved -
+			month.Requested
+		month.Available = available
+		month.OverUsed = available < 0.0
+		answer.Months[m] = month
+		answer.Accrued += month.Accrued
+		answer.Taken += month.Taken
+		answer.Approved += month.Approved
+		answer.Requested += month.Requested
+		answer.OverUsed = answer.OverUsed || month.OverUsed
+	}
+	answer.Available = available
+	return answer
+}
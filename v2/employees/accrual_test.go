@@ -0,0 +1,301 @@
+package employees
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/erneap/models/v2/labor"
+)
+
+func TestGetLeaveBalance(t *testing.T) {
+	// five years of service on March 15, 2025.
+	veteran := Employee{}
+	veteran.AddAssignment("s", "w", date(2020, time.March, 15))
+	veteran.Balances = []AnnualLeave{{Year: 2023, Annual: 120, Carryover: 40}}
+	veteran.Leaves = []LeaveDay{
+		{LeaveDate: date(2023, time.May, 1), Code: "V", Hours: 100,
+			Status: "ACTUAL"},
+		{LeaveDate: date(2024, time.February, 5), Code: "V", Hours: 8,
+			Status: "ACTUAL"},
+		// approved leave isn't used until it is taken.
+		{LeaveDate: date(2024, time.July, 5), Code: "V", Hours: 8,
+			Status: "APPROVED"},
+	}
+	newHire := Employee{}
+	newHire.AddAssignment("s", "w", date(2024, time.June, 1))
+
+	monthly := labor.AccrualPolicy{
+		Code: "V",
+		Rates: []labor.AccrualRate{
+			{Years: 0, Hours: 96},
+			{Years: 5, Hours: 144},
+		},
+		Frequency:      labor.AccrueMonthly,
+		CarryoverLimit: 40,
+	}
+	capped := monthly
+	capped.MaxBalance = 100
+	useOrLose := monthly
+	useOrLose.UseOrLose = true
+	waiting := labor.DefaultAccrualPolicy()
+	waiting.WaitingDays = 30
+
+	tests := []struct {
+		name      string
+		emp       Employee
+		policy    labor.AccrualPolicy
+		year      int
+		carryover float64
+		accrued   float64
+		used      float64
+		available float64
+	}{
+		{
+			name:      "carryover limited",
+			emp:       veteran,
+			policy:    monthly,
+			year:      2024,
+			carryover: 40,
+			accrued:   96,
+			used:      8,
+			available: 128,
+		},
+		{
+			name:   "rate changes with the years of service",
+			emp:    veteran,
+			policy: monthly,
+			year:   2025,
+			// two months at 96 a year, then ten at 144.
+			carryover: 0,
+			accrued:   136,
+			available: 136,
+		},
+		{
+			name:      "maximum balance",
+			emp:       veteran,
+			policy:    capped,
+			year:      2024,
+			carryover: 40,
+			accrued:   68,
+			used:      8,
+			available: 100,
+		},
+		{
+			name:      "use or lose",
+			emp:       veteran,
+			policy:    useOrLose,
+			year:      2024,
+			accrued:   96,
+			used:      8,
+			available: 88,
+		},
+		{
+			name:   "annual accrual prorated after the waiting period",
+			emp:    newHire,
+			policy: waiting,
+			year:   2024,
+			// eligible July 1, with 184 of the year's 366 days left.
+			accrued:   120.0 * 184 / 366,
+			available: 120.0 * 184 / 366,
+		},
+		{
+			name:   "before service",
+			emp:    newHire,
+			policy: waiting,
+			year:   2023,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tt.emp.GetLeaveBalance(tt.policy, tt.year)
+			if !near(got.Carryover, tt.carryover) || !near(got.Accrued, tt.accrued) ||
+				!near(got.Used, tt.used) || !near(got.Available, tt.available) {
+				t.Errorf("balance = %v carried, %v accrued, %v used, %v available, "+
+					"want %v, %v, %v, %v", got.Carryover, got.Accrued, got.Used,
+					got.Available, tt.carryover, tt.accrued, tt.used, tt.available)
+			}
+			if len(got.Months) != 12 ||
+				!near(got.Months[11].Available, got.Available) {
+				t.Errorf("months = %+v", got.Months)
+			}
+		})
+	}
+}
+
+func TestCreateLeaveBalance(t *testing.T) {
+	employee := func() Employee {
+		emp := Employee{}
+		emp.AddAssignment("s", "w", date(2020, time.January, 1))
+		emp.Balances = []AnnualLeave{
+			{Year: 2023, Annual: 120, Carryover: 40},
+			{Year: 2023, Code: "S", Annual: 40},
+		}
+		emp.Leaves = []LeaveDay{
+			{LeaveDate: date(2023, time.May, 1), Code: "V", Hours: 60,
+				Status: "ACTUAL"},
+		}
+		return emp
+	}
+	limited := labor.DefaultAccrualPolicy()
+	limited.CarryoverLimit = 80
+	sick := labor.AccrualPolicy{
+		Code:      "S",
+		Rates:     []labor.AccrualRate{{Years: 0, Hours: 48}},
+		Frequency: labor.AccrueAnnually,
+	}
+
+	tests := []struct {
+		name     string
+		existing bool
+		policies []labor.AccrualPolicy
+		want     []AnnualLeave
+	}{
+		{
+			name: "default policy",
+			want: []AnnualLeave{{Year: 2024, Annual: 120, Carryover: 100}},
+		},
+		{
+			name:     "company policies",
+			policies: []labor.AccrualPolicy{limited, sick},
+			want: []AnnualLeave{
+				{Year: 2024, Annual: 120, Carryover: 80},
+				{Year: 2024, Code: "S", Annual: 48, Carryover: 40},
+			},
+		},
+		{
+			name:     "already created",
+			existing: true,
+			want:     []AnnualLeave{{Year: 2024, Annual: 160, Carryover: 8}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			emp := employee()
+			if tt.existing {
+				emp.UpdateAnnualLeave(2024, 160, 8)
+			}
+			emp.CreateLeaveBalance(2024, tt.policies...)
+			var got []AnnualLeave
+			for _, al := range emp.Balances {
+				if al.Year == 2024 {
+					got = append(got, al)
+				}
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("balances = %+v, want %+v", got, tt.want)
+			}
+			for _, want := range tt.want {
+				found := false
+				for _, al := range got {
+					if al.IsCode(want.Code) && near(al.Annual, want.Annual) &&
+						near(al.Carryover, want.Carryover) {
+						found = true
+					}
+				}
+				if !found {
+					t.Errorf("balances = %+v, missing %+v", got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestProjectLeaveBalance(t *testing.T) {
+	emp := Employee{}
+	emp.AddAssignment("s", "w", date(2020, time.January, 5))
+	emp.Balances = []AnnualLeave{{Year: 2024, Annual: 120, Carryover: 8}}
+	emp.Leaves = []LeaveDay{
+		{LeaveDate: date(2024, time.February, 5), Code: "V", Hours: 40,
+			Status: "ACTUAL"},
+		{LeaveDate: date(2024, time.July, 5), Code: "V", Hours: 80,
+			Status: "APPROVED"},
+	}
+	emp.Requests = []LeaveRequest{
+		{
+			ID:     "r",
+			Status: LeaveRequested,
+			RequestedDays: []LeaveDay{
+				// before the date projected from, so not counted.
+				{LeaveDate: date(2024, time.May, 6), Code: "V", Hours: 8},
+				{LeaveDate: date(2024, time.December, 2), Code: "V", Hours: 16},
+			},
+		},
+	}
+	annual := labor.DefaultAccrualPolicy()
+	monthly := labor.DefaultAccrualPolicy()
+	monthly.Frequency = labor.AccrueMonthly
+	monthly.Rates[0].Hours = 240
+
+	tests := []struct {
+		name string
+		// a policy without a code is the default policy.
+		policy    labor.AccrualPolicy
+		accrued   float64
+		requested float64
+		available float64
+		// the months (from January as 0) over used.
+		overUsed []int
+	}{
+		{
+			name:      "default policy",
+			accrued:   120,
+			requested: 16,
+			available: -8,
+			overUsed:  []int{11},
+		},
+		{
+			name:      "annual",
+			policy:    annual,
+			accrued:   120,
+			requested: 16,
+			available: -8,
+			overUsed:  []int{11},
+		},
+		{
+			name: "stored annual leave spread over the policy's months",
+			// ten hours a month, not the policy's twenty.
+			policy:    monthly,
+			accrued:   120,
+			requested: 16,
+			available: -8,
+			overUsed:  []int{1, 2, 6, 7, 8, 9, 10, 11},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			asOf := time.Date(2024, time.June, 1, 12, 0, 0, 0, time.UTC)
+			var got LeaveProjection
+			if tt.policy.Code == "" {
+				got = emp.ProjectLeaveBalance(2024, asOf)
+			} else {
+				got = emp.ProjectLeaveBalanceWithPolicy(tt.policy, 2024, asOf)
+			}
+			if !got.AsOf.Equal(date(2024, time.June, 1)) {
+				t.Errorf("as of %v", got.AsOf)
+			}
+			if got.Carryover != 8 || !near(got.Accrued, tt.accrued) ||
+				got.Taken != 40 || got.Approved != 80 ||
+				got.Requested != tt.requested || !near(got.Available, tt.available) {
+				t.Errorf("projection = %+v", got)
+			}
+			if got.OverUsed != (len(tt.overUsed) > 0) {
+				t.Errorf("over used = %v", got.OverUsed)
+			}
+			over := make(map[int]bool)
+			for _, m := range tt.overUsed {
+				over[m] = true
+			}
+			for m, month := range got.Months {
+				if month.OverUsed != over[m] {
+					t.Errorf("month %d over used = %v, available %v", m+1,
+						month.OverUsed, month.Available)
+				}
+			}
+		})
+	}
+}
+
+func near(a, b float64) bool {
+	return math.Abs(a-b) < 0.0001
+}
@@ -0,0 +1,250 @@
+package employees
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/erneap/models/v2/labor"
+)
+
+// The usual approval levels, a request's supervisor and the site's lead.
+const (
+	ApproverSupervisor = "supervisor"
+	ApproverSiteLead   = "sitelead"
+)
+
+// ErrNotApprover is given when someone who isn't an approver of a leave
+// request's next step (or a delegate of one) tries to approve it.
+var ErrNotApprover = errors.New("not an approver of the leave request's step")
+
+// ApprovalLevel is a level of an approval chain and who can approve it, with
+// no approvers meaning anyone can.  A level with neither OverDays nor
+// Blackout is required for every request, otherwise only for requests with
+// more leave days than OverDays or with leave days in a blackout period.
+type ApprovalLevel struct {
+	Level     string   `json:"level" bson:"level"`
+	Approvers []string `json:"approvers,omitempty" bson:"approvers,omitempty"`
+	OverDays  int      `json:"overDays,omitempty" bson:"overDays,omitempty"`
+	Blackout  bool     `json:"blackout,omitempty" bson:"blackout,omitempty"`
+}
+
+// BlackoutPeriod is the dates (inclusive) leave needs extra approval.
+type BlackoutPeriod struct {
+	StartDate time.Time `json:"startdate" bson:"startdate"`
+	EndDate   time.Time `json:"enddate" bson:"enddate"`
+	Reason    string    `json:"reason,omitempty" bson:"reason,omitempty"`
+}
+
+// ApprovalChain is the levels, in order, that approve a team's or site's leave
+// requests.
+type ApprovalChain struct {
+	Levels    []ApprovalLevel  `json:"levels" bson:"levels"`
+	Blackouts []BlackoutPeriod `json:"blackouts,omitempty" bson:"blackouts,omitempty"`
+}
+
+// ApprovalDelegation lets the delegate approve for the approver from the
+// start date through the end date, like while the approver is away.
+type ApprovalDelegation struct {
+	Approver  string    `json:"approver" bson:"approver"`
+	Delegate  string    `json:"delegate" bson:"delegate"`
+	StartDate time.Time `json:"startdate" bson:"startdate"`
+	EndDate   time.Time `json:"enddate" bson:"enddate"`
+}
+
+// IsActive tells if the delegation covers the calendar date.
+func (d *ApprovalDelegation) IsActive(date time.Time) bool {
+	return !date.Before(d.StartDate) && !date.After(d.EndDate)
+}
+
+// ApprovalStep is a level of a request's approval, with who approved it (and
+// for whom when approved by a delegate).
+type ApprovalStep struct {
+	Level        string    `json:"level" bson:"level"`
+	Approvers    []string  `json:"approvers,omitempty" bson:"approvers,omitempty"`
+	ApprovedBy   string    `json:"approvedby,omitempty" bson:"approvedby,omitempty"`
+	OnBehalfOf   string    `json:"onbehalfof,omitempty" bson:"onbehalfof,omitempty"`
+	ApprovalDate time.Time `json:"approvalDate,omitempty" bson:"approvalDate,omitempty"`
+}
+
+// IsApproved tells if the step is complete.
+func (s *ApprovalStep) IsApproved() bool {
+	return s.ApprovedBy != ""
+}
+
+// CanApprove tells if the approver can approve the step on the date, either as
+// one of its approvers or as the delegate of one, providing the approver
+// delegating.
+func (s *ApprovalStep) CanApprove(approver string,
+	delegations []ApprovalDelegation, date time.Time) (string, bool) {
+	if len(s.Approvers) == 0 {
+		return "", true
+	}
+	for _, a := range s.Approvers {
+		if strings.EqualFold(a, approver) {
+			return "", true
+		}
+	}
+	for _, d := range delegations {
+		if !strings.EqualFold(d.Delegate, approver) || !d.IsActive(date) {
+			continue
+		}
+		for _, a := range s.Approvers {
+			if strings.EqualFold(a, d.Approver) {
+				return a, true
+			}
+		}
+	}
+	return "", false
+}
+
+// GetLeaveDays provides the number of days the request takes leave.
+func (lr *LeaveRequest) GetLeaveDays() int {
+	answer := 0
+	for _, day := range lr.RequestedDays {
+		if day.Code != "" && day.Hours > 0.0 {
+			answer++
+		}
+	}
+	return answer
+}
+
+// InBlackout tells if any of the request's leave days are in a blackout
+// period.
+func (c *ApprovalChain) InBlackout(lr *LeaveRequest) bool {
+	for _, day := range lr.RequestedDays {
+		if day.Code == "" || day.Hours <= 0.0 {
+			continue
+		}
+		for _, b := range c.Blackouts {
+			if !day.LeaveDate.Before(b.StartDate) && !day.LeaveDate.After(b.EndDate) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// GetLevels provides the levels required to approve the request.
+func (c *ApprovalChain) GetLevels(lr *LeaveRequest) []ApprovalLevel {
+	var answer []ApprovalLevel
+	blackout := c.InBlackout(lr)
+	days := lr.GetLeaveDays()
+	for _, level := range c.Levels {
+		if (level.OverDays == 0 && !level.Blackout) ||
+			(level.OverDays > 0 && days > level.OverDays) ||
+			(level.Blackout && blackout) {
+			answer = append(answer, level)
+		}
+	}
+	return answer
+}
+
+// RouteApprovals sets the request's approval steps to the levels of the chain
+// it requires, keeping the approvals of levels it already had.  Without a
+// chain the request has no steps and a single approval approves it.  A
+// requested request must be routed before it can be approved.
+func (lr *LeaveRequest) RouteApprovals(chain *ApprovalChain) {
+	var steps []ApprovalStep
+	if chain != nil {
+		for _, level := range chain.GetLevels(lr) {
+			step := ApprovalStep{
+				Level:     level.Level,
+				Approvers: level.Approvers,
+			}
+			for _, s := range lr.Approvals {
+				if strings.EqualFold(s.Level, level.Level) {
+					step.ApprovedBy = s.ApprovedBy
+					step.OnBehalfOf = s.OnBehalfOf
+					step.ApprovalDate = s.ApprovalDate
+				}
+			}
+			steps = append(steps, step)
+		}
+	}
+	lr.Approvals = steps
+	lr.Routed = true
+}
+
+// ApprovalsComplete tells if all of the request's approval steps are approved.
+func (lr *LeaveRequest) ApprovalsComplete() bool {
+	for _, step := range lr.Approvals {
+		if !step.IsApproved() {
+			return false
+		}
+	}
+	return true
+}
+
+// resetApprovals clears the approvals of the request's steps, for a request
+// that needs approving again.
+func (lr *LeaveRequest) resetApprovals() {
+	for s, step := range lr.Approvals {
+		step.ApprovedBy = ""
+		step.OnBehalfOf = ""
+		step.ApprovalDate = time.Time{}
+		lr.Approvals[s] = step
+	}
+}
+
+// ApproveStep records the approver's approval of the request's next step on
+// the date, telling if all the steps are now approved.
+func (lr *LeaveRequest) ApproveStep(approver string,
+	delegations []ApprovalDelegation, date time.Time) (bool, error) {
+	if lr.GetStatus() != LeaveRequested {
+		return false, &TransitionError{
+			RequestID: lr.ID,
+			From:      lr.GetStatus(),
+			To:        LeaveApproved,
+			Reason:    "request isn't waiting for approval",
+		}
+	}
+	if !lr.Routed {
+		return false, &TransitionError{
+			RequestID: lr.ID,
+			From:      lr.GetStatus(),
+			To:        LeaveApproved,
+			Reason:    "approval steps aren't routed",
+		}
+	}
+	for s, step := range lr.Approvals {
+		if step.IsApproved() {
+			continue
+		}
+		onBehalfOf, ok := step.CanApprove(approver, delegations, date)
+		if !ok {
+			return false, ErrNotApprover
+		}
+		step.ApprovedBy = approver
+		step.OnBehalfOf = onBehalfOf
+		step.ApprovalDate = time.Now().UTC()
+		lr.Approvals[s] = step
+		break
+	}
+	return lr.ApprovalsComplete(), nil
+}
+
+// ApproveLeaveStep records the approver's approval of the request's next
+// step, for one of the step's approvers or as their delegate on the day at
+// the site's location.  Once all the steps are approved, the request is
+// approved by ApproveLeaveRequest.
+func (e *Employee) ApproveLeaveStep(request, approver string,
+	delegations []ApprovalDelegation, loc *time.Location,
+	leavecodes []labor.Workcode) (string, *LeaveRequest, error) {
+	for i, req := range e.Requests {
+		if req.ID == request {
+			complete, err := req.ApproveStep(approver, delegations,
+				SiteDate(time.Now(), loc))
+			if err != nil {
+				return "", nil, err
+			}
+			e.Requests[i] = req
+			if !complete {
+				return "Leave Request: Leave Request approval step completed.",
+					&req, nil
+			}
+			return e.ApproveLeaveRequest(request, "", approver, 0.0, leavecodes)
+		}
+	}
+	return "", nil, errors.New("not found")
+}
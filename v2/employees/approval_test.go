@@ -0,0 +1,151 @@
+package employees
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestApproveStep(t *testing.T) {
+	steps := func() []ApprovalStep {
+		return []ApprovalStep{
+			{Level: ApproverSupervisor, Approvers: []string{"boss"}},
+			{Level: ApproverSiteLead, Approvers: []string{"lead"}},
+		}
+	}
+	delegations := []ApprovalDelegation{
+		{Approver: "boss", Delegate: "deputy",
+			StartDate: date(2024, time.June, 1), EndDate: date(2024, time.June, 14)},
+	}
+	tests := []struct {
+		name       string
+		status     string
+		approvals  []ApprovalStep
+		approver   string
+		date       time.Time
+		unrouted   bool
+		complete   bool
+		err        error
+		approvedBy string
+		onBehalfOf string
+	}{
+		{
+			name:       "approver",
+			status:     LeaveRequested,
+			approvals:  steps(),
+			approver:   "BOSS",
+			date:       date(2024, time.June, 3),
+			approvedBy: "BOSS",
+		},
+		{
+			name:       "delegate",
+			status:     LeaveRequested,
+			approvals:  steps(),
+			approver:   "deputy",
+			date:       date(2024, time.June, 3),
+			approvedBy: "deputy",
+			onBehalfOf: "boss",
+		},
+		{
+			name:      "delegation ended",
+			status:    LeaveRequested,
+			approvals: steps(),
+			approver:  "deputy",
+			date:      date(2024, time.June, 15),
+			err:       ErrNotApprover,
+		},
+		{
+			name:      "next step's approver",
+			status:    LeaveRequested,
+			approvals: steps(),
+			approver:  "lead",
+			date:      date(2024, time.June, 3),
+			err:       ErrNotApprover,
+		},
+		{
+			name:   "last step",
+			status: LeaveRequested,
+			approvals: []ApprovalStep{
+				{Level: ApproverSupervisor, Approvers: []string{"boss"},
+					ApprovedBy: "boss"},
+				{Level: ApproverSiteLead, Approvers: []string{"lead"}},
+			},
+			approver:   "lead",
+			date:       date(2024, time.June, 3),
+			complete:   true,
+			approvedBy: "lead",
+		},
+		{
+			name:   "anyone",
+			status: LeaveRequested,
+			approvals: []ApprovalStep{
+				{Level: ApproverSupervisor},
+			},
+			approver:   "someone",
+			date:       date(2024, time.June, 3),
+			complete:   true,
+			approvedBy: "someone",
+		},
+		{
+			name:     "no steps",
+			status:   LeaveRequested,
+			approver: "boss",
+			date:     date(2024, time.June, 3),
+			complete: true,
+		},
+		{
+			name:      "not routed",
+			status:    LeaveRequested,
+			approvals: steps(),
+			approver:  "boss",
+			date:      date(2024, time.June, 3),
+			unrouted:  true,
+			err:       ErrInvalidTransition,
+		},
+		{
+			name:      "not requested",
+			status:    LeaveDraft,
+			approvals: steps(),
+			approver:  "boss",
+			date:      date(2024, time.June, 3),
+			err:       ErrInvalidTransition,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := LeaveRequest{
+				ID:        "r",
+				Status:    tt.status,
+				Approvals: append([]ApprovalStep{}, tt.approvals...),
+				Routed:    !tt.unrouted,
+			}
+			complete, err := req.ApproveStep(tt.approver, delegations, tt.date)
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("ApproveStep error = %v, want %v", err, tt.err)
+			}
+			if complete != tt.complete {
+				t.Errorf("complete = %v, want %v", complete, tt.complete)
+			}
+			if tt.err != nil || tt.approvedBy == "" {
+				for s, step := range req.Approvals {
+					if step.ApprovedBy != tt.approvals[s].ApprovedBy {
+						t.Errorf("step changed: %+v", step)
+					}
+				}
+				return
+			}
+			// the step approved is the last one approved.
+			var step ApprovalStep
+			for _, s := range req.Approvals {
+				if s.IsApproved() {
+					step = s
+				}
+			}
+			if step.ApprovedBy != tt.approvedBy || step.OnBehalfOf != tt.onBehalfOf ||
+				step.ApprovalDate.IsZero() {
+				t.Errorf("step = %+v, want approved by %s for %q", step,
+					tt.approvedBy, tt.onBehalfOf)
+			}
+		})
+	}
+}
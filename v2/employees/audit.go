@@ -0,0 +1,196 @@
+package employees
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// AuditEntry records a single field change to an employee, who made it and
+// when.  The path names the field with its json names, with list items
+// addressed by their id when they have one (leaves[id=3].status) or by their
+// position when they don't (emails[1]).  The old and new values are the
+// field's json value, blank when the field was added or removed.
+type AuditEntry struct {
+	ID         primitive.ObjectID `json:"id" bson:"_id"`
+	EmployeeID primitive.ObjectID `json:"employeeid" bson:"employeeid"`
+	Actor      string             `json:"actor" bson:"actor"`
+	Date       time.Time          `json:"date" bson:"date"`
+	Path       string             `json:"path" bson:"path"`
+	OldValue   string             `json:"oldvalue" bson:"oldvalue"`
+	NewValue   string             `json:"newvalue" bson:"newvalue"`
+}
+
+type ByAuditEntry []AuditEntry
+
+func (c ByAuditEntry) Len() int { return len(c) }
+func (c ByAuditEntry) Less(i, j int) bool {
+	if c[i].Date.Equal(c[j].Date) {
+		return c[i].Path < c[j].Path
+	}
+	return c[i].Date.Before(c[j].Date)
+}
+func (c ByAuditEntry) Swap(i, j int) { c[i], c[j] = c[j], c[i] }
+
+// GetChanges compares the employee to its previous state and provides an
+// audit entry (path and values only) for each field changed.  The user, work
+// and version aren't part of the employee's stored record, so they aren't
+// compared.
+func (e *Employee) GetChanges(prev *Employee) []AuditEntry {
+	var answer []AuditEntry
+	old, err := auditDocument(prev)
+	if err != nil {
+		return answer
+	}
+	current, err := auditDocument(e)
+	if err != nil {
+		return answer
+	}
+	auditCompare("", old, current, &answer)
+	return answer
+}
+
+// GetRemoval provides the audit entry (values only) for the employee's
+// removal, a blank path with the employee's record as the old value.
+func (e *Employee) GetRemoval() AuditEntry {
+	old, err := auditDocument(e)
+	if err != nil {
+		return AuditEntry{}
+	}
+	return AuditEntry{
+		OldValue: auditValue(old),
+	}
+}
+
+// auditDocument converts the employee into its generic json form.
+func auditDocument(emp *Employee) (interface{}, error) {
+	if emp == nil {
+		return nil, nil
+	}
+	tEmp := *emp
+	tEmp.User = nil
+	tEmp.Work = nil
+	tEmp.Version = 0
+	data, err := json.Marshal(&tEmp)
+	if err != nil {
+		return nil, err
+	}
+	var answer interface{}
+	err = json.Unmarshal(data, &answer)
+	return answer, err
+}
+
+func auditCompare(path string, old, current interface{},
+	answer *[]AuditEntry) {
+	switch oValue := old.(type) {
+	case map[string]interface{}:
+		if cValue, ok := current.(map[string]interface{}); ok {
+			keys := make(map[string]bool)
+			for key := range oValue {
+				keys[key] = true
+			}
+			for key := range cValue {
+				keys[key] = true
+			}
+			var names []string
+			for key := range keys {
+				names = append(names, key)
+			}
+			sort.Strings(names)
+			for _, key := range names {
+				fieldPath := key
+				if path != "" {
+					fieldPath = path + "." + key
+				}
+				auditCompare(fieldPath, oValue[key], cValue[key], answer)
+			}
+			return
+		}
+	case []interface{}:
+		if cValue, ok := current.([]interface{}); ok {
+			auditCompareList(path, oValue, cValue, answer)
+			return
+		}
+	}
+	if !reflect.DeepEqual(old, current) {
+		*answer = append(*answer, AuditEntry{
+			Path:     path,
+			OldValue: auditValue(old),
+			NewValue: auditValue(current),
+		})
+	}
+}
+
+// auditCompareList compares list items by their id when every item has one,
+// so adding or removing an item doesn't show as a change to all the items
+// after it, otherwise by position.
+func auditCompareList(path string, old, current []interface{},
+	answer *[]AuditEntry) {
+	oItems, oIDs := auditItemsByID(old)
+	cItems, cIDs := auditItemsByID(current)
+	if oItems == nil || cItems == nil {
+		for i := 0; i < len(old) || i < len(current); i++ {
+			var oItem, cItem interface{}
+			if i < len(old) {
+				oItem = old[i]
+			}
+			if i < len(current) {
+				cItem = current[i]
+			}
+			auditCompare(fmt.Sprintf("%s[%d]", path, i), oItem, cItem, answer)
+		}
+		return
+	}
+	ids := oIDs
+	for _, id := range cIDs {
+		if _, ok := oItems[id]; !ok {
+			ids = append(ids, id)
+		}
+	}
+	for _, id := range ids {
+		auditCompare(fmt.Sprintf("%s[id=%s]", path, id), oItems[id], cItems[id],
+			answer)
+	}
+}
+
+// auditItemsByID maps the list items by their id, in list order, or gives a
+// nil map if any item doesn't have an id.
+func auditItemsByID(list []interface{}) (map[string]interface{}, []string) {
+	items := make(map[string]interface{})
+	var ids []string
+	for _, item := range list {
+		mItem, ok := item.(map[string]interface{})
+		if !ok {
+			return nil, nil
+		}
+		id, ok := mItem["id"]
+		if !ok {
+			return nil, nil
+		}
+		key := auditValue(id)
+		if _, found := items[key]; found {
+			return nil, nil
+		}
+		items[key] = item
+		ids = append(ids, key)
+	}
+	return items, ids
+}
+
+func auditValue(value interface{}) string {
+	switch tValue := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return tValue
+	}
+	data, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Sprintf("%v", value)
+	}
+	return string(data)
+}
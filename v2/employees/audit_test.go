@@ -0,0 +1,98 @@
+package employees
+
+import (
+	"testing"
+	"time"
+
+	"github.com/erneap/models/v2/users"
+)
+
+func TestEmployeeGetChanges(t *testing.T) {
+	leaveDate := time.Date(2024, time.June, 3, 0, 0, 0, 0, time.UTC)
+	base := func() Employee {
+		return Employee{
+			Email: "a@b",
+			Name:  EmployeeName{FirstName: "A", LastName: "B"},
+			Leaves: []LeaveDay{
+				{ID: 3, LeaveDate: leaveDate, Code: "V", Hours: 8,
+					Status: "REQUESTED"},
+				{ID: 5, LeaveDate: leaveDate.AddDate(0, 0, 1), Code: "V", Hours: 8,
+					Status: "REQUESTED"},
+			},
+			EmailAddresses: []string{"a@b", "c@d"},
+		}
+	}
+	type change struct {
+		path     string
+		oldValue string
+		newValue string
+	}
+	tests := []struct {
+		name   string
+		change func(e *Employee)
+		want   []change
+	}{
+		{
+			name:   "nothing",
+			change: func(e *Employee) {},
+		},
+		{
+			name:   "field",
+			change: func(e *Employee) { e.Email = "x@y" },
+			want:   []change{{"email", "a@b", "x@y"}},
+		},
+		{
+			name:   "nested field",
+			change: func(e *Employee) { e.Name.FirstName = "C" },
+			want:   []change{{"name.first", "A", "C"}},
+		},
+		{
+			name:   "list item by id",
+			change: func(e *Employee) { e.Leaves[1].Status = "APPROVED" },
+			want:   []change{{"leaves[id=5].status", "REQUESTED", "APPROVED"}},
+		},
+		{
+			name: "list item removed by id",
+			change: func(e *Employee) {
+				e.Leaves = e.Leaves[1:]
+			},
+			want: []change{{"leaves[id=3]",
+				`{"code":"V","hours":8,"id":3,"leavedate":"2024-06-03T00:00:00Z",` +
+					`"requestid":"","status":"REQUESTED"}`, ""}},
+		},
+		{
+			name: "list item without an id by position",
+			change: func(e *Employee) {
+				e.EmailAddresses = e.EmailAddresses[:1]
+			},
+			want: []change{{"emails[1]", "c@d", ""}},
+		},
+		{
+			name: "fields not stored",
+			change: func(e *Employee) {
+				e.User = &users.User{EmailAddress: "x@y"}
+				e.Work = []Work{{Hours: 8}}
+				e.Version = 4
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			prev := base()
+			emp := base()
+			tt.change(&emp)
+			got := emp.GetChanges(&prev)
+			if len(got) != len(tt.want) {
+				t.Fatalf("changes = %+v, want %+v", got, tt.want)
+			}
+			for i, want := range tt.want {
+				if got[i].Path != want.path || got[i].OldValue != want.oldValue ||
+					got[i].NewValue != want.newValue {
+					t.Errorf("change %d = %s %q -> %q, want %s %q -> %q", i,
+						got[i].Path, got[i].OldValue, got[i].NewValue, want.path,
+						want.oldValue, want.newValue)
+				}
+			}
+		})
+	}
+}
@@ -33,6 +33,7 @@ type Employee struct {
 	ContactInfo    []Contact           `json:"contactinfo,omitempty" bson:"contactinfo,omitempty"`
 	Specialties    []Specialty         `json:"specialties,omitempty" bson:"specialties,omitempty"`
 	EmailAddresses []string            `json:"emails,omitempty" bson:"emails,omitempty"`
+	Version        int64               `json:"version" bson:"version"`
 }
 
 type ByEmployees []Employee
@@ -87,6 +88,9 @@ func (e *Employee) RemoveLeaves(start, end time.Time) {
 	}
 }
 
+// ConvertFromData moves the legacy nested employee data into the employee.
+// The employee methods convert an employee that still has it, until the svcs
+// migrations (see svcs.MigrateUp) have converted the stored employees.
 func (e *Employee) ConvertFromData() error {
 	if e.Data != nil {
 		e.CompanyInfo = e.Data.CompanyInfo
@@ -229,7 +233,12 @@ func (e *Employee) GetWorkday(date, lastWork time.Time) *Workday {
 		wkday = nil
 	}
 
+	// partial leave splits a scheduled workday rather than replacing it.
+	scheduled := wkday != nil && wkday.Code != ""
 	for _, lv := range e.Leaves {
+		if scheduled && lv.IsPartial() {
+			continue
+		}
 		if lv.LeaveDate.Year() == date.Year() &&
 			lv.LeaveDate.Month() == date.Month() &&
 			lv.LeaveDate.Day() == date.Day() &&
@@ -477,64 +486,61 @@ func (e *Employee) PurgeOldData(date time.Time) bool {
 	}
 
 	// check if employee quit before purge date
+	if len(e.Assignments) == 0 {
+		return false
+	}
 	sort.Sort(ByAssignment(e.Assignments))
 	asgmt := e.Assignments[len(e.Assignments)-1]
 	return asgmt.EndDate.Before(date)
 }
 
-func (e *Employee) CreateLeaveBalance(year int) {
+// CreateLeaveBalance adds the year's annual leave of each of the company's
+// accrual policies (or the default vacation policy) when the employee doesn't
+// have one, as the hours the policy accrues in the year with the carryover it
+// allows from the year before (see GetLeaveBalance).
+func (e *Employee) CreateLeaveBalance(year int,
+	policies ...labor.AccrualPolicy) {
 	if e.Data != nil {
 		e.ConvertFromData()
 	}
-	found := false
-	lastAnnual := 0.0
-	lastCarry := 0.0
-	for _, al := range e.Balances {
-		if al.Year == year {
-			found = true
-		}
-		if al.Year == year-1 {
-			lastAnnual = al.Annual
-			lastCarry = al.Carryover
-		}
+	if len(policies) == 0 {
+		policies = []labor.AccrualPolicy{labor.DefaultAccrualPolicy()}
 	}
-	if !found {
-		al := AnnualLeave{
-			Year:      year,
-			Annual:    lastAnnual,
-			Carryover: 0.0,
-		}
-		if lastAnnual == 0.0 {
-			al.Annual = 120.0
-		} else {
-			carry := lastAnnual + lastCarry
-			for _, lv := range e.Leaves {
-				if lv.LeaveDate.Year() == year-1 && strings.ToLower(lv.Code) == "v" &&
-					strings.ToLower(lv.Status) == "actual" {
-					carry -= lv.Hours
-				}
+	for _, policy := range policies {
+		found := false
+		for _, al := range e.Balances {
+			if al.Year == year && al.IsCode(policy.Code) {
+				found = true
 			}
-			al.Carryover = carry
 		}
-		e.Balances = append(e.Balances, al)
+		if !found {
+			e.ApplyAccrualPolicy(policy, year)
+		}
 	}
 }
 
 func (e *Employee) UpdateAnnualLeave(year int, annual, carry float64) {
+	e.updateAnnualLeave("", year, annual, carry)
+}
+
+func (e *Employee) updateAnnualLeave(code string, year int, annual,
+	carry float64) {
 	if e.Data != nil {
 		e.ConvertFromData()
 	}
 	found := false
-	for _, al := range e.Balances {
-		if al.Year == year {
+	for a, al := range e.Balances {
+		if al.Year == year && al.IsCode(code) {
 			found = true
 			al.Annual = annual
 			al.Carryover = carry
+			e.Balances[a] = al
 		}
 	}
 	if !found {
 		al := AnnualLeave{
 			Year:      year,
+			Code:      code,
 			Annual:    annual,
 			Carryover: carry,
 		}
@@ -596,6 +602,8 @@ func (e *Employee) UpdateLeave(id int, field, value string) (*LeaveDay, error) {
 				Status:    lv.Status,
 				RequestID: lv.RequestID,
 				TagDay:    lv.TagDay,
+				StartTime: lv.StartTime,
+				EndTime:   lv.EndTime,
 			}
 			switch strings.ToLower(field) {
 			case "date":
@@ -618,6 +626,14 @@ func (e *Employee) UpdateLeave(id int, field, value string) (*LeaveDay, error) {
 				lv.RequestID = value
 			case "tagday":
 				lv.TagDay = value
+			case "times":
+				start, end, err := ParseLeaveTimes(value)
+				if err != nil {
+					return nil, err
+				}
+				if err := lv.SetTimes(start, end); err != nil {
+					return nil, err
+				}
 			}
 			e.Leaves[i] = lv
 		}
@@ -645,6 +661,23 @@ func (e *Employee) DeleteLeave(id int) *LeaveDay {
 	return oldLv
 }
 
+// GetPartialLeaves provides the approved or actual partial leave on the date,
+// by start time, which splits a scheduled workday between work and leave.
+func (e *Employee) GetPartialLeaves(date time.Time) []LeaveDay {
+	var answer []LeaveDay
+	for _, lv := range e.Leaves {
+		if sameDay(lv.LeaveDate, date) && lv.IsPartial() &&
+			(strings.EqualFold(lv.Status, "actual") ||
+				strings.EqualFold(lv.Status, "approved")) {
+			answer = append(answer, lv)
+		}
+	}
+	sort.Slice(answer, func(i, j int) bool {
+		return answer[i].StartTime < answer[j].StartTime
+	})
+	return answer
+}
+
 func (e *Employee) GetLeaveHours(start, end time.Time) float64 {
 	if e.Data != nil {
 		e.ConvertFromData()
@@ -682,8 +715,23 @@ func (e *Employee) GetPTOHours(start, end time.Time) float64 {
 	return answer
 }
 
+// NewLeaveRequest adds a draft leave request for the calendar dates start
+// through end.  The offset isn't used.
 func (e *Employee) NewLeaveRequest(empID, code string, start, end time.Time,
 	offset float64, comment string) *LeaveRequest {
+	return e.newLeaveRequest(empID, code, start, end, comment)
+}
+
+// NewLeaveRequestAt adds a draft leave request for the calendar dates, at the
+// site's location, of the moments start through end.
+func (e *Employee) NewLeaveRequestAt(empID, code string, start, end time.Time,
+	loc *time.Location, comment string) *LeaveRequest {
+	return e.newLeaveRequest(empID, code, SiteDate(start, loc),
+		SiteDate(end, loc), comment)
+}
+
+func (e *Employee) newLeaveRequest(empID, code string, start, end time.Time,
+	comment string) *LeaveRequest {
 	if e.Data != nil {
 		e.ConvertFromData()
 	}
@@ -707,7 +755,14 @@ func (e *Employee) NewLeaveRequest(empID, code string, start, end time.Time,
 		PrimaryCode: code,
 		StartDate:   start,
 		EndDate:     end,
-		Status:      "DRAFT",
+		Status:      LeaveDraft,
+		History: []LeaveRequestTransition{
+			{
+				Date:  time.Now().UTC(),
+				Actor: empID,
+				To:    LeaveDraft,
+			},
+		},
 	}
 	if comment != "" {
 		lrc := &LeaveRequestComment{
@@ -716,12 +771,6 @@ func (e *Employee) NewLeaveRequest(empID, code string, start, end time.Time,
 		}
 		answer.Comments = append(answer.Comments, *lrc)
 	}
-	zoneID := "UTC"
-	if offset > 0 {
-		zoneID += "+" + fmt.Sprintf("%0.1f", offset)
-	} else if offset < 0 {
-		zoneID += fmt.Sprintf("%0.1f", offset)
-	}
 	sDate := time.Date(start.Year(), start.Month(), start.Day(), 0, 0, 0, 0,
 		time.UTC)
 	std := e.GetStandardWorkday(sDate)
@@ -739,7 +788,7 @@ func (e *Employee) NewLeaveRequest(empID, code string, start, end time.Time,
 				LeaveDate: sDate,
 				Code:      code,
 				Hours:     hours,
-				Status:    "DRAFT",
+				Status:    LeaveDraft,
 				RequestID: answer.ID,
 			}
 			answer.RequestedDays = append(answer.RequestedDays, lv)
@@ -751,8 +800,22 @@ func (e *Employee) NewLeaveRequest(empID, code string, start, end time.Time,
 	return answer
 }
 
+// UpdateLeaveRequest changes the request's field, like
+// UpdateLeaveRequestWithActor without an actor, with timestamps taken at the
+// UTC offset.
 func (e *Employee) UpdateLeaveRequest(request, field, value string,
 	offset float64) (string, *LeaveRequest, error) {
+	return e.UpdateLeaveRequestWithActor(request, field, value, "",
+		offsetLocation(offset))
+}
+
+// UpdateLeaveRequestWithActor changes the request's field for the actor.
+// Dates are given as calendar dates or timestamps, whose calendar date at the
+// site's location is used.  Changing the dates of an approved request outside
+// its period sends it back for reapproval, and "requested" and "unapprove"
+// change its status, giving a TransitionError when that isn't allowed.
+func (e *Employee) UpdateLeaveRequestWithActor(request, field, value,
+	actor string, loc *time.Location) (string, *LeaveRequest, error) {
 	if e.Data != nil {
 		e.ConvertFromData()
 	}
@@ -761,43 +824,21 @@ func (e *Employee) UpdateLeaveRequest(request, field, value string,
 		if req.ID == request {
 			switch strings.ToLower(field) {
 			case "startdate", "start":
-				lvDate, err := time.Parse("2006-01-02", value)
+				lvDate, err := ParseSiteDate(value, loc)
 				if err != nil {
 					return "", nil, err
 				}
 				if lvDate.Before(req.StartDate) || lvDate.After(req.EndDate) {
-					if strings.EqualFold(req.Status, "approved") {
-						req.Status = "REQUESTED"
-						req.ApprovalDate = time.Date(1, 1, 1, 0, 0, 0, 0, time.UTC)
-						req.ApprovedBy = ""
+					if req.GetStatus() == LeaveApproved {
+						err := e.transitionLeaveRequest(&req, LeaveRequested, actor,
+							"start date changed")
+						if err != nil {
+							return "", nil, err
+						}
 						message = fmt.Sprintf("Leave Request from %s: Starting date changed "+
 							"needs reapproval", e.Name.GetLastFirst())
 					}
-					startPos := -1
-					endPos := -1
-					sort.Sort(ByLeaveDay(e.Leaves))
-					for i, lv := range e.Leaves {
-						if lv.RequestID == req.ID {
-							if startPos < 0 {
-								startPos = i
-							} else {
-								endPos = i
-							}
-						}
-					}
-					if startPos >= 0 {
-						if endPos < 0 {
-							endPos = startPos
-						}
-						endPos++
-						if endPos > len(e.Leaves) {
-
-						} else {
-							e.Leaves = append(e.Leaves[:startPos],
-								e.Leaves[endPos:]...)
-						}
-					}
-				} else if strings.EqualFold(req.Status, "approved") {
+				} else if req.GetStatus() == LeaveApproved {
 					message = fmt.Sprintf("Leave Request from %s: Starting date changed "+
 						"to %s", e.Name.GetLastFirst(), lvDate.Format("2006-01-03"))
 				}
@@ -807,47 +848,25 @@ func (e *Employee) UpdateLeaveRequest(request, field, value string,
 				}
 				// reset the leave dates
 				req = e.resetLeaveDays(req.PrimaryCode, req)
-				if req.Status == "APPROVED" {
+				if req.GetStatus() == LeaveApproved {
 					e.ChangeApprovedLeaveDates(req)
 				}
 			case "enddate", "end":
-				lvDate, err := time.Parse("2006-01-02", value)
+				lvDate, err := ParseSiteDate(value, loc)
 				if err != nil {
 					return "", nil, err
 				}
 				if lvDate.Before(req.StartDate) || lvDate.After(req.EndDate) {
-					if strings.EqualFold(req.Status, "approved") {
-						req.Status = "REQUESTED"
-						req.ApprovalDate = time.Date(1, 1, 1, 0, 0, 0, 0, time.UTC)
-						req.ApprovedBy = ""
+					if req.GetStatus() == LeaveApproved {
+						err := e.transitionLeaveRequest(&req, LeaveRequested, actor,
+							"end date changed")
+						if err != nil {
+							return "", nil, err
+						}
 						message = fmt.Sprintf("Leave Request from %s: Ending Date changed "+
 							"needs reapproval", e.Name.GetLastFirst())
 					}
-					startPos := -1
-					endPos := -1
-					sort.Sort(ByLeaveDay(e.Leaves))
-					for i, lv := range e.Leaves {
-						if lv.RequestID == req.ID {
-							if startPos < 0 {
-								startPos = i
-							} else {
-								endPos = i
-							}
-						}
-					}
-					if startPos >= 0 {
-						if endPos < 0 {
-							endPos = startPos
-						}
-						endPos++
-						if endPos > len(e.Leaves) {
-
-						} else {
-							e.Leaves = append(e.Leaves[:startPos],
-								e.Leaves[endPos:]...)
-						}
-					}
-				} else if strings.EqualFold(req.Status, "approved") {
+				} else if req.GetStatus() == LeaveApproved {
 					message = fmt.Sprintf("Leave Request from %s: Ending Date changed "+
 						"to %s", e.Name.GetLastFirst(), lvDate.Format("2006-01-02"))
 				}
@@ -857,7 +876,7 @@ func (e *Employee) UpdateLeaveRequest(request, field, value string,
 				}
 				// reset the leave dates
 				req = e.resetLeaveDays(req.PrimaryCode, req)
-				if req.Status == "APPROVED" {
+				if req.GetStatus() == LeaveApproved {
 					e.ChangeApprovedLeaveDates(req)
 				}
 			case "code", "primarycode":
@@ -867,11 +886,11 @@ func (e *Employee) UpdateLeaveRequest(request, field, value string,
 				}
 			case "dates":
 				parts := strings.Split(value, "|")
-				start, err := time.ParseInLocation("2006-01-02", parts[0], time.UTC)
+				start, err := ParseSiteDate(parts[0], loc)
 				if err != nil {
 					return "", nil, err
 				}
-				end, err := time.ParseInLocation("2006-01-02", parts[1], time.UTC)
+				end, err := ParseSiteDate(parts[1], loc)
 				if err != nil {
 					return "", nil, err
 				}
@@ -881,65 +900,37 @@ func (e *Employee) UpdateLeaveRequest(request, field, value string,
 					time.UTC)
 				if start.Before(req.StartDate) || start.After(req.EndDate) ||
 					end.Before(req.StartDate) || end.After(req.EndDate) {
-					if strings.EqualFold(req.Status, "approved") {
-						req.Status = "REQUESTED"
-						req.ApprovalDate = time.Date(1, 1, 1, 0, 0, 0, 0, time.UTC)
-						req.ApprovedBy = ""
+					if req.GetStatus() == LeaveApproved {
+						err := e.transitionLeaveRequest(&req, LeaveRequested, actor,
+							"dates changed")
+						if err != nil {
+							return "", nil, err
+						}
 						message = fmt.Sprintf("Leave Request from %s: dates changed "+
 							"needs reapproval", e.Name.GetLastFirst())
 					}
-					startPos := -1
-					endPos := -1
-					sort.Sort(ByLeaveDay(e.Leaves))
-					for i, lv := range e.Leaves {
-						if lv.RequestID == req.ID {
-							if startPos < 0 {
-								startPos = i
-							} else {
-								endPos = i
-							}
-						}
-					}
-					if startPos >= 0 {
-						if endPos < 0 {
-							endPos = startPos
-						}
-						endPos++
-						if endPos > len(e.Leaves) {
-
-						} else {
-							e.Leaves = append(e.Leaves[:startPos],
-								e.Leaves[endPos:]...)
-						}
-					}
 				}
 				req.StartDate = time.Date(start.Year(), start.Month(), start.Day(), 0,
 					0, 0, 0, time.UTC)
 				req.EndDate = time.Date(end.Year(), end.Month(), end.Day(), 0, 0, 0, 0,
 					time.UTC)
 				req = e.resetLeaveDays(req.PrimaryCode, req)
-				if req.Status == "APPROVED" {
+				if req.GetStatus() == LeaveApproved {
 					e.ChangeApprovedLeaveDates(req)
 				}
 			case "requested":
-				req.Status = "REQUESTED"
-				for d, day := range req.RequestedDays {
-					if day.Code != "" && day.Status == "" {
-						day.Status = "REQUESTED"
-					}
-					req.RequestedDays[d] = day
+				err := e.transitionLeaveRequest(&req, LeaveRequested, actor, value)
+				if err != nil {
+					return "", nil, err
 				}
 				message = fmt.Sprintf("Leave Request: Leave Request from %s ",
 					e.Name.GetLastFirst()) + "submitted for approval.  " +
 					fmt.Sprintf("Requested Leave Date: %s - %s.",
 						req.StartDate.Format("02 Jan 06"), req.EndDate.Format("02 Jan 06"))
 			case "unapprove":
-				req.ApprovedBy = ""
-				req.ApprovalDate = time.Date(1970, time.January, 1, 0, 0, 0, 0, time.UTC)
-				req.Status = "DRAFT"
-				for d, day := range req.RequestedDays {
-					day.Status = "REQUESTED"
-					req.RequestedDays[d] = day
+				err := e.transitionLeaveRequest(&req, LeaveDraft, actor, value)
+				if err != nil {
+					return "", nil, err
 				}
 				cmt := LeaveRequestComment{
 					CommentDate: time.Now().UTC(),
@@ -949,9 +940,9 @@ func (e *Employee) UpdateLeaveRequest(request, field, value string,
 				message = "Leave Request: Leave Request unapproved.\n" +
 					"Comment: " + value
 			case "day", "requestday":
-				bApproved := strings.EqualFold(req.Status, "approved")
+				bApproved := req.GetStatus() == LeaveApproved
 				parts := strings.Split(value, "|")
-				lvDate, _ := time.Parse("2006-01-02", parts[0])
+				lvDate, _ := ParseSiteDate(parts[0], loc)
 				code := parts[1]
 				hours, _ := strconv.ParseFloat(parts[2], 64)
 				found := false
@@ -1012,6 +1003,36 @@ func (e *Employee) UpdateLeaveRequest(request, field, value string,
 						e.Leaves = append(e.Leaves, *lv)
 					}
 				}
+			case "times":
+				// value is the date and times, like 2024-06-03|1300-1500.
+				parts := strings.Split(value, "|")
+				if len(parts) != 2 {
+					return "", nil, errors.New("times must be given as date|start-end")
+				}
+				lvDate, err := ParseSiteDate(parts[0], loc)
+				if err != nil {
+					return "", nil, err
+				}
+				start, end, err := ParseLeaveTimes(parts[1])
+				if err != nil {
+					return "", nil, err
+				}
+				found := false
+				for j, lv := range req.RequestedDays {
+					if lv.LeaveDate.Equal(lvDate) && lv.Code != "" {
+						if err := lv.SetTimes(start, end); err != nil {
+							return "", nil, err
+						}
+						req.RequestedDays[j] = lv
+						found = true
+					}
+				}
+				if !found {
+					return "", nil, errors.New("no leave requested on the date")
+				}
+				if req.GetStatus() == LeaveApproved {
+					e.ChangeApprovedLeaveDates(req)
+				}
 			case "comment", "addcomment":
 				newComment := &LeaveRequestComment{
 					CommentDate: time.Now().UTC(),
@@ -1075,7 +1096,7 @@ func (e *Employee) resetLeaveDays(value string, req LeaveRequest) LeaveRequest {
 					LeaveDate: start,
 					Code:      value,
 					Hours:     hours,
-					Status:    "REQUESTED",
+					Status:    LeaveRequested,
 				}
 				req.RequestedDays = append(req.RequestedDays, day)
 			} else {
@@ -1084,7 +1105,7 @@ func (e *Employee) resetLeaveDays(value string, req LeaveRequest) LeaveRequest {
 					LeaveDate: start,
 					Code:      "",
 					Hours:     0.0,
-					Status:    "REQUESTED",
+					Status:    LeaveRequested,
 				}
 				req.RequestedDays = append(req.RequestedDays, day)
 			}
@@ -1094,6 +1115,11 @@ func (e *Employee) resetLeaveDays(value string, req LeaveRequest) LeaveRequest {
 	return req
 }
 
+// ApproveLeaveRequest approves the request for the approver given as the
+// value, adding its leave days (or the variation of a modified time request).
+// A request that isn't requested (or already approved), or whose approval
+// steps weren't routed since it was requested, can't be approved, giving a
+// TransitionError.  The offset isn't used.
 func (e *Employee) ApproveLeaveRequest(request, field, value string,
 	offset float64, leavecodes []labor.Workcode) (string, *LeaveRequest, error) {
 
@@ -1103,9 +1129,9 @@ func (e *Employee) ApproveLeaveRequest(request, field, value string,
 	message := ""
 	for i, req := range e.Requests {
 		if req.ID == request {
-			req.ApprovedBy = value
-			req.ApprovalDate = time.Now().UTC()
-			req.Status = "APPROVED"
+			if err := req.Transition(LeaveApproved, value, ""); err != nil {
+				return "", nil, err
+			}
 			maxLvID := 0
 			// remove any leaves associated with this request
 			var deletes []int
@@ -1126,10 +1152,6 @@ func (e *Employee) ApproveLeaveRequest(request, field, value string,
 				}
 			}
 			if strings.ToLower(req.PrimaryCode) != "mod" {
-				for d, day := range req.RequestedDays {
-					day.Status = "APPROVED"
-					req.RequestedDays[d] = day
-				}
 				message = "Leave Request: Leave Request approved."
 				e.ChangeApprovedLeaveDates(req)
 			} else if strings.ToLower(req.PrimaryCode) == "mod" {
@@ -1158,7 +1180,7 @@ func (e *Employee) ApproveLeaveRequest(request, field, value string,
 									LeaveDate: day.LeaveDate,
 									Code:      day.Code,
 									Hours:     day.Hours,
-									Status:    "APPROVED",
+									Status:    LeaveApproved,
 									RequestID: req.ID,
 								}
 								e.Leaves = append(e.Leaves, lv)
@@ -1254,35 +1276,25 @@ func (e *Employee) ChangeApprovedLeaveDates(lr LeaveRequest) {
 		e.ConvertFromData()
 	}
 	// approved leave affects the leave listing, so we will
-	// remove old leaves for the period then add the new ones
-	startPos := -1
-	endPos := -1
+	// remove the request's old leaves then add the new ones
 	maxId := -1
-	sort.Sort(ByLeaveDay(e.Leaves))
-	for i, lv := range e.Leaves {
-		if (lv.LeaveDate.After(lr.StartDate) || lv.LeaveDate.Equal(lr.StartDate)) &&
-			(lv.LeaveDate.Before(lr.EndDate) || lv.LeaveDate.Equal(lr.EndDate)) {
-			if startPos < 0 {
-				startPos = i
-			} else {
-				endPos = i
-			}
-		}
+	for _, lv := range e.Leaves {
 		if maxId < lv.ID {
 			maxId = lv.ID
 		}
 	}
-	if startPos > 0 {
-		if endPos < 0 {
-			endPos = startPos
+	e.removeRequestLeave(lr)
+	// the request's days already taken are kept as they are.
+	taken := make(map[time.Time]bool)
+	for _, lv := range e.Leaves {
+		if lv.RequestID == lr.ID {
+			taken[lv.LeaveDate] = true
 		}
-		endPos++
-		e.Leaves = append(e.Leaves[:startPos], e.Leaves[endPos:]...)
 	}
 
 	// now add the leave request's leave days to the leave list. if now mod time
 	for _, lv := range lr.RequestedDays {
-		if lv.Hours > 0.0 {
+		if lv.Hours > 0.0 && !taken[lv.LeaveDate] {
 			maxId++
 			lv.ID = maxId
 			lv.Status = lr.Status
@@ -1315,32 +1327,7 @@ func (e *Employee) DeleteLeaveRequest(request string) (string, error) {
 	e.Requests = append(e.Requests[:pos], e.Requests[pos+1:]...)
 	// delete all leaves associated with this leave request, except if the leave
 	// has a status of actual
-	if strings.ToLower(deletable.PrimaryCode) != "mod" {
-		sort.Sort(ByLeaveDay(e.Leaves))
-		var deletes []int
-		for i, lv := range e.Leaves {
-			if lv.RequestID == request && strings.ToLower(lv.Status) != "actual" {
-				deletes = append(deletes, i)
-			}
-		}
-		if len(deletes) > 0 {
-			for i := len(deletes) - 1; i >= 0; i-- {
-				e.Leaves = append(e.Leaves[:deletes[i]],
-					e.Leaves[deletes[i]+1:]...)
-			}
-		}
-	} else {
-		pos = -1
-		for v, vari := range e.Variations {
-			if vari.IsMod && vari.StartDate.Equal(deletable.StartDate) &&
-				vari.EndDate.Equal(deletable.EndDate) {
-				pos = v
-			}
-		}
-		if pos >= 0 {
-			e.Variations = append(e.Variations[:pos], e.Variations[pos+1:]...)
-		}
-	}
+	e.removeRequestLeave(*deletable)
 	return message, nil
 }
 
@@ -1459,6 +1446,15 @@ func (e *Employee) GetWorkedHoursForLabor(chgno, ext string,
 	return answer
 }
 
+// GetForecastHoursAt provides the forecast hours of the labor code for the
+// calendar dates, at the site's location, of the moments start through end.
+func (e *Employee) GetForecastHoursAt(lCode labor.LaborCode,
+	start, end time.Time, workcodes []EmployeeCompareCode,
+	loc *time.Location) float64 {
+	return e.GetForecastHours(lCode, SiteDate(start, loc), SiteDate(end, loc),
+		workcodes, 0.0)
+}
+
 func (e *Employee) GetForecastHours(lCode labor.LaborCode,
 	start, end time.Time, workcodes []EmployeeCompareCode,
 	offset float64) float64 {
@@ -1532,7 +1528,15 @@ func (e *Employee) GetForecastHours(lCode labor.LaborCode,
 										for _, lc := range asgmt.LaborCodes {
 											if strings.EqualFold(lCode.ChargeNumber, lc.ChargeNumber) &&
 												strings.EqualFold(lCode.Extension, lc.Extension) {
-												answer += std
+												// a day split by partial leave is only
+												// partly worked.
+												hours := std
+												for _, lv := range e.GetPartialLeaves(current) {
+													hours -= lv.Hours
+												}
+												if hours > 0.0 {
+													answer += hours
+												}
 											}
 										}
 									}
@@ -0,0 +1,55 @@
+package employees
+
+import (
+	"testing"
+	"time"
+)
+
+func TestEmployeeConvertsLegacyData(t *testing.T) {
+	legacy := func() *Employee {
+		current := Employee{}
+		current.AddAssignment("s", "w", date(2024, time.January, 1))
+		return &Employee{
+			SiteID: "s",
+			Data: &EmployeeData{
+				Assignments: current.Assignments,
+				LaborCodes:  []EmployeeLaborCode{{ChargeNumber: "C", Extension: "E"}},
+			},
+		}
+	}
+	monday := date(2024, time.June, 3)
+	tests := []struct {
+		name string
+		use  func(e *Employee) bool
+	}{
+		{
+			name: "active",
+			use:  func(e *Employee) bool { return e.IsActive(monday) },
+		},
+		{
+			name: "workday",
+			use: func(e *Employee) bool {
+				wd := e.GetWorkday(monday, monday.AddDate(0, 0, -1))
+				return wd != nil && wd.Code == "D"
+			},
+		},
+		{
+			name: "at site",
+			use: func(e *Employee) bool {
+				return e.AtSite("s", monday, monday)
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			emp := legacy()
+			if !tt.use(emp) {
+				t.Error("legacy employee not converted")
+			}
+			if emp.Data != nil || len(emp.Assignments) != 1 ||
+				len(emp.Assignments[0].LaborCodes) != 1 {
+				t.Errorf("converted employee = %+v", emp)
+			}
+		})
+	}
+}
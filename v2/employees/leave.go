@@ -1,16 +1,33 @@
 package employees
 
 import (
+	"errors"
 	"sort"
+	"strconv"
+	"strings"
 	"time"
 )
 
+// AnnualLeave is a year's leave balance of a code, vacation ("V") when the
+// code is empty.
 type AnnualLeave struct {
 	Year      int     `json:"year" bson:"year"`
+	Code      string  `json:"code,omitempty" bson:"code,omitempty"`
 	Annual    float64 `json:"annual" bson:"annual"`
 	Carryover float64 `json:"carryover" bson:"carryover"`
 }
 
+// IsCode tells if the balance is for the leave code.
+func (al *AnnualLeave) IsCode(code string) bool {
+	if code == "" {
+		code = "V"
+	}
+	if al.Code == "" {
+		return strings.EqualFold(code, "V")
+	}
+	return strings.EqualFold(al.Code, code)
+}
+
 type ByBalance []AnnualLeave
 
 func (c ByBalance) Len() int { return len(c) }
@@ -28,6 +45,8 @@ type LeaveDay struct {
 	RequestID string    `json:"requestid" bson:"requestid"`
 	Used      bool      `json:"-" bson:"-"`
 	TagDay    string    `json:"tagday,omitempty" bson:"tagday,omitempty"`
+	StartTime uint      `json:"starttime,omitempty" bson:"starttime,omitempty"`
+	EndTime   uint      `json:"endtime,omitempty" bson:"endtime,omitempty"`
 }
 
 type ByLeaveDay []LeaveDay
@@ -38,6 +57,69 @@ func (c ByLeaveDay) Less(i, j int) bool {
 }
 func (c ByLeaveDay) Swap(i, j int) { c[i], c[j] = c[j], c[i] }
 
+// IsPartial tells if the leave is for part of the day, from its start time to
+// its end time (as 24 hour clock times like 1300).  Partial leave on a
+// scheduled workday splits the day between work and leave instead of
+// replacing it.
+func (lv *LeaveDay) IsPartial() bool {
+	return lv.StartTime != lv.EndTime
+}
+
+// SetTimes makes the leave partial, from the start time to the end time (past
+// midnight when the end is before the start), with the hours between them.
+func (lv *LeaveDay) SetTimes(start, end uint) error {
+	if start%100 > 59 || start > 2359 || end%100 > 59 || end > 2359 {
+		return errors.New("leave times must be 24 hour clock times")
+	}
+	if start == end {
+		return errors.New("leave times can't be the same")
+	}
+	lv.StartTime = start
+	lv.EndTime = end
+	lv.Hours = lv.GetTimeHours()
+	return nil
+}
+
+// GetTimeHours provides the hours from the leave's start time to its end time.
+func (lv *LeaveDay) GetTimeHours() float64 {
+	minutes := int(lv.EndTime/100*60+lv.EndTime%100) -
+		int(lv.StartTime/100*60+lv.StartTime%100)
+	if minutes < 0 {
+		minutes += 24 * 60
+	}
+	return float64(minutes) / 60.0
+}
+
+// TimesAt provides the moments partial leave starts and ends on its date at
+// the location.
+func (lv *LeaveDay) TimesAt(loc *time.Location) (time.Time, time.Time) {
+	if loc == nil {
+		loc = time.UTC
+	}
+	start := time.Date(lv.LeaveDate.Year(), lv.LeaveDate.Month(),
+		lv.LeaveDate.Day(), int(lv.StartTime/100), int(lv.StartTime%100), 0, 0,
+		loc)
+	return start, start.Add(time.Duration(lv.GetTimeHours() * float64(time.Hour)))
+}
+
+// ParseLeaveTimes reads a partial leave's start and end times given as
+// "1300-1500".
+func ParseLeaveTimes(value string) (uint, uint, error) {
+	parts := strings.Split(value, "-")
+	if len(parts) != 2 {
+		return 0, 0, errors.New("leave times must be given as start-end")
+	}
+	start, err := strconv.ParseUint(strings.TrimSpace(parts[0]), 10, 32)
+	if err != nil {
+		return 0, 0, err
+	}
+	end, err := strconv.ParseUint(strings.TrimSpace(parts[1]), 10, 32)
+	if err != nil {
+		return 0, 0, err
+	}
+	return uint(start), uint(end), nil
+}
+
 type LeaveRequestComment struct {
 	CommentDate time.Time `json:"commentdate" bson:"commentdate"`
 	Comment     string    `json:"comment" bson:"comment"`
@@ -52,17 +134,20 @@ func (c ByLeaveRequestComment) Less(i, j int) bool {
 func (c ByLeaveRequestComment) Swap(i, j int) { c[i], c[j] = c[j], c[i] }
 
 type LeaveRequest struct {
-	ID            string                `json:"id" bson:"id"`
-	EmployeeID    string                `json:"employeeid,omitempty"`
-	RequestDate   time.Time             `json:"requestDate" bson:"requestDate"`
-	PrimaryCode   string                `json:"primarycode" bson:"primarycode"`
-	StartDate     time.Time             `json:"startdate" bson:"startdate"`
-	EndDate       time.Time             `json:"enddate" bson:"enddate"`
-	Status        string                `json:"status" bson:"status"`
-	ApprovedBy    string                `json:"approvedby" bson:"approvedby"`
-	ApprovalDate  time.Time             `json:"approvalDate" bson:"approvalDate"`
-	RequestedDays []LeaveDay            `json:"requesteddays" bson:"requesteddays"`
-	Comments      []LeaveRequestComment `json:"comments,omitempty" bson:"comments,omitempty"`
+	ID            string                   `json:"id" bson:"id"`
+	EmployeeID    string                   `json:"employeeid,omitempty"`
+	RequestDate   time.Time                `json:"requestDate" bson:"requestDate"`
+	PrimaryCode   string                   `json:"primarycode" bson:"primarycode"`
+	StartDate     time.Time                `json:"startdate" bson:"startdate"`
+	EndDate       time.Time                `json:"enddate" bson:"enddate"`
+	Status        string                   `json:"status" bson:"status"`
+	ApprovedBy    string                   `json:"approvedby" bson:"approvedby"`
+	ApprovalDate  time.Time                `json:"approvalDate" bson:"approvalDate"`
+	RequestedDays []LeaveDay               `json:"requesteddays" bson:"requesteddays"`
+	Comments      []LeaveRequestComment    `json:"comments,omitempty" bson:"comments,omitempty"`
+	Approvals     []ApprovalStep           `json:"approvals,omitempty" bson:"approvals,omitempty"`
+	Routed        bool                     `json:"routed,omitempty" bson:"routed,omitempty"`
+	History       []LeaveRequestTransition `json:"history,omitempty" bson:"history,omitempty"`
 }
 
 type ByLeaveRequest []LeaveRequest
@@ -103,7 +188,7 @@ func (lr *LeaveRequest) SetLeaveDays(emp *Employee) {
 				LeaveDate: sDate,
 				Code:      lr.PrimaryCode,
 				Hours:     hours,
-				Status:    "REQUESTED",
+				Status:    LeaveRequested,
 				RequestID: lr.ID,
 			}
 			lr.RequestedDays = append(lr.RequestedDays, lv)
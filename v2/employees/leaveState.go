@@ -0,0 +1,206 @@
+package employees
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// The leave request statuses.  A request is drafted by the employee, then
+// requested (submitted for approval) and approved by a supervisor.  An
+// approved request goes back to requested when its dates change and needs
+// reapproval, or back to draft when it is unapproved, and a requested one can
+// be taken back to draft.
+const (
+	LeaveDraft     = "DRAFT"
+	LeaveRequested = "REQUESTED"
+	LeaveApproved  = "APPROVED"
+)
+
+// leaveTransitions is the statuses each status can go to.
+var leaveTransitions = map[string][]string{
+	LeaveDraft:     {LeaveRequested},
+	LeaveRequested: {LeaveDraft, LeaveApproved},
+	LeaveApproved:  {LeaveDraft, LeaveRequested, LeaveApproved},
+}
+
+// ErrInvalidTransition is matched (with errors.Is) by every TransitionError.
+var ErrInvalidTransition = errors.New("invalid leave request transition")
+
+// TransitionError tells which change of a leave request's status wasn't
+// allowed and why.
+type TransitionError struct {
+	RequestID string
+	From      string
+	To        string
+	Reason    string
+}
+
+func (e *TransitionError) Error() string {
+	return fmt.Sprintf("leave request %s can't go from %s to %s: %s",
+		e.RequestID, e.From, e.To, e.Reason)
+}
+
+func (e *TransitionError) Is(target error) bool {
+	return target == ErrInvalidTransition
+}
+
+// LeaveRequestTransition records a change of a leave request's status, who
+// made it, when and why.  The request's creation is recorded from a blank
+// status.
+type LeaveRequestTransition struct {
+	Date   time.Time `json:"date" bson:"date"`
+	Actor  string    `json:"actor,omitempty" bson:"actor,omitempty"`
+	From   string    `json:"from" bson:"from"`
+	To     string    `json:"to" bson:"to"`
+	Reason string    `json:"reason,omitempty" bson:"reason,omitempty"`
+}
+
+// GetStatus provides the request's status, in upper case as older requests
+// may have stored it in any case.
+func (lr *LeaveRequest) GetStatus() string {
+	return strings.ToUpper(lr.Status)
+}
+
+// CanTransition tells if the request's status can go to the status given.
+func (lr *LeaveRequest) CanTransition(to string) bool {
+	for _, status := range leaveTransitions[lr.GetStatus()] {
+		if status == to {
+			return true
+		}
+	}
+	return false
+}
+
+// Transition changes the request's status, after checking the change is
+// allowed and its guard:  a request needs leave days to be requested, and an
+// approver and all its approval steps approved to be approved, with the steps
+// routed (by RouteApprovals) since it was requested.  The change's effects on
+// the request are applied (the approval is set or cleared, with the steps'
+// approvals and routing, and the requested days' statuses follow the
+// request's) and it is added to the request's history.  The effects on the
+// employee's leaves are left to the employee's methods.
+func (lr *LeaveRequest) Transition(to, actor, reason string) error {
+	from := lr.GetStatus()
+	to = strings.ToUpper(to)
+	if !lr.CanTransition(to) {
+		return &TransitionError{
+			RequestID: lr.ID,
+			From:      from,
+			To:        to,
+			Reason:    "not allowed",
+		}
+	}
+	mod := strings.EqualFold(lr.PrimaryCode, "mod")
+	switch to {
+	case LeaveRequested:
+		if len(lr.RequestedDays) == 0 {
+			return &TransitionError{
+				RequestID: lr.ID,
+				From:      from,
+				To:        to,
+				Reason:    "request has no leave days",
+			}
+		}
+		for d, day := range lr.RequestedDays {
+			if (day.Code != "" && day.Status == "") ||
+				strings.EqualFold(day.Status, LeaveApproved) {
+				day.Status = LeaveRequested
+			}
+			lr.RequestedDays[d] = day
+		}
+		lr.ApprovedBy = ""
+		lr.ApprovalDate = time.Time{}
+		lr.resetApprovals()
+		lr.Routed = false
+	case LeaveApproved:
+		if actor == "" {
+			return &TransitionError{
+				RequestID: lr.ID,
+				From:      from,
+				To:        to,
+				Reason:    "no approver given",
+			}
+		}
+		if from == LeaveRequested && !lr.Routed {
+			return &TransitionError{
+				RequestID: lr.ID,
+				From:      from,
+				To:        to,
+				Reason:    "approval steps aren't routed",
+			}
+		}
+		if !lr.ApprovalsComplete() {
+			return &TransitionError{
+				RequestID: lr.ID,
+				From:      from,
+				To:        to,
+				Reason:    "approval steps aren't complete",
+			}
+		}
+		if !mod {
+			for d, day := range lr.RequestedDays {
+				day.Status = LeaveApproved
+				lr.RequestedDays[d] = day
+			}
+		}
+		lr.ApprovedBy = actor
+		lr.ApprovalDate = time.Now().UTC()
+	case LeaveDraft:
+		if !mod {
+			for d, day := range lr.RequestedDays {
+				day.Status = LeaveRequested
+				lr.RequestedDays[d] = day
+			}
+		}
+		lr.ApprovedBy = ""
+		lr.ApprovalDate = time.Time{}
+		lr.resetApprovals()
+		lr.Routed = false
+	}
+	lr.Status = to
+	lr.History = append(lr.History, LeaveRequestTransition{
+		Date:   time.Now().UTC(),
+		Actor:  actor,
+		From:   from,
+		To:     to,
+		Reason: reason,
+	})
+	return nil
+}
+
+// transitionLeaveRequest changes the request's status, removing the leave it
+// gave the employee when it stops being approved.
+func (e *Employee) transitionLeaveRequest(req *LeaveRequest, to, actor,
+	reason string) error {
+	approved := req.GetStatus() == LeaveApproved
+	if err := req.Transition(to, actor, reason); err != nil {
+		return err
+	}
+	if approved && req.GetStatus() != LeaveApproved {
+		e.removeRequestLeave(*req)
+	}
+	return nil
+}
+
+// removeRequestLeave removes the leave days (other than those already taken)
+// added for the request, or the variation of a modified time request.
+func (e *Employee) removeRequestLeave(req LeaveRequest) {
+	if !strings.EqualFold(req.PrimaryCode, "mod") {
+		for i := len(e.Leaves) - 1; i >= 0; i-- {
+			lv := e.Leaves[i]
+			if lv.RequestID == req.ID && !strings.EqualFold(lv.Status, "actual") {
+				e.Leaves = append(e.Leaves[:i], e.Leaves[i+1:]...)
+			}
+		}
+		return
+	}
+	for v, vari := range e.Variations {
+		if vari.IsMod && vari.StartDate.Equal(req.StartDate) &&
+			vari.EndDate.Equal(req.EndDate) {
+			e.Variations = append(e.Variations[:v], e.Variations[v+1:]...)
+			return
+		}
+	}
+}
@@ -0,0 +1,266 @@
+package employees
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLeaveRequestTransition(t *testing.T) {
+	days := []LeaveDay{
+		{LeaveDate: date(2024, time.June, 3), Code: "V", Hours: 8},
+		{LeaveDate: date(2024, time.June, 4), Code: "V", Hours: 8},
+	}
+	tests := []struct {
+		name    string
+		req     LeaveRequest
+		to      string
+		actor   string
+		wantErr bool
+		// the requested days' status after the transition.
+		dayStatus string
+		unrouted  bool
+	}{
+		{
+			name:      "draft to requested",
+			req:       LeaveRequest{Status: LeaveDraft},
+			to:        LeaveRequested,
+			actor:     "e",
+			dayStatus: LeaveRequested,
+		},
+		{
+			name:  "lower case status",
+			req:   LeaveRequest{Status: "draft"},
+			to:    "requested",
+			actor: "e",
+			// days with a blank status are requested.
+			dayStatus: LeaveRequested,
+		},
+		{
+			name:    "draft to approved",
+			req:     LeaveRequest{Status: LeaveDraft},
+			to:      LeaveApproved,
+			actor:   "boss",
+			wantErr: true,
+		},
+		{
+			name:    "requested without days",
+			req:     LeaveRequest{Status: LeaveDraft},
+			to:      LeaveRequested,
+			actor:   "e",
+			wantErr: true,
+		},
+		{
+			name:      "requested to approved",
+			req:       LeaveRequest{Status: LeaveRequested},
+			to:        LeaveApproved,
+			actor:     "boss",
+			dayStatus: LeaveApproved,
+		},
+		{
+			name:     "approved without routing",
+			req:      LeaveRequest{Status: LeaveRequested},
+			to:       LeaveApproved,
+			actor:    "boss",
+			unrouted: true,
+			wantErr:  true,
+		},
+		{
+			name:    "approved without an approver",
+			req:     LeaveRequest{Status: LeaveRequested},
+			to:      LeaveApproved,
+			wantErr: true,
+		},
+		{
+			name: "approved with steps left",
+			req: LeaveRequest{
+				Status:    LeaveRequested,
+				Approvals: []ApprovalStep{{Level: ApproverSupervisor}},
+			},
+			to:      LeaveApproved,
+			actor:   "boss",
+			wantErr: true,
+		},
+		{
+			name: "approved with the steps approved",
+			req: LeaveRequest{
+				Status: LeaveRequested,
+				Approvals: []ApprovalStep{{Level: ApproverSupervisor,
+					ApprovedBy: "boss"}},
+			},
+			to:        LeaveApproved,
+			actor:     "boss",
+			dayStatus: LeaveApproved,
+		},
+		{
+			name: "approved back to requested",
+			req: LeaveRequest{
+				Status:     LeaveApproved,
+				ApprovedBy: "boss",
+				Approvals: []ApprovalStep{{Level: ApproverSupervisor,
+					ApprovedBy: "boss"}},
+			},
+			to:        LeaveRequested,
+			actor:     "e",
+			dayStatus: LeaveRequested,
+		},
+		{
+			name:      "unapproved",
+			req:       LeaveRequest{Status: LeaveApproved, ApprovedBy: "boss"},
+			to:        LeaveDraft,
+			actor:     "boss",
+			dayStatus: LeaveRequested,
+		},
+		{
+			name:    "unknown status",
+			req:     LeaveRequest{Status: LeaveRequested},
+			to:      "CANCELLED",
+			actor:   "e",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := tt.req
+			req.ID = "r"
+			req.Routed = !tt.unrouted
+			if tt.name != "requested without days" {
+				req.RequestedDays = append([]LeaveDay{}, days...)
+			}
+			from := req.GetStatus()
+			err := req.Transition(tt.to, tt.actor, "why")
+			if tt.wantErr {
+				var te *TransitionError
+				if !errors.Is(err, ErrInvalidTransition) || !errors.As(err, &te) ||
+					te.From != from {
+					t.Fatalf("Transition error = %v", err)
+				}
+				if req.GetStatus() != from || len(req.History) != 0 {
+					t.Errorf("failed transition changed the request: %+v", req)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if req.Status != strings.ToUpper(tt.to) {
+				t.Errorf("status = %s, want %s", req.Status, tt.to)
+			}
+			if len(req.History) != 1 || req.History[0].From != from ||
+				req.History[0].To != req.Status || req.History[0].Actor != tt.actor {
+				t.Errorf("history = %+v", req.History)
+			}
+			approved := req.Status == LeaveApproved
+			if req.Routed != approved {
+				t.Errorf("routed = %v", req.Routed)
+			}
+			if approved != (req.ApprovedBy == tt.actor) ||
+				approved == req.ApprovalDate.IsZero() {
+				t.Errorf("approved by %q on %v", req.ApprovedBy, req.ApprovalDate)
+			}
+			for _, day := range req.RequestedDays {
+				if day.Status != tt.dayStatus {
+					t.Errorf("day status = %s, want %s", day.Status, tt.dayStatus)
+				}
+			}
+			for _, step := range req.Approvals {
+				if !approved && step.IsApproved() {
+					t.Errorf("approval kept: %+v", step)
+				}
+			}
+		})
+	}
+}
+
+func TestChangeApprovedLeaveDates(t *testing.T) {
+	emp := Employee{
+		Leaves: []LeaveDay{
+			{ID: 1, LeaveDate: date(2024, time.June, 3), Code: "V", Hours: 8,
+				Status: "ACTUAL", RequestID: "r"},
+			{ID: 2, LeaveDate: date(2024, time.June, 4), Code: "V", Hours: 8,
+				Status: LeaveApproved, RequestID: "r"},
+			{ID: 3, LeaveDate: date(2024, time.June, 5), Code: "H", Hours: 8,
+				Status: "ACTUAL"},
+			{ID: 4, LeaveDate: date(2024, time.June, 6), Code: "V", Hours: 8,
+				Status: LeaveApproved, RequestID: "other"},
+		},
+	}
+	// the request moves from June 3-4 to June 3 and 7.
+	emp.ChangeApprovedLeaveDates(LeaveRequest{
+		ID:     "r",
+		Status: LeaveApproved,
+		RequestedDays: []LeaveDay{
+			{LeaveDate: date(2024, time.June, 3), Code: "V", Hours: 8},
+			{LeaveDate: date(2024, time.June, 7), Code: "V", Hours: 8},
+			{LeaveDate: date(2024, time.June, 8), Code: "", Hours: 0},
+		},
+	})
+	want := []struct {
+		date   time.Time
+		status string
+		req    string
+	}{
+		{date(2024, time.June, 3), "ACTUAL", "r"},
+		{date(2024, time.June, 5), "ACTUAL", ""},
+		{date(2024, time.June, 6), LeaveApproved, "other"},
+		{date(2024, time.June, 7), LeaveApproved, "r"},
+	}
+	if len(emp.Leaves) != len(want) {
+		t.Fatalf("leaves = %+v", emp.Leaves)
+	}
+	for i, w := range want {
+		lv := emp.Leaves[i]
+		if !lv.LeaveDate.Equal(w.date) || lv.Status != w.status ||
+			lv.RequestID != w.req {
+			t.Errorf("leave %d = %+v, want %+v", i, lv, w)
+		}
+	}
+	if emp.Leaves[3].ID != 5 {
+		t.Errorf("new leave's id = %d, want 5", emp.Leaves[3].ID)
+	}
+}
+
+func TestUpdateLeaveRequestWithActor(t *testing.T) {
+	emp := Employee{}
+	emp.AddAssignment("s", "w", date(2024, time.January, 1))
+	req := emp.NewLeaveRequestAt("e", "V", date(2024, time.June, 3),
+		date(2024, time.June, 7), nil, "")
+
+	if _, _, err := emp.UpdateLeaveRequestWithActor(req.ID, "unapprove", "no",
+		"boss", nil); !errors.Is(err, ErrInvalidTransition) {
+		t.Fatalf("unapproving a draft error = %v", err)
+	}
+	_, got, err := emp.UpdateLeaveRequestWithActor(req.ID, "requested", "", "e",
+		nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Status != LeaveRequested || len(got.History) != 2 ||
+		got.History[1].Actor != "e" {
+		t.Fatalf("requested: %+v", got)
+	}
+	// the request is approved once its approval steps are routed.
+	if _, _, err := emp.ApproveLeaveRequest(req.ID, "", "boss", 0.0,
+		nil); !errors.Is(err, ErrInvalidTransition) {
+		t.Fatalf("approving an unrouted request error = %v", err)
+	}
+	emp.Requests[0].RouteApprovals(nil)
+	if _, _, err := emp.ApproveLeaveRequest(req.ID, "", "boss", 0.0,
+		nil); err != nil {
+		t.Fatal(err)
+	}
+	_, got, err = emp.UpdateLeaveRequestWithActor(req.ID, "unapprove", "no",
+		"boss", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	last := got.History[len(got.History)-1]
+	if got.Status != LeaveDraft || last.Actor != "boss" || last.Reason != "no" ||
+		got.ApprovedBy != "" {
+		t.Errorf("unapproved: %+v", got)
+	}
+	if len(emp.Leaves) != 0 {
+		t.Errorf("unapproved leaves kept: %+v", emp.Leaves)
+	}
+}
@@ -0,0 +1,70 @@
+package employees
+
+import (
+	"testing"
+	"time"
+)
+
+func TestLeaveDaySetTimes(t *testing.T) {
+	newYork, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Skip("no time zone data:", err)
+	}
+	tests := []struct {
+		name    string
+		start   uint
+		end     uint
+		hours   float64
+		wantErr bool
+	}{
+		{name: "afternoon", start: 1300, end: 1530, hours: 2.5},
+		{name: "past midnight", start: 2200, end: 200, hours: 4},
+		{name: "same times", start: 1300, end: 1300, wantErr: true},
+		{name: "bad minutes", start: 1360, end: 1500, wantErr: true},
+		{name: "bad hours", start: 1300, end: 2400, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			lv := LeaveDay{LeaveDate: date(2024, time.June, 3), Code: "V",
+				Hours: 8}
+			err := lv.SetTimes(tt.start, tt.end)
+			if tt.wantErr {
+				if err == nil || lv.IsPartial() || lv.Hours != 8 {
+					t.Errorf("SetTimes = %v, leave %+v", err, lv)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !lv.IsPartial() || lv.Hours != tt.hours {
+				t.Errorf("leave = %+v, want %v hours", lv, tt.hours)
+			}
+			start, end := lv.TimesAt(newYork)
+			if start.Hour() != int(tt.start/100) || start.Location() != newYork ||
+				end.Sub(start).Hours() != tt.hours {
+				t.Errorf("times = %v - %v", start, end)
+			}
+		})
+	}
+}
+
+func TestParseLeaveTimes(t *testing.T) {
+	tests := []struct {
+		value   string
+		start   uint
+		end     uint
+		wantErr bool
+	}{
+		{value: "1300-1500", start: 1300, end: 1500},
+		{value: " 800 - 1130 ", start: 800, end: 1130},
+		{value: "1300", wantErr: true},
+		{value: "1pm-3pm", wantErr: true},
+	}
+	for _, tt := range tests {
+		start, end, err := ParseLeaveTimes(tt.value)
+		if (err != nil) != tt.wantErr || start != tt.start || end != tt.end {
+			t.Errorf("ParseLeaveTimes(%q) = %d, %d, %v", tt.value, start, end, err)
+		}
+	}
+}
@@ -0,0 +1,66 @@
+package employees
+
+import (
+	"time"
+
+	"github.com/erneap/models/v2/labor"
+)
+
+// The dates in an employee's data (assignments, variations, leave and work)
+// are calendar dates, kept as midnight UTC.  The site's location is needed to
+// turn a moment (like now, or a timestamp from a client) into the calendar
+// date at the site, and a calendar date into the moment a shift starts.
+
+// SiteDate provides the calendar date of the moment at the location, as
+// midnight UTC.  A nil location is UTC.  Calendar dates aren't given to it,
+// since midnight UTC is a moment too (still the day before in the
+// Americas).
+func SiteDate(t time.Time, loc *time.Location) time.Time {
+	if loc == nil {
+		loc = time.UTC
+	}
+	t = t.In(loc)
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+// offsetLocation provides the location of a UTC offset in hours, for the
+// callers still giving an offset.
+func offsetLocation(offset float64) *time.Location {
+	if offset == 0.0 {
+		return time.UTC
+	}
+	return time.FixedZone("", int(offset*float64(time.Hour/time.Second)))
+}
+
+// ParseSiteDate parses a calendar date (2006-01-02) or a timestamp (RFC 3339),
+// whose calendar date at the location is provided.
+func ParseSiteDate(value string, loc *time.Location) (time.Time, error) {
+	date, err := time.ParseInLocation("2006-01-02", value, time.UTC)
+	if err == nil {
+		return date, nil
+	}
+	t, terr := time.Parse(time.RFC3339, value)
+	if terr != nil {
+		return date, err
+	}
+	return SiteDate(t, loc), nil
+}
+
+// ShiftTimes provides the moments the scheduled day's shift starts and ends
+// at the location, from the start time of its code's workcode.  The times are
+// zero for a day without work or a code without a workcode.  Because the
+// start is the local time on the date, it stays the same across daylight
+// saving changes.
+func (sd *ScheduleDay) ShiftTimes(workcodes []labor.Workcode,
+	loc *time.Location) (time.Time, time.Time) {
+	if !sd.IsWorkday() {
+		return time.Time{}, time.Time{}
+	}
+	for _, wc := range workcodes {
+		if wc.Id == sd.Code {
+			start := wc.StartsAt(sd.Date, loc)
+			return start, start.Add(time.Duration(sd.Hours * float64(time.Hour)))
+		}
+	}
+	return time.Time{}, time.Time{}
+}
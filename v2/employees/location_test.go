@@ -0,0 +1,128 @@
+package employees
+
+import (
+	"testing"
+	"time"
+
+	"github.com/erneap/models/v2/labor"
+)
+
+func TestSiteDate(t *testing.T) {
+	newYork, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Skip("no time zone data:", err)
+	}
+	tests := []struct {
+		name   string
+		moment time.Time
+		loc    *time.Location
+		want   time.Time
+	}{
+		{
+			name:   "evening in new york",
+			moment: time.Date(2024, time.July, 11, 2, 0, 0, 0, time.UTC),
+			loc:    newYork,
+			want:   date(2024, time.July, 10),
+		},
+		{
+			name:   "midnight utc in new york",
+			moment: time.Date(2024, time.July, 11, 0, 0, 0, 0, time.UTC),
+			loc:    newYork,
+			want:   date(2024, time.July, 10),
+		},
+		{
+			name:   "morning in new york",
+			moment: time.Date(2024, time.July, 11, 14, 0, 0, 0, time.UTC),
+			loc:    newYork,
+			want:   date(2024, time.July, 11),
+		},
+		{
+			name:   "local moment",
+			moment: time.Date(2024, time.January, 5, 23, 30, 0, 0, newYork),
+			loc:    newYork,
+			want:   date(2024, time.January, 5),
+		},
+		{
+			name:   "nil location is utc",
+			moment: time.Date(2024, time.July, 11, 2, 0, 0, 0, time.UTC),
+			want:   date(2024, time.July, 11),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := SiteDate(tt.moment, tt.loc); !got.Equal(tt.want) {
+				t.Errorf("SiteDate(%v) = %v, want %v", tt.moment, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseSiteDate(t *testing.T) {
+	newYork, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Skip("no time zone data:", err)
+	}
+	tests := []struct {
+		value   string
+		want    time.Time
+		wantErr bool
+	}{
+		{value: "2024-07-11", want: date(2024, time.July, 11)},
+		{value: "2024-07-11T02:00:00Z", want: date(2024, time.July, 10)},
+		{value: "2024-07-11T02:00:00-04:00", want: date(2024, time.July, 11)},
+		{value: "07/11/2024", wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := ParseSiteDate(tt.value, newYork)
+		if (err != nil) != tt.wantErr || !got.Equal(tt.want) && !tt.wantErr {
+			t.Errorf("ParseSiteDate(%q) = %v, %v, want %v", tt.value, got, err,
+				tt.want)
+		}
+	}
+}
+
+func TestScheduleDayShiftTimes(t *testing.T) {
+	newYork, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Skip("no time zone data:", err)
+	}
+	workcodes := []labor.Workcode{{Id: "D", StartTime: 7}}
+	tests := []struct {
+		name  string
+		day   ScheduleDay
+		start time.Time
+	}{
+		{
+			name: "standard time",
+			day: ScheduleDay{Date: date(2024, time.March, 9), Code: "D",
+				Hours: 12, Source: SourceAssignment},
+			start: time.Date(2024, time.March, 9, 12, 0, 0, 0, time.UTC),
+		},
+		{
+			name: "daylight saving time",
+			day: ScheduleDay{Date: date(2024, time.March, 10), Code: "D",
+				Hours: 12, Source: SourceAssignment},
+			start: time.Date(2024, time.March, 10, 11, 0, 0, 0, time.UTC),
+		},
+		{
+			name: "no workcode",
+			day: ScheduleDay{Date: date(2024, time.March, 10), Code: "N",
+				Hours: 12, Source: SourceAssignment},
+		},
+		{
+			name: "day off",
+			day:  ScheduleDay{Date: date(2024, time.March, 10)},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			start, end := tt.day.ShiftTimes(workcodes, newYork)
+			if !start.Equal(tt.start) {
+				t.Errorf("start = %v, want %v", start, tt.start)
+			}
+			if !tt.start.IsZero() && end.Sub(start) != 12*time.Hour {
+				t.Errorf("end = %v", end)
+			}
+		})
+	}
+}
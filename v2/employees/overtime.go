@@ -0,0 +1,160 @@
+package employees
+
+import (
+	"sort"
+	"time"
+
+	"github.com/erneap/models/v2/labor"
+)
+
+// OvertimeHours are the hours worked on a charge number (or, without one, in
+// total) split by the overtime rules.
+type OvertimeHours struct {
+	ChargeNumber string  `json:"chargeNumber,omitempty"`
+	Extension    string  `json:"extension,omitempty"`
+	Regular      float64 `json:"regular"`
+	Overtime     float64 `json:"overtime"`
+	Premium      float64 `json:"premium"`
+	CompTime     float64 `json:"comptime"`
+}
+
+type ByOvertimeHours []OvertimeHours
+
+func (c ByOvertimeHours) Len() int { return len(c) }
+func (c ByOvertimeHours) Less(i, j int) bool {
+	if c[i].ChargeNumber == c[j].ChargeNumber {
+		return c[i].Extension < c[j].Extension
+	}
+	return c[i].ChargeNumber < c[j].ChargeNumber
+}
+func (c ByOvertimeHours) Swap(i, j int) { c[i], c[j] = c[j], c[i] }
+
+func (o *OvertimeHours) GetTotal() float64 {
+	return o.Regular + o.Overtime + o.Premium + o.CompTime
+}
+
+func (o *OvertimeHours) add(hours OvertimeHours) {
+	o.Regular += hours.Regular
+	o.Overtime += hours.Overtime
+	o.Premium += hours.Premium
+	o.CompTime += hours.CompTime
+}
+
+// OvertimePeriod is an employee's hours for a pay period, in total and by
+// charge number.  CompTimeEarned is the compensatory time banked for the
+// period's comp time hours.
+type OvertimePeriod struct {
+	EmployeeID     string          `json:"employeeid"`
+	Name           string          `json:"name"`
+	StartDate      time.Time       `json:"startdate"`
+	EndDate        time.Time       `json:"enddate"`
+	Hours          OvertimeHours   `json:"hours"`
+	CompTimeEarned float64         `json:"comptimeEarned,omitempty"`
+	Charges        []OvertimeHours `json:"charges,omitempty"`
+}
+
+// ComputeOvertime splits the employee's work into regular, overtime, premium
+// and comp time hours by the rules, for each pay period with work from the
+// one holding start through the one holding end.  The work records are taken
+// in date (then charge number) order, so the hours over a limit are the last
+// ones worked.  Hours over the daily limit don't count towards the weekly
+// limit, holiday hours (with a holiday premium) count towards neither, and
+// mod time is always straight time.  The employee's work must be loaded for
+// the pay periods.
+func (e *Employee) ComputeOvertime(rules labor.OvertimeRules,
+	holidays []time.Time, start, end time.Time) []OvertimePeriod {
+	var answer []OvertimePeriod
+	first, _ := rules.PayPeriodOf(start)
+	_, last := rules.PayPeriodOf(end)
+
+	var work []Work
+	for _, wk := range e.Work {
+		if !wk.DateWorked.Before(first) &&
+			wk.DateWorked.Before(last.AddDate(0, 0, 1)) {
+			work = append(work, wk)
+		}
+	}
+	sort.Sort(ByEmployeeWork(work))
+
+	w := 0
+	for pStart := first; !pStart.After(last); {
+		_, pEnd := rules.PayPeriodOf(pStart)
+		period := OvertimePeriod{
+			EmployeeID: e.ID.Hex(),
+			Name:       e.Name.GetLastFirst(),
+			StartDate:  pStart,
+			EndDate:    pEnd,
+		}
+		next := pEnd.AddDate(0, 0, 1)
+		var week, day time.Time
+		weekly, daily := 0.0, 0.0
+		for ; w < len(work) && work[w].DateWorked.Before(next); w++ {
+			wk := work[w]
+			date := time.Date(wk.DateWorked.Year(), wk.DateWorked.Month(),
+				wk.DateWorked.Day(), 0, 0, 0, 0, time.UTC)
+			if ww := rules.WorkweekOf(date); !ww.Equal(week) {
+				week = ww
+				weekly = 0.0
+			}
+			if !date.Equal(day) {
+				day = date
+				daily = 0.0
+			}
+			hours := OvertimeHours{
+				ChargeNumber: wk.ChargeNumber,
+				Extension:    wk.Extension,
+			}
+			switch {
+			case rules.HolidayPremium && isHoliday(holidays, date):
+				hours.Premium = wk.Hours
+			case wk.ModifiedTime:
+				hours.Regular = wk.Hours
+			default:
+				straight := wk.Hours
+				if rules.DailyLimit > 0.0 && straight > rules.DailyLimit-daily {
+					straight = rules.DailyLimit - daily
+				}
+				if rules.WeeklyLimit > 0.0 && straight > rules.WeeklyLimit-weekly {
+					straight = rules.WeeklyLimit - weekly
+				}
+				daily += straight
+				weekly += straight
+				hours.Regular = straight
+				if rules.CompTime {
+					hours.CompTime = wk.Hours - straight
+				} else {
+					hours.Overtime = wk.Hours - straight
+				}
+			}
+			period.Hours.add(hours)
+			found := false
+			for c, charge := range period.Charges {
+				if charge.ChargeNumber == hours.ChargeNumber &&
+					charge.Extension == hours.Extension {
+					charge.add(hours)
+					period.Charges[c] = charge
+					found = true
+				}
+			}
+			if !found {
+				period.Charges = append(period.Charges, hours)
+			}
+		}
+		if len(period.Charges) > 0 {
+			sort.Sort(ByOvertimeHours(period.Charges))
+			period.CompTimeEarned = period.Hours.CompTime * rules.GetCompTimeRate()
+			answer = append(answer, period)
+		}
+		pStart = next
+	}
+	return answer
+}
+
+func isHoliday(holidays []time.Time, date time.Time) bool {
+	for _, hol := range holidays {
+		if sameDay(hol, date) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,122 @@
+package employees
+
+import (
+	"testing"
+	"time"
+
+	"github.com/erneap/models/v2/labor"
+)
+
+func TestComputeOvertime(t *testing.T) {
+	// Monday through Friday, June 3-7 2024, nine hours a day on A, and four
+	// hours on B the Saturday.
+	week := []Work{
+		{DateWorked: date(2024, time.June, 3), ChargeNumber: "A", Hours: 9},
+		{DateWorked: date(2024, time.June, 4), ChargeNumber: "A", Hours: 9},
+		{DateWorked: date(2024, time.June, 5), ChargeNumber: "A", Hours: 9},
+		{DateWorked: date(2024, time.June, 6), ChargeNumber: "A", Hours: 9},
+		{DateWorked: date(2024, time.June, 7), ChargeNumber: "A", Hours: 9},
+		{DateWorked: date(2024, time.June, 8), ChargeNumber: "B", Hours: 4},
+	}
+	// the Monday after, June 10, a holiday.
+	holiday := Work{DateWorked: date(2024, time.June, 10), ChargeNumber: "A",
+		Hours: 8}
+
+	weekly := labor.DefaultOvertimeRules()
+	daily := labor.DefaultOvertimeRules()
+	daily.DailyLimit = 8
+	daily.HolidayPremium = true
+	daily.CompTime = true
+	daily.PayPeriodWeeks = 2
+	daily.PayPeriodStart = date(2024, time.June, 2)
+
+	tests := []struct {
+		name     string
+		work     []Work
+		rules    labor.OvertimeRules
+		holidays []time.Time
+		start    time.Time
+		end      time.Time
+		want     []OvertimeHours
+		earned   float64
+		charges  []OvertimeHours
+	}{
+		{
+			name:  "weekly limit",
+			work:  week,
+			rules: weekly,
+			start: date(2024, time.June, 3),
+			end:   date(2024, time.June, 8),
+			want:  []OvertimeHours{{Regular: 40, Overtime: 9}},
+			// the last hours worked are the overtime.
+			charges: []OvertimeHours{
+				{ChargeNumber: "A", Regular: 40, Overtime: 5},
+				{ChargeNumber: "B", Overtime: 4},
+			},
+		},
+		{
+			name:  "a period for each week",
+			work:  append(append([]Work{}, week...), holiday),
+			rules: weekly,
+			start: date(2024, time.June, 3),
+			end:   date(2024, time.June, 10),
+			want: []OvertimeHours{{Regular: 40, Overtime: 9},
+				{Regular: 8}},
+		},
+		{
+			name:     "daily limit, holiday premium and comp time",
+			work:     append(append([]Work{}, week...), holiday),
+			rules:    daily,
+			holidays: []time.Time{date(2024, time.June, 10)},
+			start:    date(2024, time.June, 3),
+			end:      date(2024, time.June, 10),
+			want:     []OvertimeHours{{Regular: 40, CompTime: 9, Premium: 8}},
+			earned:   13.5,
+		},
+		{
+			name: "mod time is straight time",
+			work: append(append([]Work{}, week...),
+				Work{DateWorked: date(2024, time.June, 8), ChargeNumber: "B",
+					Hours: 4, ModifiedTime: true}),
+			rules: weekly,
+			start: date(2024, time.June, 3),
+			end:   date(2024, time.June, 8),
+			want:  []OvertimeHours{{Regular: 44, Overtime: 9}},
+		},
+		{
+			name:  "no work",
+			rules: weekly,
+			start: date(2024, time.June, 3),
+			end:   date(2024, time.June, 8),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			emp := Employee{Work: tt.work}
+			got := emp.ComputeOvertime(tt.rules, tt.holidays, tt.start, tt.end)
+			if len(got) != len(tt.want) {
+				t.Fatalf("got %d periods, want %d: %+v", len(got), len(tt.want), got)
+			}
+			for p, want := range tt.want {
+				if got[p].Hours != want {
+					t.Errorf("period %d hours = %+v, want %+v", p, got[p].Hours, want)
+				}
+			}
+			if len(got) > 0 && got[0].CompTimeEarned != tt.earned {
+				t.Errorf("comp time earned = %v, want %v", got[0].CompTimeEarned,
+					tt.earned)
+			}
+			if tt.charges != nil {
+				if len(got[0].Charges) != len(tt.charges) {
+					t.Fatalf("charges = %+v, want %+v", got[0].Charges, tt.charges)
+				}
+				for c, want := range tt.charges {
+					if got[0].Charges[c] != want {
+						t.Errorf("charge %d = %+v, want %+v", c, got[0].Charges[c],
+							want)
+					}
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,253 @@
+package employees
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+)
+
+// PatternDay is a single day of a rotation pattern:  the pattern's shift
+// worked (one for the first shift, two for the second, zero for a day off)
+// and, for patterns with set hours, the hours worked.
+type PatternDay struct {
+	Shift int
+	Hours float64
+}
+
+// RotationPattern is a standard shift rotation, given as one cycle of days.
+type RotationPattern struct {
+	Name        string
+	Description string
+	Shifts      int
+	Days        []PatternDay
+}
+
+func patternDays(shifts ...int) []PatternDay {
+	answer := make([]PatternDay, len(shifts))
+	for i, shift := range shifts {
+		answer[i] = PatternDay{Shift: shift}
+	}
+	return answer
+}
+
+// RotationPatterns are the known rotation patterns.
+var RotationPatterns = []RotationPattern{
+	{
+		Name: "panama",
+		Description: "Panama 2-2-3: two on, two off, three on, two off, two " +
+			"on, three off on a single shift",
+		Shifts: 1,
+		Days:   patternDays(1, 1, 0, 0, 1, 1, 1, 0, 0, 1, 1, 0, 0, 0),
+	},
+	{
+		Name: "pitman",
+		Description: "Pitman: the 2-2-3 cycle worked two weeks on days, then " +
+			"two weeks on nights",
+		Shifts: 2,
+		Days: patternDays(1, 1, 0, 0, 1, 1, 1, 0, 0, 1, 1, 0, 0, 0,
+			2, 2, 0, 0, 2, 2, 2, 0, 0, 2, 2, 0, 0, 0),
+	},
+	{
+		Name: "dupont",
+		Description: "DuPont: four nights, three off, three days, one off, " +
+			"three nights, three off, four days, seven off",
+		Shifts: 2,
+		Days: patternDays(2, 2, 2, 2, 0, 0, 0, 1, 1, 1, 0, 2, 2, 2, 0, 0, 0,
+			1, 1, 1, 1, 0, 0, 0, 0, 0, 0, 0),
+	},
+	{
+		Name:        "4on4off",
+		Description: "4-on/4-off: four on, four off on a single shift",
+		Shifts:      1,
+		Days:        patternDays(1, 1, 1, 1, 0, 0, 0, 0),
+	},
+	{
+		Name: "549",
+		Description: "5/4/9: four nine hour days and an eight hour day, then " +
+			"four nine hour days and a day off, Monday through Friday",
+		Shifts: 1,
+		Days: []PatternDay{{}, {1, 9}, {1, 9}, {1, 9}, {1, 9}, {1, 8}, {},
+			{}, {1, 9}, {1, 9}, {1, 9}, {1, 9}, {}, {}},
+	},
+}
+
+// GetRotationPattern provides the known pattern with the name given.
+func GetRotationPattern(name string) (*RotationPattern, error) {
+	for i, pat := range RotationPatterns {
+		if strings.EqualFold(pat.Name, name) {
+			return &RotationPatterns[i], nil
+		}
+	}
+	return nil, fmt.Errorf("no rotation pattern %q", name)
+}
+
+// RotationOptions picks the pattern and how it is worked:  a shift code for
+// each of the pattern's shifts, the hours for the days the pattern doesn't set,
+// the workcenter and the date the pattern's first day falls on.
+type RotationOptions struct {
+	Pattern    string    `json:"pattern"`
+	Codes      []string  `json:"codes"`
+	Hours      float64   `json:"hours"`
+	Workcenter string    `json:"workcenter"`
+	Anchor     time.Time `json:"anchor"`
+}
+
+// ApplyRotation replaces the assignment's schedules with the rotation pattern.
+// The pattern is laid out over as many weeks as it takes to repeat on the
+// same weekday, as one schedule for each week rotating every seven days, so
+// the schedules work with GetWorkday and can still be edited by week.
+func (a *Assignment) ApplyRotation(opts RotationOptions) error {
+	pat, err := GetRotationPattern(opts.Pattern)
+	if err != nil {
+		return err
+	}
+	if len(opts.Codes) < pat.Shifts {
+		return fmt.Errorf("the %s pattern needs %d shift codes", pat.Name,
+			pat.Shifts)
+	}
+	if opts.Hours <= 0 {
+		for _, day := range pat.Days {
+			if day.Shift > 0 && day.Hours <= 0 {
+				return errors.New("rotation hours must be above zero")
+			}
+		}
+	}
+
+	cycle := len(pat.Days)
+	days := cycle * 7 / gcd(cycle, 7)
+	start := scheduleStart(a.StartDate)
+	anchor := time.Date(opts.Anchor.Year(), opts.Anchor.Month(),
+		opts.Anchor.Day(), 0, 0, 0, 0, time.UTC)
+	offset := int(math.Round(anchor.Sub(start).Hours() / 24))
+
+	a.Schedules = a.Schedules[:0]
+	for week := 0; week < days/7; week++ {
+		a.AddSchedule(7)
+		sch := &a.Schedules[week]
+		for i := range sch.Workdays {
+			day := pat.Days[(((week*7+i-offset)%cycle)+cycle)%cycle]
+			if day.Shift == 0 {
+				continue
+			}
+			sch.Workdays[i].Workcenter = opts.Workcenter
+			sch.Workdays[i].Code = opts.Codes[day.Shift-1]
+			sch.Workdays[i].Hours = opts.Hours
+			if day.Hours > 0 {
+				sch.Workdays[i].Hours = day.Hours
+			}
+		}
+	}
+	a.RotationDate = anchor
+	a.RotationDays = 0
+	if len(a.Schedules) > 1 {
+		a.RotationDays = 7
+	}
+	return nil
+}
+
+// DetectRotation finds the known pattern the assignment's schedules follow,
+// giving the options that would build them, or false if they don't follow
+// one.  A day off is one without a code, and each of the pattern's shifts
+// must have its own code.
+func (a *Assignment) DetectRotation() (*RotationOptions, bool) {
+	if len(a.Schedules) == 0 {
+		return nil, false
+	}
+	period := len(a.Schedules[0].Workdays)
+	if len(a.Schedules) > 1 && a.RotationDays > 0 {
+		period = a.RotationDays * len(a.Schedules)
+		for _, sch := range a.Schedules {
+			if len(sch.Workdays) == 0 {
+				return nil, false
+			}
+			period = period * len(sch.Workdays) / gcd(period, len(sch.Workdays))
+		}
+	}
+	if period == 0 || period > 366 {
+		return nil, false
+	}
+	start := scheduleStart(a.StartDate)
+	actual := make([]Workday, period)
+	for i := range actual {
+		if wd := a.GetWorkday(start.AddDate(0, 0, i)); wd != nil {
+			actual[i] = *wd
+		}
+	}
+
+	for _, pat := range RotationPatterns {
+		cycle := len(pat.Days)
+		length := period * cycle / gcd(period, cycle)
+		for shift := 0; shift < cycle; shift++ {
+			if opts, ok := matchRotation(&pat, actual, shift, length); ok {
+				opts.Anchor = start.AddDate(0, 0, (cycle-shift)%cycle)
+				return opts, true
+			}
+		}
+	}
+	return nil, false
+}
+
+// matchRotation compares the workdays to the pattern started shift days into
+// its cycle.
+func matchRotation(pat *RotationPattern, actual []Workday, shift,
+	length int) (*RotationOptions, bool) {
+	opts := &RotationOptions{
+		Pattern: pat.Name,
+		Codes:   make([]string, pat.Shifts),
+	}
+	for i := 0; i < length; i++ {
+		wd := actual[i%len(actual)]
+		day := pat.Days[(i+shift)%len(pat.Days)]
+		if day.Shift == 0 {
+			if wd.Code != "" {
+				return nil, false
+			}
+			continue
+		}
+		if wd.Code == "" {
+			return nil, false
+		}
+		code := opts.Codes[day.Shift-1]
+		if code == "" {
+			for _, other := range opts.Codes {
+				if strings.EqualFold(other, wd.Code) {
+					return nil, false
+				}
+			}
+			opts.Codes[day.Shift-1] = wd.Code
+		} else if !strings.EqualFold(code, wd.Code) {
+			return nil, false
+		}
+		if day.Hours > 0 {
+			if wd.Hours != day.Hours {
+				return nil, false
+			}
+		} else if opts.Hours == 0 {
+			opts.Hours = wd.Hours
+		}
+		if opts.Workcenter == "" {
+			opts.Workcenter = wd.Workcenter
+		}
+	}
+	return opts, true
+}
+
+// scheduleStart provides the Sunday on or before the date, where an
+// assignment's schedule starts.
+func scheduleStart(date time.Time) time.Time {
+	start := time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0,
+		time.UTC)
+	for start.Weekday() != time.Sunday {
+		start = start.AddDate(0, 0, -1)
+	}
+	return start
+}
+
+func gcd(a, b int) int {
+	for b != 0 {
+		a, b = b, a%b
+	}
+	return a
+}
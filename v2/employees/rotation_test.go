@@ -0,0 +1,110 @@
+package employees
+
+import (
+	"testing"
+	"time"
+)
+
+func date(year int, month time.Month, day int) time.Time {
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+}
+
+func TestApplyAndDetectRotation(t *testing.T) {
+	anchor := date(2024, time.March, 9)
+	for _, pat := range RotationPatterns {
+		t.Run(pat.Name, func(t *testing.T) {
+			a := Assignment{
+				StartDate: date(2024, time.March, 6),
+				EndDate:   date(9999, time.December, 31),
+			}
+			err := a.ApplyRotation(RotationOptions{
+				Pattern:    pat.Name,
+				Codes:      []string{"D", "N"},
+				Hours:      12,
+				Workcenter: "wc",
+				Anchor:     anchor,
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			// each day of two cycles from the anchor follows the pattern.
+			for i := 0; i < 2*len(pat.Days); i++ {
+				day := pat.Days[i%len(pat.Days)]
+				wd := a.GetWorkday(anchor.AddDate(0, 0, i))
+				code := ""
+				if wd != nil {
+					code = wd.Code
+				}
+				want := ""
+				if day.Shift > 0 {
+					want = []string{"D", "N"}[day.Shift-1]
+				}
+				if code != want {
+					t.Fatalf("day %d code = %q, want %q", i, code, want)
+				}
+			}
+
+			opts, ok := a.DetectRotation()
+			if !ok {
+				t.Fatal("rotation not detected")
+			}
+			if opts.Pattern != pat.Name || opts.Workcenter != "wc" {
+				t.Errorf("detected %+v", opts)
+			}
+
+			// the detected options (whose anchor and codes may start at another
+			// point of the cycle) build the same schedules.
+			b := Assignment{StartDate: a.StartDate, EndDate: a.EndDate}
+			if err := b.ApplyRotation(*opts); err != nil {
+				t.Fatal(err)
+			}
+			for i := 0; i < 2*len(pat.Days); i++ {
+				d := anchor.AddDate(0, 0, i)
+				wa, wb := a.GetWorkday(d), b.GetWorkday(d)
+				if (wa == nil) != (wb == nil) ||
+					(wa != nil && (wa.Code != wb.Code || wa.Hours != wb.Hours)) {
+					t.Fatalf("%s: applied %v, detected %v", d.Format("2006-01-02"),
+						wa, wb)
+				}
+			}
+		})
+	}
+}
+
+func TestApplyRotationErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		opts RotationOptions
+	}{
+		{
+			name: "unknown pattern",
+			opts: RotationOptions{Pattern: "none", Codes: []string{"D"}, Hours: 8},
+		},
+		{
+			name: "missing shift code",
+			opts: RotationOptions{Pattern: "dupont", Codes: []string{"D"},
+				Hours: 12},
+		},
+		{
+			name: "no hours",
+			opts: RotationOptions{Pattern: "panama", Codes: []string{"D"}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := Assignment{StartDate: date(2024, time.March, 6)}
+			if err := a.ApplyRotation(tt.opts); err == nil {
+				t.Error("ApplyRotation gave no error")
+			}
+		})
+	}
+}
+
+func TestDetectRotationWeekly(t *testing.T) {
+	e := Employee{}
+	e.AddAssignment("s", "wc", date(2024, time.March, 6))
+	if opts, ok := e.Assignments[0].DetectRotation(); ok {
+		t.Errorf("weekly schedule detected as %+v", opts)
+	}
+}
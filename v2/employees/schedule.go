@@ -0,0 +1,239 @@
+package employees
+
+import (
+	"strings"
+	"time"
+)
+
+// ScheduleSource names the layer of the employee's data a scheduled day comes
+// from.  The layers are applied in this order, each one overriding the ones
+// before it:  the assignment's schedule, the variations (mod time variations
+// are marked separately), approved or actual leave, and the work actually
+// recorded for the day.
+type ScheduleSource string
+
+const (
+	SourceNone         ScheduleSource = ""
+	SourceAssignment   ScheduleSource = "assignment"
+	SourceVariation    ScheduleSource = "variation"
+	SourceModVariation ScheduleSource = "modvariation"
+	SourceLeave        ScheduleSource = "leave"
+	SourceWork         ScheduleSource = "work"
+)
+
+// ScheduleLayer is what a single layer gives for the day.  The ID is the
+// assignment, variation or (first) leave day's id, and isn't used for work.
+type ScheduleLayer struct {
+	Source     ScheduleSource `json:"source"`
+	ID         uint           `json:"id"`
+	Workcenter string         `json:"workcenter"`
+	Code       string         `json:"code"`
+	Hours      float64        `json:"hours"`
+}
+
+// ScheduleDay is the employee's effective schedule for a day, from the top
+// layer, with the layers it overrode in the order they were applied.  A day
+// without an assignment has no source.  A workday split by partial leave
+// keeps its work with the scheduled hours less the leave's, and has the
+// leave's code, hours and times (from the first leave's start to the last
+// one's end).
+type ScheduleDay struct {
+	Date       time.Time       `json:"date"`
+	Site       string          `json:"site"`
+	Workcenter string          `json:"workcenter"`
+	Code       string          `json:"code"`
+	Hours      float64         `json:"hours"`
+	Source     ScheduleSource  `json:"source"`
+	Overridden []ScheduleLayer `json:"overridden,omitempty"`
+	LeaveCode  string          `json:"leavecode,omitempty"`
+	LeaveHours float64         `json:"leavehours,omitempty"`
+	LeaveStart uint            `json:"leavestart,omitempty"`
+	LeaveEnd   uint            `json:"leaveend,omitempty"`
+}
+
+// IsSplit tells if the day is split between work and partial leave.
+func (sd *ScheduleDay) IsSplit() bool {
+	return sd.LeaveHours > 0.0 && sd.IsWorkday()
+}
+
+// IsWorkday tells if the employee works (or worked) the day.
+func (sd *ScheduleDay) IsWorkday() bool {
+	return sd.Code != "" && (sd.Source == SourceAssignment ||
+		sd.Source == SourceVariation || sd.Source == SourceModVariation ||
+		sd.Source == SourceWork)
+}
+
+// ResolveSchedule provides the employee's effective schedule for each day
+// from start through end.  The work layer needs the employee's work records
+// loaded for the period.
+func (e *Employee) ResolveSchedule(start, end time.Time) []ScheduleDay {
+	var answer []ScheduleDay
+	day := time.Date(start.Year(), start.Month(), start.Day(), 0, 0, 0, 0,
+		time.UTC)
+	last := time.Date(end.Year(), end.Month(), end.Day(), 0, 0, 0, 0, time.UTC)
+	for !day.After(last) {
+		answer = append(answer, e.ResolveDay(day))
+		day = day.AddDate(0, 0, 1)
+	}
+	return answer
+}
+
+// ResolveScheduleAt provides the employee's effective schedule for each
+// calendar date at the location from the moment start through the moment end,
+// like ResolveSchedule.
+func (e *Employee) ResolveScheduleAt(start, end time.Time,
+	loc *time.Location) []ScheduleDay {
+	return e.ResolveSchedule(SiteDate(start, loc), SiteDate(end, loc))
+}
+
+// ResolveDay provides the employee's effective schedule for the date.
+func (e *Employee) ResolveDay(date time.Time) ScheduleDay {
+	date = time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0,
+		time.UTC)
+	answer := ScheduleDay{
+		Date: date,
+	}
+	site, layers := e.scheduleLayers(date)
+	answer.Site = site
+
+	// partial leave splits a scheduled workday, unless it takes all of it.
+	var partial []LeaveDay
+	if len(layers) > 0 && layers[len(layers)-1].Code != "" {
+		partial = e.GetPartialLeaves(date)
+		hours := 0.0
+		for _, lv := range partial {
+			hours += lv.Hours
+		}
+		if hours >= layers[len(layers)-1].Hours &&
+			layers[len(layers)-1].Hours > 0.0 {
+			partial = nil
+		}
+	}
+
+	var leave *ScheduleLayer
+	for _, lv := range e.Leaves {
+		if len(partial) > 0 && lv.IsPartial() {
+			continue
+		}
+		if sameDay(lv.LeaveDate, date) &&
+			(strings.EqualFold(lv.Status, "actual") ||
+				strings.EqualFold(lv.Status, "approved")) {
+			if leave == nil {
+				leave = &ScheduleLayer{
+					Source: SourceLeave,
+					ID:     uint(lv.ID),
+					Code:   lv.Code,
+				}
+			} else if lv.Hours > leave.Hours {
+				leave.Code = lv.Code
+			}
+			leave.Hours += lv.Hours
+		}
+	}
+	if leave != nil {
+		layers = append(layers, *leave)
+	}
+
+	work := 0.0
+	for _, wk := range e.Work {
+		if sameDay(wk.DateWorked, date) && !wk.ModifiedTime {
+			work += wk.Hours
+		}
+	}
+	if work > 0.0 {
+		// work on a day off is given the code of the last scheduled workday.
+		wkday := ScheduleLayer{
+			Source: SourceWork,
+			Hours:  work,
+		}
+		for back := 0; back < 28 && wkday.Code == ""; back++ {
+			_, sched := e.scheduleLayers(date.AddDate(0, 0, -back))
+			if len(sched) > 0 {
+				wkday.Workcenter = sched[len(sched)-1].Workcenter
+				wkday.Code = sched[len(sched)-1].Code
+			}
+		}
+		layers = append(layers, wkday)
+	}
+
+	if len(layers) > 0 {
+		top := layers[len(layers)-1]
+		answer.Workcenter = top.Workcenter
+		answer.Code = top.Code
+		answer.Hours = top.Hours
+		answer.Source = top.Source
+		if len(layers) > 1 {
+			answer.Overridden = layers[:len(layers)-1]
+		}
+	}
+	if answer.Source != SourceLeave {
+		most := 0.0
+		for l, lv := range partial {
+			if lv.Hours > most {
+				answer.LeaveCode = lv.Code
+				most = lv.Hours
+			}
+			if l == 0 {
+				answer.LeaveStart = lv.StartTime
+			}
+			answer.LeaveEnd = lv.EndTime
+			answer.LeaveHours += lv.Hours
+		}
+		if answer.Source != SourceWork && answer.Hours > answer.LeaveHours {
+			answer.Hours -= answer.LeaveHours
+		}
+	}
+	return answer
+}
+
+// scheduleLayers provides the site and the assignment and variation layers
+// for the date.
+func (e *Employee) scheduleLayers(date time.Time) (string, []ScheduleLayer) {
+	var answer []ScheduleLayer
+	site := ""
+	for _, asgmt := range e.Assignments {
+		if (asgmt.StartDate.Before(date) || asgmt.StartDate.Equal(date)) &&
+			(asgmt.EndDate.After(date) || asgmt.EndDate.Equal(date)) &&
+			len(asgmt.Schedules) > 0 && len(asgmt.Schedules[0].Workdays) > 0 {
+			site = asgmt.Site
+			layer := ScheduleLayer{
+				Source: SourceAssignment,
+				ID:     asgmt.ID,
+			}
+			if wd := asgmt.GetWorkday(date); wd != nil {
+				layer.Workcenter = wd.Workcenter
+				layer.Code = wd.Code
+				layer.Hours = wd.Hours
+			}
+			if len(answer) > 0 {
+				answer[0] = layer
+			} else {
+				answer = append(answer, layer)
+			}
+		}
+	}
+	for _, vari := range e.Variations {
+		if (vari.StartDate.Before(date) || vari.StartDate.Equal(date)) &&
+			(vari.EndDate.After(date) || vari.EndDate.Equal(date)) &&
+			len(vari.Schedule.Workdays) > 0 {
+			layer := ScheduleLayer{
+				Source: SourceVariation,
+				ID:     vari.ID,
+			}
+			if vari.IsMod {
+				layer.Source = SourceModVariation
+			}
+			if wd := vari.GetWorkday(site, date); wd != nil {
+				layer.Workcenter = wd.Workcenter
+				layer.Code = wd.Code
+				layer.Hours = wd.Hours
+			}
+			answer = append(answer, layer)
+		}
+	}
+	return site, answer
+}
+
+func sameDay(a, b time.Time) bool {
+	return a.Year() == b.Year() && a.Month() == b.Month() && a.Day() == b.Day()
+}
@@ -0,0 +1,188 @@
+package employees
+
+import (
+	"errors"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// The shift swap statuses.  A swap is requested by the employee giving up
+// the shift, accepted (or declined) by the counterpart, then approved (or
+// rejected) by a supervisor.  A swap can be cancelled until it is finished,
+// and cancelling an approved swap removes the variations it made.
+const (
+	SwapRequested = "requested"
+	SwapAccepted  = "accepted"
+	SwapDeclined  = "declined"
+	SwapApproved  = "approved"
+	SwapRejected  = "rejected"
+	SwapCancelled = "cancelled"
+)
+
+// ShiftSwap trades the requester's shift on the requester's date for the
+// counterpart's shift on the counterpart's date (which can be the same date).
+// The workdays traded are copied when the swap is requested, and approval
+// adds variations with them to both employees, whose ids are kept so a
+// cancel can remove them.
+type ShiftSwap struct {
+	ID                    primitive.ObjectID    `json:"id" bson:"_id"`
+	TeamID                primitive.ObjectID    `json:"team" bson:"team"`
+	RequesterID           primitive.ObjectID    `json:"requesterid" bson:"requesterid"`
+	CounterpartID         primitive.ObjectID    `json:"counterpartid" bson:"counterpartid"`
+	RequestDate           time.Time             `json:"requestdate" bson:"requestdate"`
+	RequesterDate         time.Time             `json:"requesterdate" bson:"requesterdate"`
+	RequesterWorkday      Workday               `json:"requesterworkday" bson:"requesterworkday"`
+	CounterpartDate       time.Time             `json:"counterpartdate" bson:"counterpartdate"`
+	CounterpartWorkday    Workday               `json:"counterpartworkday" bson:"counterpartworkday"`
+	Status                string                `json:"status" bson:"status"`
+	AcceptedDate          time.Time             `json:"accepteddate,omitempty" bson:"accepteddate,omitempty"`
+	ApprovedBy            string                `json:"approvedby,omitempty" bson:"approvedby,omitempty"`
+	ApprovalDate          time.Time             `json:"approvaldate,omitempty" bson:"approvaldate,omitempty"`
+	RequesterVariations   []uint                `json:"requestervariations,omitempty" bson:"requestervariations,omitempty"`
+	CounterpartVariations []uint                `json:"counterpartvariations,omitempty" bson:"counterpartvariations,omitempty"`
+	Comments              []LeaveRequestComment `json:"comments,omitempty" bson:"comments,omitempty"`
+}
+
+type ByShiftSwap []ShiftSwap
+
+func (c ByShiftSwap) Len() int { return len(c) }
+func (c ByShiftSwap) Less(i, j int) bool {
+	if c[i].RequesterDate.Equal(c[j].RequesterDate) {
+		return c[i].RequestDate.Before(c[j].RequestDate)
+	}
+	return c[i].RequesterDate.Before(c[j].RequesterDate)
+}
+func (c ByShiftSwap) Swap(i, j int) { c[i], c[j] = c[j], c[i] }
+
+// IsFinished tells if the swap can't change any more.
+func (s *ShiftSwap) IsFinished() bool {
+	return s.Status == SwapDeclined || s.Status == SwapRejected ||
+		s.Status == SwapCancelled
+}
+
+func (s *ShiftSwap) AddComment(comment string) {
+	if comment != "" {
+		s.Comments = append(s.Comments, LeaveRequestComment{
+			CommentDate: time.Now().UTC(),
+			Comment:     comment,
+		})
+	}
+}
+
+// Accept records the counterpart's acceptance, or with accept false, that the
+// counterpart declined.
+func (s *ShiftSwap) Accept(empID primitive.ObjectID, accept bool,
+	comment string) error {
+	if empID != s.CounterpartID {
+		return errors.New("only the counterpart can accept the swap")
+	}
+	if s.Status != SwapRequested {
+		return errors.New("swap isn't waiting for the counterpart")
+	}
+	s.Status = SwapDeclined
+	if accept {
+		s.Status = SwapAccepted
+		s.AcceptedDate = time.Now().UTC()
+	}
+	s.AddComment(comment)
+	return nil
+}
+
+// Approve records the supervisor's approval, or with approve false, the
+// rejection.  The variations are added by the caller.
+func (s *ShiftSwap) Approve(approver string, approve bool,
+	comment string) error {
+	if s.Status != SwapAccepted {
+		return errors.New("swap isn't accepted by both employees")
+	}
+	s.Status = SwapRejected
+	if approve {
+		s.Status = SwapApproved
+	}
+	s.ApprovedBy = approver
+	s.ApprovalDate = time.Now().UTC()
+	s.AddComment(comment)
+	return nil
+}
+
+// Cancel cancels the swap.  The variations of an approved swap are removed by
+// the caller.
+func (s *ShiftSwap) Cancel(comment string) error {
+	if s.IsFinished() {
+		return errors.New("swap is already " + s.Status)
+	}
+	s.Status = SwapCancelled
+	s.AddComment(comment)
+	return nil
+}
+
+// SwapChanges provides the workdays an employee works (or, with no code,
+// doesn't) because of the swap.  The requester is off on the requester's date
+// and works the counterpart's shift on the counterpart's date, and the
+// counterpart the other way around.
+func (s *ShiftSwap) SwapChanges(empID primitive.ObjectID) map[time.Time]Workday {
+	answer := make(map[time.Time]Workday)
+	if empID == s.RequesterID {
+		answer[s.RequesterDate] = Workday{}
+		answer[s.CounterpartDate] = s.CounterpartWorkday
+	} else if empID == s.CounterpartID {
+		answer[s.CounterpartDate] = Workday{}
+		answer[s.RequesterDate] = s.RequesterWorkday
+	}
+	return answer
+}
+
+// AddDayVariation adds a variation for the single date, with the employee's
+// usual workdays for the rest of the week and the workday given for the
+// date, providing the variation's id.
+func (e *Employee) AddDayVariation(date time.Time, wd Workday) uint {
+	date = time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0,
+		time.UTC)
+	id := uint(0)
+	for _, vari := range e.Variations {
+		if vari.ID > id {
+			id = vari.ID
+		}
+	}
+	id++
+
+	vari := Variation{
+		ID:        id,
+		StartDate: date,
+		EndDate:   date,
+	}
+	start := scheduleStart(date)
+	for i := 0; i < 7; i++ {
+		day := Workday{
+			ID: uint(i),
+		}
+		site, layers := e.scheduleLayers(start.AddDate(0, 0, i))
+		if len(layers) > 0 {
+			top := layers[len(layers)-1]
+			day.Workcenter = top.Workcenter
+			day.Code = top.Code
+			day.Hours = top.Hours
+		}
+		if vari.Site == "" {
+			vari.Site = site
+		}
+		vari.Schedule.Workdays = append(vari.Schedule.Workdays, day)
+	}
+	wd.ID = uint(date.Weekday())
+	vari.Schedule.Workdays[wd.ID] = wd
+	e.Variations = append(e.Variations, vari)
+	return id
+}
+
+// RemoveVariations removes the variations with the ids given.
+func (e *Employee) RemoveVariations(ids []uint) {
+	for i := len(e.Variations) - 1; i >= 0; i-- {
+		for _, id := range ids {
+			if e.Variations[i].ID == id {
+				e.Variations = append(e.Variations[:i], e.Variations[i+1:]...)
+				break
+			}
+		}
+	}
+}
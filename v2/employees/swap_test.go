@@ -0,0 +1,114 @@
+package employees
+
+import (
+	"testing"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestShiftSwapStatus(t *testing.T) {
+	counterpart := primitive.NewObjectID()
+	tests := []struct {
+		name    string
+		status  string
+		action  func(s *ShiftSwap) error
+		wantErr bool
+		want    string
+	}{
+		{
+			name:   "accept",
+			status: SwapRequested,
+			action: func(s *ShiftSwap) error {
+				return s.Accept(counterpart, true, "ok")
+			},
+			want: SwapAccepted,
+		},
+		{
+			name:   "decline",
+			status: SwapRequested,
+			action: func(s *ShiftSwap) error {
+				return s.Accept(counterpart, false, "")
+			},
+			want: SwapDeclined,
+		},
+		{
+			name:   "accept by someone else",
+			status: SwapRequested,
+			action: func(s *ShiftSwap) error {
+				return s.Accept(primitive.NewObjectID(), true, "")
+			},
+			wantErr: true,
+		},
+		{
+			name:   "accept twice",
+			status: SwapAccepted,
+			action: func(s *ShiftSwap) error {
+				return s.Accept(counterpart, true, "")
+			},
+			wantErr: true,
+		},
+		{
+			name:   "approve",
+			status: SwapAccepted,
+			action: func(s *ShiftSwap) error {
+				return s.Approve("boss", true, "")
+			},
+			want: SwapApproved,
+		},
+		{
+			name:   "reject",
+			status: SwapAccepted,
+			action: func(s *ShiftSwap) error {
+				return s.Approve("boss", false, "")
+			},
+			want: SwapRejected,
+		},
+		{
+			name:   "approve before acceptance",
+			status: SwapRequested,
+			action: func(s *ShiftSwap) error {
+				return s.Approve("boss", true, "")
+			},
+			wantErr: true,
+		},
+		{
+			name:   "cancel approved",
+			status: SwapApproved,
+			action: func(s *ShiftSwap) error {
+				return s.Cancel("changed plans")
+			},
+			want: SwapCancelled,
+		},
+		{
+			name:   "cancel declined",
+			status: SwapDeclined,
+			action: func(s *ShiftSwap) error {
+				return s.Cancel("")
+			},
+			wantErr: true,
+		},
+		{
+			name:   "cancel twice",
+			status: SwapCancelled,
+			action: func(s *ShiftSwap) error {
+				return s.Cancel("")
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			swap := ShiftSwap{CounterpartID: counterpart, Status: tt.status}
+			err := tt.action(&swap)
+			if tt.wantErr {
+				if err == nil || swap.Status != tt.status {
+					t.Errorf("error = %v, status %s", err, swap.Status)
+				}
+				return
+			}
+			if err != nil || swap.Status != tt.want {
+				t.Errorf("error = %v, status %s, want %s", err, swap.Status, tt.want)
+			}
+		})
+	}
+}
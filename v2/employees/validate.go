@@ -0,0 +1,206 @@
+package employees
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// ErrInvalidEmployee is matched (with errors.Is) by ValidationProblems.
+var ErrInvalidEmployee = errors.New("employee isn't valid")
+
+// The kinds of problems Validate finds.
+const (
+	ProblemDates          = "dates"
+	ProblemOverlap        = "overlap"
+	ProblemGap            = "gap"
+	ProblemUncovered      = "uncovered"
+	ProblemNoSchedule     = "noschedule"
+	ProblemScheduleLength = "schedulelength"
+	ProblemRotation       = "rotation"
+)
+
+// ValidationProblem is a single problem with the employee's data.  The path
+// addresses the field like the audit entries do (assignments[id=2].enddate),
+// and a warning is a problem that doesn't make the data unusable.
+type ValidationProblem struct {
+	Path    string `json:"path"`
+	Kind    string `json:"kind"`
+	Message string `json:"message"`
+	Warning bool   `json:"warning,omitempty"`
+}
+
+type ValidationProblems []ValidationProblem
+
+func (vp ValidationProblems) Error() string {
+	var msgs []string
+	for _, p := range vp {
+		msgs = append(msgs, p.Path+": "+p.Message)
+	}
+	return ErrInvalidEmployee.Error() + ": " + strings.Join(msgs, "; ")
+}
+
+func (vp ValidationProblems) Is(target error) bool {
+	return target == ErrInvalidEmployee
+}
+
+// Errors provides the problems that aren't warnings.
+func (vp ValidationProblems) Errors() ValidationProblems {
+	var answer ValidationProblems
+	for _, p := range vp {
+		if !p.Warning {
+			answer = append(answer, p)
+		}
+	}
+	return answer
+}
+
+// Validate checks the employee's assignments and variations, providing the
+// problems found:  assignments that overlap (an error) or leave gaps between
+// them (a warning), variations not covered by the assignments, schedules
+// whose length isn't a multiple of seven days, and rotation days that aren't
+// whole weeks (or, as a warning, aren't set for rotating schedules).
+func (e *Employee) Validate() ValidationProblems {
+	var answer ValidationProblems
+	asgmts := make([]Assignment, len(e.Assignments))
+	copy(asgmts, e.Assignments)
+	sort.Sort(ByAssignment(asgmts))
+
+	for i, asgmt := range asgmts {
+		path := fmt.Sprintf("assignments[id=%d]", asgmt.ID)
+		if asgmt.EndDate.Before(asgmt.StartDate) {
+			answer = append(answer, ValidationProblem{
+				Path:    path + ".endDate",
+				Kind:    ProblemDates,
+				Message: "ends before it starts",
+			})
+		}
+		if i > 0 {
+			prev := asgmts[i-1]
+			if !asgmt.StartDate.After(prev.EndDate) {
+				answer = append(answer, ValidationProblem{
+					Path: path + ".startDate",
+					Kind: ProblemOverlap,
+					Message: fmt.Sprintf("overlaps assignment %d, which ends %s",
+						prev.ID, prev.EndDate.Format("2006-01-02")),
+				})
+			} else if asgmt.StartDate.After(prev.EndDate.AddDate(0, 0, 1)) {
+				answer = append(answer, ValidationProblem{
+					Path: path + ".startDate",
+					Kind: ProblemGap,
+					Message: fmt.Sprintf("doesn't start the day after assignment %d"+
+						" ends (%s)", prev.ID, prev.EndDate.Format("2006-01-02")),
+					Warning: true,
+				})
+			}
+		}
+		answer = append(answer, asgmt.validateSchedules(path)...)
+	}
+
+	for _, vari := range e.Variations {
+		path := fmt.Sprintf("variations[id=%d]", vari.ID)
+		if vari.EndDate.Before(vari.StartDate) {
+			answer = append(answer, ValidationProblem{
+				Path:    path + ".enddate",
+				Kind:    ProblemDates,
+				Message: "ends before it starts",
+			})
+			continue
+		}
+		if date, ok := coveredBy(asgmts, vari.StartDate, vari.EndDate); !ok {
+			answer = append(answer, ValidationProblem{
+				Path: path,
+				Kind: ProblemUncovered,
+				Message: fmt.Sprintf("no assignment covers %s",
+					date.Format("2006-01-02")),
+			})
+		}
+		if len(vari.Schedule.Workdays) == 0 ||
+			len(vari.Schedule.Workdays)%7 != 0 {
+			answer = append(answer, ValidationProblem{
+				Path: path + ".schedule.workdays",
+				Kind: ProblemScheduleLength,
+				Message: fmt.Sprintf("has %d days, not a multiple of seven",
+					len(vari.Schedule.Workdays)),
+			})
+		}
+	}
+	return answer
+}
+
+func (a *Assignment) validateSchedules(path string) ValidationProblems {
+	var answer ValidationProblems
+	if len(a.Schedules) == 0 {
+		return append(answer, ValidationProblem{
+			Path:    path + ".schedules",
+			Kind:    ProblemNoSchedule,
+			Message: "has no schedule",
+		})
+	}
+	for _, sch := range a.Schedules {
+		if len(sch.Workdays) == 0 || len(sch.Workdays)%7 != 0 {
+			answer = append(answer, ValidationProblem{
+				Path: fmt.Sprintf("%s.schedules[id=%d].workdays", path, sch.ID),
+				Kind: ProblemScheduleLength,
+				Message: fmt.Sprintf("has %d days, not a multiple of seven",
+					len(sch.Workdays)),
+			})
+		}
+	}
+	if len(a.Schedules) == 1 {
+		if a.RotationDays > 0 {
+			answer = append(answer, ValidationProblem{
+				Path:    path + ".rotationdays",
+				Kind:    ProblemRotation,
+				Message: "rotation days aren't used with a single schedule",
+				Warning: true,
+			})
+		}
+		return answer
+	}
+	if a.RotationDays <= 0 {
+		// GetWorkday uses only the first schedule until rotation days are set,
+		// so an assignment whose schedules were just added or removed is still
+		// usable.
+		return append(answer, ValidationProblem{
+			Path: path + ".rotationdays",
+			Kind: ProblemRotation,
+			Message: fmt.Sprintf("no rotation days for %d schedules, only the"+
+				" first is used", len(a.Schedules)),
+			Warning: true,
+		})
+	}
+	if a.RotationDays%7 != 0 {
+		return append(answer, ValidationProblem{
+			Path: path + ".rotationdays",
+			Kind: ProblemRotation,
+			Message: fmt.Sprintf("%d rotation days for %d schedules, must be a"+
+				" multiple of seven", a.RotationDays, len(a.Schedules)),
+		})
+	}
+	return answer
+}
+
+// coveredBy checks that the sorted assignments cover every day from start
+// through end, or provides the first day not covered.
+func coveredBy(asgmts []Assignment, start, end time.Time) (time.Time, bool) {
+	date := time.Date(start.Year(), start.Month(), start.Day(), 0, 0, 0, 0,
+		time.UTC)
+	for !date.After(end) {
+		found := false
+		for _, asgmt := range asgmts {
+			if !asgmt.StartDate.After(date) && !asgmt.EndDate.Before(date) {
+				date = time.Date(asgmt.EndDate.Year(), asgmt.EndDate.Month(),
+					asgmt.EndDate.Day(), 0, 0, 0, 0, time.UTC).AddDate(0, 0, 1)
+				found = true
+				break
+			}
+		}
+		if !found {
+			return date, false
+		}
+	}
+	return date, true
+}
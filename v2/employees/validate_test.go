@@ -0,0 +1,160 @@
+package employees
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestEmployeeValidate(t *testing.T) {
+	start := time.Date(2020, time.January, 1, 0, 0, 0, 0, time.UTC)
+	// base is an employee with a single assignment from the start on.
+	base := func() Employee {
+		emp := Employee{}
+		emp.AddAssignment("s", "w", start)
+		return emp
+	}
+	type problem struct {
+		path    string
+		kind    string
+		warning bool
+	}
+	tests := []struct {
+		name   string
+		change func(e *Employee)
+		want   []problem
+	}{
+		{
+			name:   "valid",
+			change: func(e *Employee) {},
+		},
+		{
+			name: "ends before it starts",
+			change: func(e *Employee) {
+				e.Assignments[0].EndDate = start.AddDate(0, 0, -1)
+			},
+			want: []problem{{"assignments[id=1].endDate", ProblemDates, false}},
+		},
+		{
+			name: "overlap",
+			change: func(e *Employee) {
+				e.AddAssignment("s", "w", start.AddDate(1, 0, 0))
+				e.Assignments[0].EndDate = start.AddDate(1, 0, 0)
+			},
+			want: []problem{{"assignments[id=2].startDate", ProblemOverlap,
+				false}},
+		},
+		{
+			name: "gap",
+			change: func(e *Employee) {
+				e.AddAssignment("s", "w", start.AddDate(1, 0, 0))
+				e.Assignments[0].EndDate = start.AddDate(0, 6, 0)
+			},
+			want: []problem{{"assignments[id=2].startDate", ProblemGap, true}},
+		},
+		{
+			name: "no schedule",
+			change: func(e *Employee) {
+				e.Assignments[0].Schedules = nil
+			},
+			want: []problem{{"assignments[id=1].schedules", ProblemNoSchedule,
+				false}},
+		},
+		{
+			name: "schedule length",
+			change: func(e *Employee) {
+				e.Assignments[0].ChangeScheduleDays(0, 10)
+			},
+			want: []problem{{"assignments[id=1].schedules[id=0].workdays",
+				ProblemScheduleLength, false}},
+		},
+		{
+			name: "rotation days with a single schedule",
+			change: func(e *Employee) {
+				e.Assignments[0].RotationDays = 14
+			},
+			want: []problem{{"assignments[id=1].rotationdays", ProblemRotation,
+				true}},
+		},
+		{
+			name: "rotating schedules",
+			change: func(e *Employee) {
+				e.Assignments[0].AddSchedule(7)
+				e.Assignments[0].RotationDays = 14
+			},
+		},
+		{
+			name: "schedule added without rotation days",
+			change: func(e *Employee) {
+				e.Assignments[0].AddSchedule(7)
+			},
+			want: []problem{{"assignments[id=1].rotationdays", ProblemRotation,
+				true}},
+		},
+		{
+			name: "rotation days not a multiple of the schedule length",
+			change: func(e *Employee) {
+				e.Assignments[0].AddSchedule(14)
+				e.Assignments[0].RotationDays = 21
+			},
+		},
+		{
+			name: "rotation days not whole weeks",
+			change: func(e *Employee) {
+				e.Assignments[0].AddSchedule(7)
+				e.Assignments[0].RotationDays = 10
+			},
+			want: []problem{{"assignments[id=1].rotationdays", ProblemRotation,
+				false}},
+		},
+		{
+			name: "variation not covered",
+			change: func(e *Employee) {
+				e.Variations = append(e.Variations, Variation{
+					ID:        1,
+					StartDate: start.AddDate(0, 0, -2),
+					EndDate:   start.AddDate(0, 0, 2),
+					Schedule:  e.Assignments[0].Schedules[0],
+				})
+			},
+			want: []problem{{"variations[id=1]", ProblemUncovered, false}},
+		},
+		{
+			name: "variation schedule length",
+			change: func(e *Employee) {
+				e.Variations = append(e.Variations, Variation{
+					ID:        1,
+					StartDate: start.AddDate(0, 0, 2),
+					EndDate:   start.AddDate(0, 0, 4),
+				})
+			},
+			want: []problem{{"variations[id=1].schedule.workdays",
+				ProblemScheduleLength, false}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			emp := base()
+			tt.change(&emp)
+			got := emp.Validate()
+			if len(got) != len(tt.want) {
+				t.Fatalf("problems = %+v, want %+v", got, tt.want)
+			}
+			for i, want := range tt.want {
+				if got[i].Path != want.path || got[i].Kind != want.kind ||
+					got[i].Warning != want.warning || got[i].Message == "" {
+					t.Errorf("problem %d = %+v, want %+v", i, got[i], want)
+				}
+			}
+			errs := got.Errors()
+			for _, p := range errs {
+				if p.Warning {
+					t.Errorf("warning in errors: %+v", p)
+				}
+			}
+			if len(errs) > 0 && !errors.Is(errs, ErrInvalidEmployee) {
+				t.Error("problems aren't an invalid employee error")
+			}
+		})
+	}
+}
@@ -0,0 +1,16 @@
+package general
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// ArchiveRecord keeps a copy of a document removed (or trimmed) by the
+// retention policies, with the collection it came from.
+type ArchiveRecord struct {
+	ID          primitive.ObjectID `json:"id" bson:"_id"`
+	Collection  string             `json:"collection" bson:"collection"`
+	ArchiveDate time.Time          `json:"archivedate" bson:"archivedate"`
+	Document    interface{}        `json:"document" bson:"document"`
+}
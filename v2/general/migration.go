@@ -0,0 +1,20 @@
+package general
+
+import "time"
+
+// MigrationRecord records a schema migration applied to the stored documents,
+// keyed by the migration's version.
+type MigrationRecord struct {
+	Version     int       `json:"version" bson:"_id"`
+	Name        string    `json:"name" bson:"name"`
+	AppliedDate time.Time `json:"applied" bson:"applied"`
+	Documents   int64     `json:"documents" bson:"documents"`
+}
+
+type ByMigrationRecord []MigrationRecord
+
+func (c ByMigrationRecord) Len() int { return len(c) }
+func (c ByMigrationRecord) Less(i, j int) bool {
+	return c[i].Version < c[j].Version
+}
+func (c ByMigrationRecord) Swap(i, j int) { c[i], c[j] = c[j], c[i] }
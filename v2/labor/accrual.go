@@ -0,0 +1,113 @@
+package labor
+
+import "time"
+
+// The accrual frequencies.  Annual accrual gives the year's hours on the
+// first of the year (or the day the employee becomes eligible, prorated),
+// the others a share of the year's hours at the end of each month or pay
+// period.
+const (
+	AccrueAnnually = "annually"
+	AccrueMonthly  = "monthly"
+	AccrueBiweekly = "biweekly"
+	AccrueWeekly   = "weekly"
+)
+
+// AccrualRate is the hours accrued in a year by employees with at least the
+// years of service.
+type AccrualRate struct {
+	Years int     `json:"years" bson:"years"`
+	Hours float64 `json:"hours" bson:"hours"`
+}
+
+// AccrualPolicy is a company's rules for accruing a leave code.
+type AccrualPolicy struct {
+	Code      string        `json:"code" bson:"code"`
+	Rates     []AccrualRate `json:"rates" bson:"rates"`
+	Frequency string        `json:"frequency" bson:"frequency"`
+	// PayPeriodStart is the first day of any pay period, for biweekly and
+	// weekly accrual.
+	PayPeriodStart time.Time `json:"payPeriodStart,omitempty" bson:"payPeriodStart,omitempty"`
+	// CarryoverLimit caps the hours carried into the next year, zero being no
+	// cap.  With UseOrLose nothing is carried.
+	CarryoverLimit float64 `json:"carryoverLimit,omitempty" bson:"carryoverLimit,omitempty"`
+	UseOrLose      bool    `json:"useOrLose,omitempty" bson:"useOrLose,omitempty"`
+	// MaxBalance stops accruing while the available hours are at it, zero
+	// being no maximum.
+	MaxBalance float64 `json:"maxBalance,omitempty" bson:"maxBalance,omitempty"`
+	// WaitingDays is the days of service before an employee starts accruing.
+	WaitingDays int `json:"waitingDays,omitempty" bson:"waitingDays,omitempty"`
+}
+
+// DefaultAccrualPolicy is the policy for a company without its own:  120
+// hours of vacation ("V") a year, given at the start of the year, with
+// everything left carried over.
+func DefaultAccrualPolicy() AccrualPolicy {
+	return AccrualPolicy{
+		Code: "V",
+		Rates: []AccrualRate{
+			{Years: 0, Hours: 120.0},
+		},
+		Frequency: AccrueAnnually,
+	}
+}
+
+// GetAnnualHours provides the hours accrued in a year with the years of
+// service, from the rate for the most years not above them.
+func (p *AccrualPolicy) GetAnnualHours(years int) float64 {
+	answer := 0.0
+	best := -1
+	for _, rate := range p.Rates {
+		if rate.Years <= years && rate.Years > best {
+			best = rate.Years
+			answer = rate.Hours
+		}
+	}
+	return answer
+}
+
+// GetCarryover provides the hours carried into the next year of those left.
+func (p *AccrualPolicy) GetCarryover(left float64) float64 {
+	if p.UseOrLose || left < 0.0 {
+		return 0.0
+	}
+	if p.CarryoverLimit > 0.0 && left > p.CarryoverLimit {
+		return p.CarryoverLimit
+	}
+	return left
+}
+
+// GetPeriodEnds provides the last day of each accrual period ending in the
+// year, with the share of the year's hours each gets (so a year with 27
+// biweekly periods accrues a little more).  Annual accrual is a single period
+// ending (and given) on the first of the year.
+func (p *AccrualPolicy) GetPeriodEnds(year int) ([]time.Time, float64) {
+	var answer []time.Time
+	start := time.Date(year, 1, 1, 0, 0, 0, 0, time.UTC)
+	next := start.AddDate(1, 0, 0)
+	switch p.Frequency {
+	case AccrueMonthly:
+		for m := 1; m <= 12; m++ {
+			answer = append(answer, start.AddDate(0, m, -1))
+		}
+		return answer, 1.0 / 12.0
+	case AccrueBiweekly, AccrueWeekly:
+		days, periods := 7, 52.0
+		if p.Frequency == AccrueBiweekly {
+			days, periods = 14, 26.0
+		}
+		anchor := time.Date(p.PayPeriodStart.Year(), p.PayPeriodStart.Month(),
+			p.PayPeriodStart.Day(), 0, 0, 0, 0, time.UTC)
+		// the first period end on or after the start of the year.
+		into := int(start.Sub(anchor).Hours()/24) % days
+		if into < 0 {
+			into += days
+		}
+		end := start.AddDate(0, 0, days-1-into)
+		for ; end.Before(next); end = end.AddDate(0, 0, days) {
+			answer = append(answer, end)
+		}
+		return answer, 1.0 / periods
+	}
+	return append(answer, start), 1.0
+}
@@ -0,0 +1,75 @@
+package labor
+
+import "time"
+
+// OvertimeRules are a company's rules for turning the hours worked into
+// regular, overtime and premium hours.
+type OvertimeRules struct {
+	// WeeklyLimit is the straight time hours in a workweek, above which hours
+	// are overtime.  Zero is no weekly overtime.
+	WeeklyLimit float64 `json:"weeklyLimit" bson:"weeklyLimit"`
+	// DailyLimit is the straight time hours in a day, above which hours are
+	// overtime.  Zero is no daily overtime.
+	DailyLimit    float64      `json:"dailyLimit,omitempty" bson:"dailyLimit,omitempty"`
+	WorkweekStart time.Weekday `json:"workweekStart" bson:"workweekStart"`
+	// PayPeriodWeeks is the number of workweeks in a pay period, one when not
+	// given.  PayPeriodStart is the date of any pay period's start, for pay
+	// periods longer than a week.
+	PayPeriodWeeks int       `json:"payPeriodWeeks,omitempty" bson:"payPeriodWeeks,omitempty"`
+	PayPeriodStart time.Time `json:"payPeriodStart,omitempty" bson:"payPeriodStart,omitempty"`
+	// HolidayPremium makes the hours worked on a holiday premium hours, which
+	// don't count towards the overtime limits.
+	HolidayPremium bool `json:"holidayPremium,omitempty" bson:"holidayPremium,omitempty"`
+	// CompTime banks the overtime hours as compensatory time instead of paying
+	// them, earned at CompTimeRate (one and a half when not given) for each
+	// hour.
+	CompTime     bool    `json:"compTime,omitempty" bson:"compTime,omitempty"`
+	CompTimeRate float64 `json:"compTimeRate,omitempty" bson:"compTimeRate,omitempty"`
+}
+
+// DefaultOvertimeRules are the rules for a company without its own:  overtime
+// over 40 hours in a Sunday to Saturday workweek, paid weekly.
+func DefaultOvertimeRules() OvertimeRules {
+	return OvertimeRules{
+		WeeklyLimit:    40.0,
+		WorkweekStart:  time.Sunday,
+		PayPeriodWeeks: 1,
+		CompTimeRate:   1.5,
+	}
+}
+
+// GetCompTimeRate provides the compensatory time earned for an hour of
+// overtime.
+func (r *OvertimeRules) GetCompTimeRate() float64 {
+	if r.CompTimeRate <= 0.0 {
+		return 1.5
+	}
+	return r.CompTimeRate
+}
+
+// WorkweekOf provides the start of the workweek the date is in.
+func (r *OvertimeRules) WorkweekOf(date time.Time) time.Time {
+	date = time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0,
+		time.UTC)
+	for date.Weekday() != r.WorkweekStart {
+		date = date.AddDate(0, 0, -1)
+	}
+	return date
+}
+
+// PayPeriodOf provides the first and last dates of the pay period the date is
+// in.
+func (r *OvertimeRules) PayPeriodOf(date time.Time) (time.Time, time.Time) {
+	start := r.WorkweekOf(date)
+	weeks := r.PayPeriodWeeks
+	if weeks <= 1 {
+		return start, start.AddDate(0, 0, 6)
+	}
+	anchor := r.WorkweekOf(r.PayPeriodStart)
+	into := int(start.Sub(anchor).Hours()/24) / 7 % weeks
+	if into < 0 {
+		into += weeks
+	}
+	start = start.AddDate(0, 0, -7*into)
+	return start, start.AddDate(0, 0, 7*weeks-1)
+}
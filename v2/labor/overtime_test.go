@@ -0,0 +1,37 @@
+package labor
+
+import (
+	"testing"
+	"time"
+)
+
+func date(year int, month time.Month, day int) time.Time {
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+}
+
+func TestOvertimeRulesPayPeriodOf(t *testing.T) {
+	rules := DefaultOvertimeRules()
+	rules.PayPeriodWeeks = 2
+	rules.PayPeriodStart = date(2024, time.June, 2)
+	tests := []struct {
+		date  time.Time
+		start time.Time
+		end   time.Time
+	}{
+		{date(2024, time.June, 2), date(2024, time.June, 2),
+			date(2024, time.June, 15)},
+		{date(2024, time.June, 20), date(2024, time.June, 16),
+			date(2024, time.June, 29)},
+		{date(2024, time.May, 30), date(2024, time.May, 19),
+			date(2024, time.June, 1)},
+	}
+	for _, tt := range tests {
+		start, end := rules.PayPeriodOf(tt.date)
+		if !start.Equal(tt.start) || !end.Equal(tt.end) {
+			t.Errorf("PayPeriodOf(%s) = %s - %s, want %s - %s",
+				tt.date.Format("2006-01-02"), start.Format("2006-01-02"),
+				end.Format("2006-01-02"), tt.start.Format("2006-01-02"),
+				tt.end.Format("2006-01-02"))
+		}
+	}
+}
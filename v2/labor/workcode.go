@@ -1,5 +1,7 @@
 package labor
 
+import "time"
+
 type Workcode struct {
 	Id        string `json:"id" bson:"id"`
 	Title     string `json:"title" bson:"title"`
@@ -25,3 +27,15 @@ func (c ByWorkcode) Less(i, j int) bool {
 	return true
 }
 func (c ByWorkcode) Swap(i, j int) { c[i], c[j] = c[j], c[i] }
+
+// StartsAt provides the moment the workcode's shift starts on the calendar
+// date at the location.  StartTime is the hour of the day, local to the
+// location, so a shift starts at the same clock time through daylight saving
+// changes.  A nil location is UTC.
+func (w *Workcode) StartsAt(date time.Time, loc *time.Location) time.Time {
+	if loc == nil {
+		loc = time.UTC
+	}
+	return time.Date(date.Year(), date.Month(), date.Day(), int(w.StartTime),
+		0, 0, 0, loc)
+}
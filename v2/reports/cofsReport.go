@@ -3,6 +3,7 @@ package reports
 import (
 	"archive/zip"
 	"bytes"
+	"context"
 	"fmt"
 	"math"
 	"sort"
@@ -36,8 +37,15 @@ type ReportCofS struct {
 // and use that for the https response.
 // //////////////////////////////////////////////////////////
 func (cr *ReportCofS) Create() error {
+	return cr.CreateContext(context.Background())
+}
+
+// CreateContext builds the CofS report files within the context.
+func (cr *ReportCofS) CreateContext(ctx context.Context) error {
+	ctx, cancel := createContext(ctx)
+	defer cancel()
 	// First get the site based on teamid and siteid
-	site, err := svcs.GetSite(cr.TeamID, cr.SiteID)
+	site, err := svcs.GetSiteContext(ctx, cr.TeamID, cr.SiteID)
 	if err != nil {
 		return err
 	}
@@ -47,7 +55,7 @@ func (cr *ReportCofS) Create() error {
 	// the team
 	cr.Companies = make(map[string]teams.Company)
 	cr.LeaveCodes = make(map[string]labor.Workcode)
-	team, err := svcs.GetTeam(cr.TeamID)
+	team, err := svcs.GetTeamContext(ctx, cr.TeamID)
 	if err != nil {
 		return err
 	}
@@ -61,14 +69,12 @@ func (cr *ReportCofS) Create() error {
 	}
 
 	// get workrecords for employees
-	for e, emp := range cr.Site.Employees {
-		emp.Work = emp.Work[:0]
-		work, err := svcs.GetEmployeeWork(emp.ID.Hex(),
-			uint(cr.Date.Year()))
-		if err == nil {
-			emp.Work = append(emp.Work, work.Work...)
-			cr.Site.Employees[e] = emp
-		}
+	err = svcs.LoadEmployeeDetailsContext(ctx, cr.Site.Employees,
+		svcs.EmployeeOptions{
+			WorkYears: []uint{uint(cr.Date.Year())},
+		})
+	if err != nil {
+		return err
 	}
 
 	// set start date as first day of month and end date as
@@ -234,6 +240,19 @@ func (cr *ReportCofS) CreateEmployeeData(count, coCount int,
 						current.Format("02 January"))
 					cr.Remarks = append(cr.Remarks, remark)
 				}
+				// a day split by partial leave shows the hours worked, with the
+				// leave noted.
+				for _, lv := range emp.GetPartialLeaves(current) {
+					title := lv.Code
+					if wc, ok := cr.LeaveCodes[lv.Code]; ok && wc.Title != "" {
+						title = wc.Title
+					}
+					remark := fmt.Sprintf("%s: %s %s took %.1f hours of %s from "+
+						"%04d to %04d on %s.", strings.ToUpper(company),
+						emp.Name.FirstName, emp.Name.LastName, lv.Hours, title,
+						lv.StartTime, lv.EndTime, current.Format("02 January"))
+					cr.Remarks = append(cr.Remarks, remark)
+				}
 			} else if !bExercise {
 				wd := emp.GetWorkdayActual(current, labor)
 				if wd != nil && wd.Code != "" {
@@ -0,0 +1,22 @@
+package reports
+
+import (
+	"context"
+	"time"
+)
+
+// DefaultCreateTimeout limits how long a report build may run when the
+// context given to its CreateContext has no deadline.  Each report's
+// CreateContext stops building once its context is cancelled or times out,
+// and its Create uses context.Background(), so it gets this limit too.
+var DefaultCreateTimeout = 5 * time.Minute
+
+func createContext(ctx context.Context) (context.Context, context.CancelFunc) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	if _, ok := ctx.Deadline(); ok || DefaultCreateTimeout <= 0 {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeout(ctx, DefaultCreateTimeout)
+}
@@ -7,11 +7,10 @@ import (
 	"strings"
 	"time"
 
-	"github.com/erneap/models/v2/config"
 	"github.com/erneap/models/v2/metrics"
+	"github.com/erneap/models/v2/svcs"
 	"github.com/erneap/models/v2/systemdata"
 	"github.com/xuri/excelize/v2"
-	"go.mongodb.org/mongo-driver/bson"
 )
 
 type DrawSummary struct {
@@ -27,6 +26,13 @@ type DrawSummary struct {
 }
 
 func (ds *DrawSummary) Create() (*excelize.File, error) {
+	return ds.CreateContext(context.Background())
+}
+
+// CreateContext builds the draw summary workbook within the context.
+func (ds *DrawSummary) CreateContext(ctx context.Context) (*excelize.File, error) {
+	ctx, cancel := createContext(ctx)
+	defer cancel()
 
 	// create outage excel file
 	workbook := excelize.NewFile()
@@ -66,18 +72,12 @@ func (ds *DrawSummary) Create() (*excelize.File, error) {
 	sort.Sort(ByMissionDay(ds.Missions))
 
 	// get missions for the time period and fill them into the mission days
-	var tmissions []metrics.Mission
-	filter := bson.M{"missionDate": bson.M{"$gte": ds.StartDate, "$lte": ds.EndDate}}
-	cursor, err := config.GetCollection(config.DB, "metrics", "missions").Find(context.TODO(),
-		filter)
+	tmissions, err := svcs.GetMissionsBetweenDatesContext(ctx, ds.StartDate,
+		ds.EndDate)
 	if err != nil {
 		return nil, err
 	}
 
-	if err = cursor.All(context.TODO(), &tmissions); err != nil {
-		return nil, err
-	}
-
 	for _, msn := range tmissions {
 		for pos, mday := range ds.Missions {
 			if mday.MissionDate.Equal(msn.MissionDate) {
@@ -87,18 +87,12 @@ func (ds *DrawSummary) Create() (*excelize.File, error) {
 		}
 	}
 	// get outages for the time period and fill them into the outage days
-	var tOutages []metrics.GroundOutage
-	filter = bson.M{"outageDate": bson.M{"$gte": ds.StartDate, "$lte": ds.EndDate}}
-	cursor, err = config.GetCollection(config.DB, "metrics", "groundoutages").Find(context.TODO(),
-		filter)
+	tOutages, err := svcs.GetOutagesBetweenDatesContext(ctx, ds.StartDate,
+		ds.EndDate)
 	if err != nil {
 		return nil, err
 	}
 
-	if err = cursor.All(context.TODO(), &tOutages); err != nil {
-		return nil, err
-	}
-
 	for _, outage := range tOutages {
 		for pos, oday := range ds.Outages {
 			if oday.OutageDate.Equal(outage.OutageDate) && outage.Capability == "NMC" {
@@ -1,6 +1,7 @@
 package reports
 
 import (
+	"context"
 	"fmt"
 	"sort"
 	"strconv"
@@ -27,46 +28,55 @@ type EnterpriseSchedule struct {
 }
 
 func (sr *EnterpriseSchedule) Create() error {
+	return sr.CreateContext(context.Background())
+}
+
+// CreateContext builds the enterprise schedule within the context.
+func (sr *EnterpriseSchedule) CreateContext(ctx context.Context) error {
+	ctx, cancel := createContext(ctx)
+	defer cancel()
 	sr.Styles = make(map[string]int)
 	sr.Workcodes = make(map[string]labor.Workcode)
 	sr.Report = excelize.NewFile()
-	sr.Date = time.Now().UTC()
+	sr.Date = employees.SiteDate(time.Now(),
+		siteLocation(ctx, sr.TeamID, sr.SiteID))
 	sr.LastWorked = time.Date(1970, time.January, 1, 0, 0, 0, 0, time.UTC)
 
 	// get employees with assignments for the site that are assigned
 	// during the year.
 	startDate := time.Date(sr.Year, 1, 1, 0, 0, 0, 0, time.UTC)
 	endDate := time.Date(sr.Year, 12, 31, 23, 59, 59, 0, time.UTC)
-	emps, err := svcs.GetEmployeesForTeam(sr.TeamID)
+	emps, err := svcs.GetEmployeesForTeamContext(ctx, sr.TeamID)
 	if err != nil {
 		return err
 	}
 
 	for _, emp := range emps {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
 		if emp.AtSite(sr.SiteID, startDate, endDate) {
-			// get timecard data/work hours for each employee
-			// for time period.
-			wr, err := svcs.GetEmployeeWork(emp.ID.Hex(), uint(startDate.Year()))
-			if err == nil {
-				emp.Work = append(emp.Work, wr.Work...)
-				for _, wk := range wr.Work {
-					if wk.DateWorked.After(sr.LastWorked) {
-						sr.LastWorked = wk.DateWorked
-					}
-				}
-			}
-			if startDate.Year() != endDate.Year() {
-				wr, err = svcs.GetEmployeeWork(emp.ID.Hex(), uint(endDate.Year()))
-				if err == nil {
-					emp.Work = append(emp.Work, wr.Work...)
-				}
-			}
 			sr.Employees = append(sr.Employees, emp)
 		}
 	}
 
+	// get timecard data/work hours for each employee for time period.
+	err = svcs.LoadEmployeeDetailsContext(ctx, sr.Employees, svcs.EmployeeOptions{
+		WorkYears: svcs.WorkYears(startDate, endDate),
+	})
+	if err != nil {
+		return err
+	}
+	for _, emp := range sr.Employees {
+		for _, wk := range emp.Work {
+			if wk.DateWorked.After(sr.LastWorked) {
+				sr.LastWorked = wk.DateWorked
+			}
+		}
+	}
+
 	// get the team's workcodes
-	team, err := svcs.GetTeam(sr.TeamID)
+	team, err := svcs.GetTeamContext(ctx, sr.TeamID)
 	if err != nil {
 		return err
 	}
@@ -75,7 +85,7 @@ func (sr *EnterpriseSchedule) Create() error {
 	}
 
 	// get the site's workcenters
-	site, err := svcs.GetSite(sr.TeamID, sr.SiteID)
+	site, err := svcs.GetSiteContext(ctx, sr.TeamID, sr.SiteID)
 	if err != nil {
 		return err
 	}
@@ -83,7 +93,7 @@ func (sr *EnterpriseSchedule) Create() error {
 	sort.Sort(sites.ByWorkcenter(sr.Workcenters))
 
 	// create styles for display on each monthly sheet
-	err = sr.CreateStyles()
+	err = sr.createStyles(ctx)
 	if err != nil {
 		return err
 	}
@@ -112,11 +122,15 @@ func (sr *EnterpriseSchedule) Create() error {
 }
 
 func (sr *EnterpriseSchedule) CreateStyles() error {
+	return sr.createStyles(context.Background())
+}
+
+func (sr *EnterpriseSchedule) createStyles(ctx context.Context) error {
 	//get all the workcodes from the team object and create the
 	// styles for each one, plus one for weekend (non-leave), and
 	// even and odd non-leaves.  Also need style for month label and workcenter
 
-	team, err := svcs.GetTeam(sr.TeamID)
+	team, err := svcs.GetTeamContext(ctx, sr.TeamID)
 	if err != nil {
 		return err
 	}
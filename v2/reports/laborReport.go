@@ -1,6 +1,7 @@
 package reports
 
 import (
+	"context"
 	"math"
 	"sort"
 	"strconv"
@@ -29,10 +30,19 @@ type LaborReport struct {
 	StatsRow          int
 	CurrentAsOf       time.Time
 	EndWork           time.Time
-	Offset            float64
+	Location          *time.Location
+	OvertimeRules     labor.OvertimeRules
+	Holidays          []time.Time
 }
 
 func (lr *LaborReport) Create() error {
+	return lr.CreateContext(context.Background())
+}
+
+// CreateContext builds the labor report workbook within the context.
+func (lr *LaborReport) CreateContext(ctx context.Context) error {
+	ctx, cancel := createContext(ctx)
+	defer cancel()
 	lr.CurrentAsOf = time.Now().UTC()
 	lr.EndWork = time.Date(1970, time.January, 1, 0, 0, 0, 0, time.UTC)
 	lr.StatsRow = 3
@@ -46,7 +56,7 @@ func (lr *LaborReport) Create() error {
 		lr.Date.Day(), 0, 0, 0, 0, time.UTC)
 	maxDate := time.Date(lr.Date.Year(), lr.Date.Month(),
 		lr.Date.Day(), 0, 0, 0, 0, time.UTC)
-	site, err := svcs.GetSite(lr.TeamID, lr.SiteID)
+	site, err := svcs.GetSiteContext(ctx, lr.TeamID, lr.SiteID)
 	if err != nil {
 		return err
 	}
@@ -72,54 +82,56 @@ func (lr *LaborReport) Create() error {
 	}
 
 	// Get the team's workcodes
-	team, err := svcs.GetTeam(lr.TeamID)
+	team, err := svcs.GetTeamContext(ctx, lr.TeamID)
 	if err != nil {
 		return err
 	}
 	for _, wc := range team.Workcodes {
 		lr.Workcodes[wc.Id] = wc
 	}
-	for _, site := range team.Sites {
-		if strings.EqualFold(site.ID, lr.SiteID) {
-			lr.Offset = 0
+	lr.Location = site.GetLocation()
+	lr.CurrentAsOf = lr.CurrentAsOf.In(lr.Location)
+	lr.OvertimeRules = labor.DefaultOvertimeRules()
+	for _, co := range team.Companies {
+		if strings.EqualFold(co.ID, lr.CompanyID) {
+			lr.OvertimeRules = co.GetOvertimeRules()
+			first, _ := lr.OvertimeRules.PayPeriodOf(minDate)
+			lr.Holidays = co.GetHolidayDatesBetween(first, maxDate)
 		}
 	}
 
 	// get employees with assignments for the site that are assigned
 	// during the forecast period.
 
-	emps, err := svcs.GetEmployeesForTeam(lr.TeamID)
+	emps, err := svcs.GetEmployeesForTeamContext(ctx, lr.TeamID)
 	if err != nil {
 		return err
 	}
 	for _, emp := range emps {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
 		if emp.AtSite(lr.SiteID, minDate, maxDate) {
-			// get work records for the year inclusive of the two
-			// dates
-			work, _ := svcs.GetEmployeeWork(emp.ID.Hex(),
-				uint(minDate.Year()))
-			if work != nil {
-				emp.Work = append(emp.Work, work.Work...)
-			}
-			if minDate.Year() != maxDate.Year() {
-				year := minDate.Year()
-				for year < maxDate.Year() {
-					year++
-					work, _ = svcs.GetEmployeeWork(emp.ID.Hex(),
-						uint(year))
-					if work != nil {
-						emp.Work = append(emp.Work, work.Work...)
-					}
-				}
-			}
-			if emp.GetLastWorkday().After(lr.EndWork) {
-				lr.EndWork = emp.GetLastWorkday()
-			}
 			lr.Employees = append(lr.Employees, emp)
 		}
 		lr.Report.UpdateLinkedValue()
 	}
 
+	// get work records for the years inclusive of the two dates, and the
+	// start of the pay period the first is in for the overtime.
+	firstPay, _ := lr.OvertimeRules.PayPeriodOf(minDate)
+	err = svcs.LoadEmployeeDetailsContext(ctx, lr.Employees, svcs.EmployeeOptions{
+		WorkYears: svcs.WorkYears(firstPay, maxDate),
+	})
+	if err != nil {
+		return err
+	}
+	for _, emp := range lr.Employees {
+		if emp.GetLastWorkday().After(lr.EndWork) {
+			lr.EndWork = emp.GetLastWorkday()
+		}
+	}
+
 	//////////////////////////////////////////////////////////
 	// Report Creation
 	//////////////////////////////////////////////////////////
@@ -127,6 +139,8 @@ func (lr *LaborReport) Create() error {
 
 	lr.CreateStatisticsReport()
 
+	lr.CreateOvertimeReport(minDate, maxDate)
+
 	sort.Sort(sites.ByForecastReport(lr.ForecastReports))
 
 	for _, fr := range lr.ForecastReports {
@@ -1045,7 +1059,7 @@ func (lr *LaborReport) CreateContractReport(
 				fr.EndDate.AddDate(0, 0, 1))
 			forecast := emp.GetForecastHours(lCode, fr.StartDate,
 				fr.EndDate.AddDate(0, 0, 1),
-				compareCodes, lr.Offset)
+				compareCodes, 0.0)
 			if actual > 0.0 || forecast > 0.0 {
 				// show employee for this labor code, if either actual or forecast > 0
 				row++
@@ -1118,7 +1132,7 @@ func (lr *LaborReport) CreateContractReport(
 							if last.AddDate(0, 0, -1).After(lastWorkday) {
 								style = lr.Styles["forecast"]
 							}
-							hours += emp.GetForecastHours(lCode, first, last, compareCodes, lr.Offset)
+							hours += emp.GetForecastHours(lCode, first, last, compareCodes, 0.0)
 						}
 						lr.Report.SetCellStyle(sheetName, cellID, cellID, style)
 						format := lr.ConditionalStyles["cellpink"]
@@ -1366,3 +1380,69 @@ func (lr *LaborReport) CreateStatisticsReport() error {
 
 	return nil
 }
+
+// CreateOvertimeReport lists each employee's regular, overtime, premium and
+// comp time hours by charge number for the pay periods from start through
+// the last work recorded (or end), by the company's overtime rules.
+func (lr *LaborReport) CreateOvertimeReport(start, end time.Time) error {
+	sheetName := "Overtime"
+	lr.Report.NewSheet(sheetName)
+	options := excelize.ViewOptions{}
+	options.ShowGridLines = &[]bool{false}[0]
+	lr.Report.SetSheetView(sheetName, 0, &options)
+	if lr.EndWork.Before(end) {
+		end = lr.EndWork
+	}
+
+	lr.Report.SetColWidth(sheetName, GetColumn(0), GetColumn(0), 30.0)
+	lr.Report.SetColWidth(sheetName, GetColumn(1), GetColumn(2), 12.0)
+	lr.Report.SetColWidth(sheetName, GetColumn(3), GetColumn(3), 20.0)
+	lr.Report.SetColWidth(sheetName, GetColumn(4), GetColumn(7), 12.0)
+
+	// Current as of header
+	style := lr.Styles["headerctr"]
+	lr.Report.MergeCell(sheetName, "A1", "H1")
+	lr.Report.SetCellStyle(sheetName, "A1", "A1", style)
+	lr.Report.SetCellValue(sheetName, "A1", "Current As Of "+
+		lr.CurrentAsOf.Format("02 Jan 2006"))
+	lr.Report.SetRowHeight(sheetName, 1, 20.0)
+
+	style = lr.Styles["whitelbl"]
+	lr.Report.SetCellStyle(sheetName, "A2", "H2", style)
+	for i, label := range []string{"Employee", "Period Start", "Period End",
+		"Contract No/Ext", "Regular", "Overtime", "Premium", "Comp Time"} {
+		lr.Report.SetCellValue(sheetName, GetCellID(i, 2), label)
+	}
+
+	row := 2
+	if end.Before(start) {
+		return nil
+	}
+	for _, emp := range lr.Employees {
+		periods := emp.ComputeOvertime(lr.OvertimeRules, lr.Holidays, start,
+			end)
+		for _, period := range periods {
+			for _, charge := range period.Charges {
+				row++
+				lr.Report.SetCellStyle(sheetName, GetCellID(0, row),
+					GetCellID(0, row), lr.Styles["peopleleft"])
+				lr.Report.SetCellValue(sheetName, GetCellID(0, row), period.Name)
+				lr.Report.SetCellStyle(sheetName, GetCellID(1, row),
+					GetCellID(3, row), lr.Styles["peoplectr"])
+				lr.Report.SetCellValue(sheetName, GetCellID(1, row),
+					period.StartDate.Format("02 Jan 06"))
+				lr.Report.SetCellValue(sheetName, GetCellID(2, row),
+					period.EndDate.Format("02 Jan 06"))
+				lr.Report.SetCellValue(sheetName, GetCellID(3, row),
+					charge.ChargeNumber+" "+charge.Extension)
+				lr.Report.SetCellStyle(sheetName, GetCellID(4, row),
+					GetCellID(7, row), lr.Styles["actual"])
+				lr.Report.SetCellValue(sheetName, GetCellID(4, row), charge.Regular)
+				lr.Report.SetCellValue(sheetName, GetCellID(5, row), charge.Overtime)
+				lr.Report.SetCellValue(sheetName, GetCellID(6, row), charge.Premium)
+				lr.Report.SetCellValue(sheetName, GetCellID(7, row), charge.CompTime)
+			}
+		}
+	}
+	return nil
+}
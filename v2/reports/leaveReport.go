@@ -1,6 +1,7 @@
 package reports
 
 import (
+	"context"
 	"fmt"
 	"sort"
 	"strconv"
@@ -123,10 +124,17 @@ type LeaveReport struct {
 	Workcodes map[string]labor.Workcode
 	Styles    map[string]int
 	Employees []employees.Employee
-	Offset    float64
+	Location  *time.Location
 }
 
 func (lr *LeaveReport) Create() error {
+	return lr.CreateContext(context.Background())
+}
+
+// CreateContext builds the leave report workbook within the context.
+func (lr *LeaveReport) CreateContext(ctx context.Context) error {
+	ctx, cancel := createContext(ctx)
+	defer cancel()
 	lr.Styles = make(map[string]int)
 	lr.Workcodes = make(map[string]labor.Workcode)
 	lr.Report = excelize.NewFile()
@@ -135,12 +143,15 @@ func (lr *LeaveReport) Create() error {
 	// during the year.
 	startDate := time.Date(lr.Year, 1, 1, 0, 0, 0, 0, time.UTC)
 	endDate := time.Date(lr.Year, 12, 31, 23, 59, 59, 0, time.UTC)
-	emps, err := svcs.GetEmployeesForTeam(lr.TeamID)
+	emps, err := svcs.GetEmployeesForTeamContext(ctx, lr.TeamID)
 	if err != nil {
 		return err
 	}
 
 	for _, emp := range emps {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
 		if emp.AtSite(lr.SiteID, startDate, endDate) {
 			if strings.EqualFold(emp.CompanyInfo.Company, lr.CompanyID) {
 				lr.Employees = append(lr.Employees, emp)
@@ -150,7 +161,7 @@ func (lr *LeaveReport) Create() error {
 
 	sort.Sort(employees.ByEmployees(lr.Employees))
 
-	team, err := svcs.GetTeam(lr.TeamID)
+	team, err := svcs.GetTeamContext(ctx, lr.TeamID)
 	if err != nil {
 		return err
 	}
@@ -162,6 +173,7 @@ func (lr *LeaveReport) Create() error {
 					ID:     hol.ID,
 					SortID: hol.SortID,
 					Name:   hol.Name,
+					Rule:   hol.Rule,
 				}
 				holiday.ActualDates = append(holiday.ActualDates, hol.ActualDates...)
 				h := LeaveMonth{
@@ -177,9 +189,10 @@ func (lr *LeaveReport) Create() error {
 			lr.Workcodes[wc.Id] = wc
 		}
 	}
+	lr.Location = time.UTC
 	for _, site := range team.Sites {
 		if strings.EqualFold(site.ID, lr.SiteID) {
-			lr.Offset = site.UtcOffset
+			lr.Location = site.GetLocation()
 		}
 	}
 
@@ -886,7 +899,7 @@ func (lr *LeaveReport) CreateLeaveListing() error {
 	lr.Report.MergeCell(sheetName, GetCellID(0, 1),
 		GetCellID(extendedWidth, 1))
 	lr.Report.SetCellValue(sheetName, GetCellID(0, 1),
-		"Current As Of: "+time.Now().Format("01/02/2006"))
+		"Current As Of: "+time.Now().In(lr.Location).Format("01/02/2006"))
 
 	// Freeze the first row
 	lr.Report.SetPanes(sheetName, &excelize.Panes{
@@ -928,7 +941,7 @@ func (lr *LeaveReport) CreateLeaveListing() error {
 		annual := 0.0
 		carry := 0.0
 		for _, bal := range emp.Balances {
-			if bal.Year == lr.Year {
+			if bal.Year == lr.Year && bal.IsCode("V") {
 				annual = bal.Annual
 				carry = bal.Carryover
 			}
@@ -1208,9 +1221,9 @@ func (lr *LeaveReport) CreateLeaveListing() error {
 
 		for _, lv := range empOtherLeave {
 			for m, month := range months {
+				// a leave date with a time of day is a moment from an older client
 				if lv.LeaveDate.Hour() != 0 {
-					delta := time.Hour * time.Duration(lr.Offset)
-					lv.LeaveDate = lv.LeaveDate.Add(delta)
+					lv.LeaveDate = employees.SiteDate(lv.LeaveDate, lr.Location)
 				}
 				if month.Month.Year() == lv.LeaveDate.Year() &&
 					month.Month.Month() == lv.LeaveDate.Month() {
@@ -1240,7 +1253,7 @@ func (lr *LeaveReport) CreateLeaveListing() error {
 				}
 			}
 		}
-		now := time.Now().UTC()
+		now := employees.SiteDate(time.Now(), lr.Location)
 		col = 0
 		var richText []excelize.RichTextRun
 		if lr.BHolidays {
@@ -1548,7 +1561,7 @@ func (lr *LeaveReport) CreateFullMonthlyReference() error {
 	lr.Report.SetCellStyle(sheetName, "A1", "D1", style)
 	lr.Report.MergeCell(sheetName, "A1", "D1")
 	lr.Report.SetCellValue(sheetName, "A1",
-		"Current As of: "+time.Now().Format("01/02/2006"))
+		"Current As of: "+time.Now().In(lr.Location).Format("01/02/2006"))
 
 	style = lr.Styles["header"]
 	lr.Report.SetCellStyle(sheetName, "E1", "AH1", style)
@@ -1634,7 +1647,7 @@ func (lr *LeaveReport) CreateMinumimMonthlyReference() error {
 	lr.Report.SetCellStyle(sheetName, "A1", "D1", style)
 	lr.Report.MergeCell(sheetName, "A1", "D1")
 	lr.Report.SetCellValue(sheetName, "A1",
-		"Current As of: "+time.Now().Format("01/02/2006"))
+		"Current As of: "+time.Now().In(lr.Location).Format("01/02/2006"))
 
 	style = lr.Styles["header"]
 	lr.Report.SetCellStyle(sheetName, "E1", "AH1", style)
@@ -0,0 +1,18 @@
+package reports
+
+import (
+	"context"
+	"time"
+
+	"github.com/erneap/models/v2/svcs"
+)
+
+// siteLocation provides the location of the site's time zone for the report's
+// dates, or UTC when the site can't be read.
+func siteLocation(ctx context.Context, teamid, siteid string) *time.Location {
+	loc, err := svcs.GetSiteLocationContext(ctx, teamid, siteid)
+	if err != nil {
+		return time.UTC
+	}
+	return loc
+}
@@ -1,6 +1,7 @@
 package reports
 
 import (
+	"context"
 	"sort"
 	"strconv"
 	"time"
@@ -70,11 +71,18 @@ type MidShiftReport struct {
 }
 
 func (m *MidShiftReport) Create() error {
-	m.CurrentAsOf = time.Now().UTC()
+	return m.CreateContext(context.Background())
+}
+
+// CreateContext builds the mid shift report within the context.
+func (m *MidShiftReport) CreateContext(ctx context.Context) error {
+	ctx, cancel := createContext(ctx)
+	defer cancel()
+	m.CurrentAsOf = time.Now().In(siteLocation(ctx, m.TeamID, m.SiteID))
 	m.Styles = make(map[string]int)
 	m.Report = excelize.NewFile()
 
-	site, err := svcs.GetSite(m.TeamID, m.SiteID)
+	site, err := svcs.GetSiteContext(ctx, m.TeamID, m.SiteID)
 	if err != nil {
 		return err
 	}
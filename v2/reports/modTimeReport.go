@@ -1,6 +1,7 @@
 package reports
 
 import (
+	"context"
 	"errors"
 	"sort"
 	"strings"
@@ -58,7 +59,15 @@ type ModTimeReport struct {
 }
 
 func (lr *ModTimeReport) Create() error {
-	lr.CurrentAsOf = time.Now().UTC()
+	return lr.CreateContext(context.Background())
+}
+
+// CreateContext builds the modified time report within the context.
+func (lr *ModTimeReport) CreateContext(ctx context.Context) error {
+	ctx, cancel := createContext(ctx)
+	defer cancel()
+	loc := siteLocation(ctx, lr.TeamID, lr.SiteID)
+	lr.CurrentAsOf = time.Now().In(loc)
 	lr.EndWork = time.Date(1970, time.January, 1, 0, 0, 0, 0, time.UTC)
 	lr.Styles = make(map[string]int)
 	lr.ConditionalStyles = make(map[string]int)
@@ -69,11 +78,11 @@ func (lr *ModTimeReport) Create() error {
 		lr.Date.Day(), 0, 0, 0, 0, time.UTC)
 	lr.MaxDate = time.Date(lr.Date.Year(), lr.Date.Month(),
 		lr.Date.Day(), 0, 0, 0, 0, time.UTC)
-	team, err := svcs.GetTeam(lr.TeamID)
+	team, err := svcs.GetTeamContext(ctx, lr.TeamID)
 	if err != nil {
 		return err
 	}
-	now := time.Now().UTC()
+	now := employees.SiteDate(time.Now(), loc)
 	found := false
 	for _, co := range team.Companies {
 		if strings.EqualFold(co.ID, lr.CompanyID) {
@@ -131,41 +140,38 @@ func (lr *ModTimeReport) Create() error {
 	// get employees with assignments for the site that are assigned
 	// during the mod period
 
-	emps, err := svcs.GetEmployeesForTeam(lr.TeamID)
+	emps, err := svcs.GetEmployeesForTeamContext(ctx, lr.TeamID)
 	if err != nil {
 		return err
 	}
+	var siteEmps []employees.Employee
 	for _, emp := range emps {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
 		if emp.AtSite(lr.SiteID, lr.MinDate, lr.MaxDate) &&
 			strings.EqualFold(emp.CompanyInfo.Company, lr.CompanyID) {
-			// get work records for the year inclusive of the two
-			// dates
-			work, _ := svcs.GetEmployeeWork(emp.ID.Hex(),
-				uint(lr.MinDate.Year()))
-			if work != nil {
-				emp.Work = append(emp.Work, work.Work...)
-			}
-			if lr.MinDate.Year() != lr.MaxDate.Year() {
-				year := lr.MinDate.Year()
-				for year < lr.MaxDate.Year() {
-					year++
-					work, _ = svcs.GetEmployeeWork(emp.ID.Hex(),
-						uint(year))
-					if work != nil {
-						emp.Work = append(emp.Work, work.Work...)
-					}
-				}
-			}
-			if emp.GetLastWorkday().After(lr.EndWork) {
-				lr.EndWork = emp.GetLastWorkday()
-			}
-			if emp.HasModTime(lr.MinDate, lr.MaxDate) {
-				lr.Employees = append(lr.Employees, emp)
-			}
+			siteEmps = append(siteEmps, emp)
 		}
 		lr.Report.UpdateLinkedValue()
 	}
 
+	// get work records for the years inclusive of the two dates
+	err = svcs.LoadEmployeeDetailsContext(ctx, siteEmps, svcs.EmployeeOptions{
+		WorkYears: svcs.WorkYears(lr.MinDate, lr.MaxDate),
+	})
+	if err != nil {
+		return err
+	}
+	for _, emp := range siteEmps {
+		if emp.GetLastWorkday().After(lr.EndWork) {
+			lr.EndWork = emp.GetLastWorkday()
+		}
+		if emp.HasModTime(lr.MinDate, lr.MaxDate) {
+			lr.Employees = append(lr.Employees, emp)
+		}
+	}
+
 	//////////////////////////////////////////////////////////
 	// Report Creation
 	//////////////////////////////////////////////////////////
@@ -8,11 +8,10 @@ import (
 	"strings"
 	"time"
 
-	"github.com/erneap/models/v2/config"
 	"github.com/erneap/models/v2/metrics"
+	"github.com/erneap/models/v2/svcs"
 	"github.com/erneap/models/v2/systemdata"
 	"github.com/xuri/excelize/v2"
-	"go.mongodb.org/mongo-driver/bson"
 )
 
 type MissionSummary struct {
@@ -28,6 +27,13 @@ type MissionSummary struct {
 }
 
 func (ms *MissionSummary) Create() (*excelize.File, error) {
+	return ms.CreateContext(context.Background())
+}
+
+// CreateContext builds the mission summary workbook within the context.
+func (ms *MissionSummary) CreateContext(ctx context.Context) (*excelize.File, error) {
+	ctx, cancel := createContext(ctx)
+	defer cancel()
 	// create outage excel file
 	workbook := excelize.NewFile()
 	ms.SystemInfo = metrics.InitialData()
@@ -51,36 +57,24 @@ func (ms *MissionSummary) Create() (*excelize.File, error) {
 	}
 
 	// collect all the missions for the period
-	var tmissions []metrics.Mission
-	filter := bson.M{"missionDate": bson.M{"$gte": ms.StartDate, "$lte": ms.EndDate}}
-	cursor, err := config.GetCollection(config.DB, "metrics2", "missions").Find(context.TODO(),
-		filter)
+	tmissions, err := svcs.GetMissionsBetweenDatesContext(ctx, ms.StartDate,
+		ms.EndDate)
 	if err != nil {
 		return nil, err
 	}
 
-	if err = cursor.All(context.TODO(), &tmissions); err != nil {
-		return nil, err
-	}
-
 	ms.Missions = append(ms.Missions, tmissions...)
 
 	sort.Sort(metrics.ByMission(ms.Missions))
 	log.Println(len(ms.Missions))
 
 	// collect all the outages for the period
-	var tOutages []metrics.GroundOutage
-	filter = bson.M{"outageDate": bson.M{"$gte": ms.StartDate, "$lte": ms.EndDate}}
-	cursor, err = config.GetCollection(config.DB, "metrics2", "outages").Find(context.TODO(),
-		filter)
+	tOutages, err := svcs.GetOutagesBetweenDatesContext(ctx, ms.StartDate,
+		ms.EndDate)
 	if err != nil {
 		return nil, err
 	}
 
-	if err = cursor.All(context.TODO(), &tOutages); err != nil {
-		return nil, err
-	}
-
 	ms.Outages = append(ms.Outages, tOutages...)
 
 	sort.Sort(metrics.ByOutage(ms.Outages))
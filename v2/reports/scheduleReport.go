@@ -1,6 +1,7 @@
 package reports
 
 import (
+	"context"
 	"sort"
 	"strconv"
 	"strings"
@@ -26,40 +27,47 @@ type ScheduleReport struct {
 }
 
 func (sr *ScheduleReport) Create() error {
+	return sr.CreateContext(context.Background())
+}
+
+// CreateContext builds the schedule workbook within the context.
+func (sr *ScheduleReport) CreateContext(ctx context.Context) error {
+	ctx, cancel := createContext(ctx)
+	defer cancel()
 	sr.Styles = make(map[string]int)
 	sr.Workcodes = make(map[string]bool)
 	sr.Report = excelize.NewFile()
-	sr.Date = time.Now().UTC()
+	sr.Date = employees.SiteDate(time.Now(),
+		siteLocation(ctx, sr.TeamID, sr.SiteID))
 
 	// get employees with assignments for the site that are assigned
 	// during the year.
 	startDate := time.Date(sr.Year, 1, 1, 0, 0, 0, 0, time.UTC)
 	endDate := time.Date(sr.Year, 12, 31, 23, 59, 59, 0, time.UTC)
-	emps, err := svcs.GetEmployeesForTeam(sr.TeamID)
+	emps, err := svcs.GetEmployeesForTeamContext(ctx, sr.TeamID)
 	if err != nil {
 		return err
 	}
 
 	for _, emp := range emps {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
 		if emp.AtSite(sr.SiteID, startDate, endDate) {
-			// get timecard data/work hours for each employee
-			// for time period.
-			wr, err := svcs.GetEmployeeWork(emp.ID.Hex(), uint(startDate.Year()))
-			if err == nil {
-				emp.Work = append(emp.Work, wr.Work...)
-			}
-			if startDate.Year() != endDate.Year() {
-				wr, err = svcs.GetEmployeeWork(emp.ID.Hex(), uint(endDate.Year()))
-				if err == nil {
-					emp.Work = append(emp.Work, wr.Work...)
-				}
-			}
 			sr.Employees = append(sr.Employees, emp)
 		}
 	}
 
+	// get timecard data/work hours for each employee for time period.
+	err = svcs.LoadEmployeeDetailsContext(ctx, sr.Employees, svcs.EmployeeOptions{
+		WorkYears: svcs.WorkYears(startDate, endDate),
+	})
+	if err != nil {
+		return err
+	}
+
 	// get the site's workcenters
-	site, err := svcs.GetSite(sr.TeamID, sr.SiteID)
+	site, err := svcs.GetSiteContext(ctx, sr.TeamID, sr.SiteID)
 	if err != nil {
 		return err
 	}
@@ -67,7 +75,7 @@ func (sr *ScheduleReport) Create() error {
 	sort.Sort(sites.ByWorkcenter(sr.Workcenters))
 
 	// create styles for display on each monthly sheet
-	err = sr.CreateStyles()
+	err = sr.createStyles(ctx)
 	if err != nil {
 		return err
 	}
@@ -81,7 +89,7 @@ func (sr *ScheduleReport) Create() error {
 	}
 
 	// add a leave legend sheet
-	sr.CreateLegendSheet()
+	sr.createLegendSheet(ctx)
 
 	// remove the provided sheet "Sheet1" from the workbook
 	sr.Report.DeleteSheet("Sheet1")
@@ -99,11 +107,15 @@ func (sr *ScheduleReport) Create() error {
 }
 
 func (sr *ScheduleReport) CreateStyles() error {
+	return sr.createStyles(context.Background())
+}
+
+func (sr *ScheduleReport) createStyles(ctx context.Context) error {
 	//get all the workcodes from the team object and create the
 	// styles for each one, plus one for weekend (non-leave), and
 	// even and odd non-leaves.  Also need style for month label and workcenter
 
-	team, err := svcs.GetTeam(sr.TeamID)
+	team, err := svcs.GetTeamContext(ctx, sr.TeamID)
 	if err != nil {
 		return err
 	}
@@ -487,6 +499,10 @@ func (sr *ScheduleReport) CreateEmployeeRow(sheetLabel string,
 }
 
 func (sr *ScheduleReport) CreateLegendSheet() error {
+	return sr.createLegendSheet(context.Background())
+}
+
+func (sr *ScheduleReport) createLegendSheet(ctx context.Context) error {
 	sheetLabel := "Legend"
 	sr.Report.NewSheet(sheetLabel)
 	options := excelize.ViewOptions{}
@@ -494,7 +510,7 @@ func (sr *ScheduleReport) CreateLegendSheet() error {
 	sr.Report.SetSheetView(sheetLabel, 0, &options)
 	sr.Report.SetColWidth(sheetLabel, "A", "A", 30)
 
-	team, err := svcs.GetTeam(sr.TeamID)
+	team, err := svcs.GetTeamContext(ctx, sr.TeamID)
 	if err != nil {
 		return err
 	}
@@ -1,6 +1,7 @@
 package reports
 
 import (
+	"context"
 	"sort"
 	"strconv"
 	"strings"
@@ -25,40 +26,47 @@ type SiteScheduleReport struct {
 }
 
 func (sr *SiteScheduleReport) Create() error {
+	return sr.CreateContext(context.Background())
+}
+
+// CreateContext builds the site schedule workbook within the context.
+func (sr *SiteScheduleReport) CreateContext(ctx context.Context) error {
+	ctx, cancel := createContext(ctx)
+	defer cancel()
 	sr.Styles = make(map[string]int)
 	sr.Workcodes = make(map[string]bool)
 	sr.Report = excelize.NewFile()
-	sr.Date = time.Now().UTC()
+	sr.Date = employees.SiteDate(time.Now(),
+		siteLocation(ctx, sr.TeamID, sr.SiteID))
 
 	// get employees with assignments for the site that are assigned
 	// during the year.
 	startDate := time.Date(sr.Date.Year(), sr.Date.Month(), 1, 0, 0, 0, 0, time.UTC)
 	endDate := startDate.AddDate(0, 2, 0).AddDate(0, 0, -1)
-	emps, err := svcs.GetEmployeesForTeam(sr.TeamID)
+	emps, err := svcs.GetEmployeesForTeamContext(ctx, sr.TeamID)
 	if err != nil {
 		return err
 	}
 
 	for _, emp := range emps {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
 		if emp.AtSite(sr.SiteID, startDate, endDate) {
-			// get timecard data/work hours for each employee
-			// for time period.
-			wr, err := svcs.GetEmployeeWork(emp.ID.Hex(), uint(startDate.Year()))
-			if err == nil {
-				emp.Work = append(emp.Work, wr.Work...)
-			}
-			if startDate.Year() != endDate.Year() {
-				wr, err = svcs.GetEmployeeWork(emp.ID.Hex(), uint(endDate.Year()))
-				if err == nil {
-					emp.Work = append(emp.Work, wr.Work...)
-				}
-			}
 			sr.Employees = append(sr.Employees, emp)
 		}
 	}
 
+	// get timecard data/work hours for each employee for time period.
+	err = svcs.LoadEmployeeDetailsContext(ctx, sr.Employees, svcs.EmployeeOptions{
+		WorkYears: svcs.WorkYears(startDate, endDate),
+	})
+	if err != nil {
+		return err
+	}
+
 	// get the site's workcenters
-	site, err := svcs.GetSite(sr.TeamID, sr.SiteID)
+	site, err := svcs.GetSiteContext(ctx, sr.TeamID, sr.SiteID)
 	if err != nil {
 		return err
 	}
@@ -66,7 +74,7 @@ func (sr *SiteScheduleReport) Create() error {
 	sort.Sort(sites.ByWorkcenter(sr.Workcenters))
 
 	// create styles for display on each monthly sheet
-	err = sr.CreateStyles()
+	err = sr.createStyles(ctx)
 	if err != nil {
 		return err
 	}
@@ -81,7 +89,7 @@ func (sr *SiteScheduleReport) Create() error {
 	}
 
 	// add a leave legend sheet
-	sr.CreateLegendSheet()
+	sr.createLegendSheet(ctx)
 
 	// remove the provided sheet "Sheet1" from the workbook
 	sr.Report.DeleteSheet("Sheet1")
@@ -99,11 +107,15 @@ func (sr *SiteScheduleReport) Create() error {
 }
 
 func (sr *SiteScheduleReport) CreateStyles() error {
+	return sr.createStyles(context.Background())
+}
+
+func (sr *SiteScheduleReport) createStyles(ctx context.Context) error {
 	//get all the workcodes from the team object and create the
 	// styles for each one, plus one for weekend (non-leave), and
 	// even and odd non-leaves.  Also need style for month label and workcenter
 
-	team, err := svcs.GetTeam(sr.TeamID)
+	team, err := svcs.GetTeamContext(ctx, sr.TeamID)
 	if err != nil {
 		return err
 	}
@@ -470,6 +482,10 @@ func (sr *SiteScheduleReport) CreateEmployeeRow(sheetLabel string,
 }
 
 func (sr *SiteScheduleReport) CreateLegendSheet() error {
+	return sr.createLegendSheet(context.Background())
+}
+
+func (sr *SiteScheduleReport) createLegendSheet(ctx context.Context) error {
 	sheetLabel := "Legend"
 	sr.Report.NewSheet(sheetLabel)
 	options := excelize.ViewOptions{}
@@ -477,7 +493,7 @@ func (sr *SiteScheduleReport) CreateLegendSheet() error {
 	sr.Report.SetSheetView(sheetLabel, 0, &options)
 	sr.Report.SetColWidth(sheetLabel, "A", "A", 30)
 
-	team, err := svcs.GetTeam(sr.TeamID)
+	team, err := svcs.GetTeamContext(ctx, sr.TeamID)
 	if err != nil {
 		return err
 	}
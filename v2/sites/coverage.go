@@ -0,0 +1,223 @@
+package sites
+
+import (
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/erneap/models/v2/employees"
+)
+
+// CoverageLeave is an employee on leave who would otherwise have worked in the
+// coverage bucket, with the leave's times for partial leave.
+type CoverageLeave struct {
+	EmployeeID string  `json:"employeeid"`
+	Name       string  `json:"name"`
+	Code       string  `json:"code"`
+	Hours      float64 `json:"hours"`
+	StartTime  uint    `json:"starttime,omitempty"`
+	EndTime    uint    `json:"endtime,omitempty"`
+}
+
+// CoverageCount is the number of employees working in a workcenter's shift or
+// position on a day, against the shift's minimum (positions don't have one).
+// Employees working only part of the day because of partial leave are
+// counted, and listed as partial.
+type CoverageCount struct {
+	Workcenter string          `json:"workcenter"`
+	Shift      string          `json:"shift,omitempty"`
+	Position   string          `json:"position,omitempty"`
+	Minimum    uint            `json:"minimum"`
+	Count      int             `json:"count"`
+	Employees  []string        `json:"employees,omitempty"`
+	OnLeave    []CoverageLeave `json:"onleave,omitempty"`
+	Partial    []CoverageLeave `json:"partial,omitempty"`
+}
+
+// IsShort tells if the count is below the minimum.
+func (c *CoverageCount) IsShort() bool {
+	return c.Count < int(c.Minimum)
+}
+
+type CoverageDay struct {
+	Date   time.Time       `json:"date"`
+	Counts []CoverageCount `json:"counts"`
+}
+
+// CoverageShortfall is a shift below its minimum on a day.
+type CoverageShortfall struct {
+	Date time.Time `json:"date"`
+	CoverageCount
+}
+
+type ByCoverageShortfall []CoverageShortfall
+
+func (c ByCoverageShortfall) Len() int { return len(c) }
+func (c ByCoverageShortfall) Less(i, j int) bool {
+	if c[i].Date.Equal(c[j].Date) {
+		if c[i].Workcenter == c[j].Workcenter {
+			return c[i].Shift < c[j].Shift
+		}
+		return c[i].Workcenter < c[j].Workcenter
+	}
+	return c[i].Date.Before(c[j].Date)
+}
+func (c ByCoverageShortfall) Swap(i, j int) { c[i], c[j] = c[j], c[i] }
+
+type CoverageReport struct {
+	Site       string              `json:"site"`
+	StartDate  time.Time           `json:"startdate"`
+	EndDate    time.Time           `json:"enddate"`
+	Days       []CoverageDay       `json:"days"`
+	Shortfalls []CoverageShortfall `json:"shortfalls,omitempty"`
+}
+
+// GetCoverage resolves each employee's day at the site from start through
+// end, counting them in their workcenter's positions (for the employees
+// assigned to one) or shifts (by the shift's associated codes), like
+// Workcenter.Assign.  An employee on leave is listed with the shift or
+// position the leave took them from, and every shift below its minimum is a
+// shortfall.  The employees need their work loaded for past dates.
+func (s *Site) GetCoverage(emps []employees.Employee,
+	start, end time.Time) *CoverageReport {
+	answer := &CoverageReport{
+		Site:      s.ID,
+		StartDate: start,
+		EndDate:   end,
+	}
+	wkctrs := make([]Workcenter, len(s.Workcenters))
+	copy(wkctrs, s.Workcenters)
+	sort.Sort(ByWorkcenter(wkctrs))
+
+	schedules := make([][]employees.ScheduleDay, len(emps))
+	for i := range emps {
+		schedules[i] = emps[i].ResolveSchedule(start, end)
+	}
+	date := time.Date(start.Year(), start.Month(), start.Day(), 0, 0, 0, 0,
+		time.UTC)
+	last := time.Date(end.Year(), end.Month(), end.Day(), 0, 0, 0, 0, time.UTC)
+	for d := 0; !date.After(last); d, date = d+1, date.AddDate(0, 0, 1) {
+		day := CoverageDay{
+			Date: date,
+		}
+		for _, wc := range wkctrs {
+			for _, pos := range wc.Positions {
+				day.Counts = append(day.Counts, CoverageCount{
+					Workcenter: wc.ID,
+					Position:   pos.ID,
+				})
+			}
+			for _, shft := range wc.Shifts {
+				day.Counts = append(day.Counts, CoverageCount{
+					Workcenter: wc.ID,
+					Shift:      shft.ID,
+					Minimum:    shft.Minimums,
+				})
+			}
+		}
+		for i := range emps {
+			sched := schedules[i][d]
+			if !strings.EqualFold(sched.Site, s.ID) {
+				continue
+			}
+			if sched.IsWorkday() {
+				if c := s.coverageBucket(day.Counts, &emps[i], sched.Workcenter,
+					sched.Code); c >= 0 {
+					day.Counts[c].Count++
+					day.Counts[c].Employees = append(day.Counts[c].Employees,
+						emps[i].ID.Hex())
+					if sched.IsSplit() {
+						day.Counts[c].Partial = append(day.Counts[c].Partial,
+							CoverageLeave{
+								EmployeeID: emps[i].ID.Hex(),
+								Name:       emps[i].Name.GetLastFirst(),
+								Code:       sched.LeaveCode,
+								Hours:      sched.LeaveHours,
+								StartTime:  sched.LeaveStart,
+								EndTime:    sched.LeaveEnd,
+							})
+					}
+				}
+			} else if sched.Source == employees.SourceLeave {
+				// the leave took the employee from the last scheduled layer.
+				for l := len(sched.Overridden) - 1; l >= 0; l-- {
+					layer := sched.Overridden[l]
+					if layer.Source == employees.SourceLeave {
+						continue
+					}
+					if layer.Code == "" {
+						break
+					}
+					if c := s.coverageBucket(day.Counts, &emps[i],
+						layer.Workcenter, layer.Code); c >= 0 {
+						day.Counts[c].OnLeave = append(day.Counts[c].OnLeave,
+							CoverageLeave{
+								EmployeeID: emps[i].ID.Hex(),
+								Name:       emps[i].Name.GetLastFirst(),
+								Code:       sched.Code,
+								Hours:      sched.Hours,
+							})
+					}
+					break
+				}
+			}
+		}
+		for _, count := range day.Counts {
+			if count.Shift != "" && count.IsShort() {
+				answer.Shortfalls = append(answer.Shortfalls, CoverageShortfall{
+					Date:          day.Date,
+					CoverageCount: count,
+				})
+			}
+		}
+		answer.Days = append(answer.Days, day)
+	}
+	return answer
+}
+
+// coverageBucket finds the count for the employee working the code in the
+// workcenter, or -1 if there isn't one.
+func (s *Site) coverageBucket(counts []CoverageCount, emp *employees.Employee,
+	wkctr, code string) int {
+	for i, count := range counts {
+		if !strings.EqualFold(count.Workcenter, wkctr) || count.Position == "" {
+			continue
+		}
+		for _, wc := range s.Workcenters {
+			if !strings.EqualFold(wc.ID, count.Workcenter) {
+				continue
+			}
+			for _, pos := range wc.Positions {
+				if pos.ID != count.Position {
+					continue
+				}
+				for _, asgn := range pos.Assigned {
+					if strings.EqualFold(asgn, emp.ID.Hex()) {
+						return i
+					}
+				}
+			}
+		}
+	}
+	for i, count := range counts {
+		if !strings.EqualFold(count.Workcenter, wkctr) || count.Shift == "" {
+			continue
+		}
+		for _, wc := range s.Workcenters {
+			if !strings.EqualFold(wc.ID, count.Workcenter) {
+				continue
+			}
+			for _, shft := range wc.Shifts {
+				if shft.ID != count.Shift {
+					continue
+				}
+				for _, assoc := range shft.AssociatedCodes {
+					if strings.EqualFold(assoc, code) {
+						return i
+					}
+				}
+			}
+		}
+	}
+	return -1
+}
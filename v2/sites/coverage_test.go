@@ -0,0 +1,129 @@
+package sites
+
+import (
+	"testing"
+	"time"
+
+	"github.com/erneap/models/v2/employees"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func date(year int, month time.Month, day int) time.Time {
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+}
+
+// testEmployee is an employee of the site working its day shift Monday
+// through Friday.
+func testEmployee(site, last string) employees.Employee {
+	emp := employees.Employee{
+		ID:     primitive.NewObjectID(),
+		SiteID: site,
+		Name:   employees.EmployeeName{FirstName: "T", LastName: last},
+	}
+	emp.AddAssignment(site, "wc", date(2020, time.January, 1))
+	return emp
+}
+
+// testSite is a site with a day shift needing the minimum and a lead position
+// held by the lead.
+func testSite(minimum uint, lead string) Site {
+	return Site{
+		ID: "s",
+		Workcenters: []Workcenter{
+			{
+				ID: "wc",
+				Shifts: []Shift{
+					{ID: "day", AssociatedCodes: []string{"D"}, Minimums: minimum},
+				},
+				Positions: []Position{
+					{ID: "lead", Assigned: []string{lead}},
+				},
+			},
+		},
+	}
+}
+
+func TestSiteGetCoverage(t *testing.T) {
+	a := testEmployee("s", "A")
+	b := testEmployee("s", "B")
+	lead := testEmployee("s", "Lead")
+	other := testEmployee("t", "Other")
+	b.Leaves = append(b.Leaves, employees.LeaveDay{ID: 1,
+		LeaveDate: date(2024, time.June, 4), Code: "V", Hours: 8,
+		Status: employees.LeaveApproved})
+	partial := employees.LeaveDay{ID: 1, LeaveDate: date(2024, time.June, 5),
+		Code: "V", Status: employees.LeaveApproved}
+	if err := partial.SetTimes(1300, 1500); err != nil {
+		t.Fatal(err)
+	}
+	a.Leaves = append(a.Leaves, partial)
+	site := testSite(2, lead.ID.Hex())
+
+	report := site.GetCoverage([]employees.Employee{a, b, lead, other},
+		date(2024, time.June, 3), date(2024, time.June, 8))
+
+	tests := []struct {
+		date    time.Time
+		day     int
+		onLeave []string
+		partial []string
+		leads   int
+	}{
+		{date: date(2024, time.June, 3), day: 2, leads: 1},
+		{date: date(2024, time.June, 4), day: 1, onLeave: []string{b.ID.Hex()},
+			leads: 1},
+		{date: date(2024, time.June, 5), day: 2, partial: []string{a.ID.Hex()},
+			leads: 1},
+		{date: date(2024, time.June, 6), day: 2, leads: 1},
+		{date: date(2024, time.June, 7), day: 2, leads: 1},
+		{date: date(2024, time.June, 8)},
+	}
+	if len(report.Days) != len(tests) {
+		t.Fatalf("got %d days, want %d", len(report.Days), len(tests))
+	}
+	for i, tt := range tests {
+		t.Run(tt.date.Format("2006-01-02"), func(t *testing.T) {
+			day := report.Days[i]
+			if !day.Date.Equal(tt.date) || len(day.Counts) != 2 {
+				t.Fatalf("day = %+v", day)
+			}
+			// positions come before shifts.
+			lc, dc := day.Counts[0], day.Counts[1]
+			if lc.Position != "lead" || lc.Count != tt.leads {
+				t.Errorf("lead count = %+v, want %d", lc, tt.leads)
+			}
+			if dc.Shift != "day" || dc.Count != tt.day || dc.Minimum != 2 {
+				t.Errorf("day count = %+v, want %d", dc, tt.day)
+			}
+			if !sameLeave(dc.OnLeave, tt.onLeave) {
+				t.Errorf("on leave = %+v, want %v", dc.OnLeave, tt.onLeave)
+			}
+			if !sameLeave(dc.Partial, tt.partial) {
+				t.Errorf("partial = %+v, want %v", dc.Partial, tt.partial)
+			}
+		})
+	}
+
+	// the shortfalls are the days under the minimum.
+	want := []time.Time{date(2024, time.June, 4), date(2024, time.June, 8)}
+	if len(report.Shortfalls) != len(want) {
+		t.Fatalf("shortfalls = %+v", report.Shortfalls)
+	}
+	for i, w := range want {
+		if !report.Shortfalls[i].Date.Equal(w) {
+			t.Errorf("shortfall %d = %v, want %v", i, report.Shortfalls[i].Date, w)
+		}
+	}
+}
+
+func sameLeave(got []CoverageLeave, want []string) bool {
+	if len(got) != len(want) {
+		return false
+	}
+	for i, lv := range got {
+		if lv.EmployeeID != want[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,396 @@
+package sites
+
+import (
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/erneap/models/v2/employees"
+	"github.com/erneap/models/v2/labor"
+)
+
+// GenerateOptions are the rules the schedule generator follows.
+type GenerateOptions struct {
+	StartDate time.Time `json:"startdate"`
+	EndDate   time.Time `json:"enddate"`
+	// Workcodes give each shift's start time (in hours), by the shift's first
+	// associated code.
+	Workcodes []labor.Workcode `json:"workcodes"`
+	// Location is the site's location the shifts start in, UTC when not given.
+	Location *time.Location `json:"-"`
+	// Hours is the length of every shift, eight when not given.
+	Hours float64 `json:"hours"`
+	// DaysPerWeek is the number of days each employee should work in each
+	// Sunday to Saturday week, five when not given.
+	DaysPerWeek int `json:"daysperweek"`
+	// MaxConsecutiveDays limits the days worked in a row, six when not given.
+	MaxConsecutiveDays int `json:"maxconsecutive"`
+	// MinRestHours is the least time between the end of a shift and the start
+	// of the next, eight when not given.
+	MinRestHours float64 `json:"minrest"`
+	// Specialties lists, by shift id, the specialties an employee must be
+	// qualified in to work the shift.
+	Specialties map[string][]int `json:"specialties,omitempty"`
+	// MidShifts are the shift ids shared out evenly like the weekends.
+	MidShifts []string `json:"midshifts,omitempty"`
+}
+
+// DraftVariation is a generated variation for an employee, not yet stored.
+type DraftVariation struct {
+	EmployeeID string              `json:"employeeid"`
+	Name       string              `json:"name"`
+	Variation  employees.Variation `json:"variation"`
+}
+
+// GeneratedSchedule is the generator's proposal:  a variation for each
+// employee and the shifts it couldn't bring up to their minimums.
+type GeneratedSchedule struct {
+	Site       string              `json:"site"`
+	Workcenter string              `json:"workcenter"`
+	Variations []DraftVariation    `json:"variations"`
+	Unfilled   []CoverageShortfall `json:"unfilled,omitempty"`
+}
+
+// genEmployee is an employee's state while generating.
+type genEmployee struct {
+	emp         *employees.Employee
+	shifts      map[time.Time]*Shift
+	total       int
+	weekends    int
+	mids        int
+	consecutive int
+	lastEnd     time.Time
+	weekDays    int
+	available   bool
+}
+
+// GenerateSchedule proposes a schedule for the workcenter's employees at the
+// site, one day at a time:  each shift is first filled to its minimum, then
+// employees who need the day to reach their days for the week are added to
+// the shift with the fewest employees over its minimum.  An employee is only
+// given a shift when assigned to the workcenter, not on approved leave,
+// qualified for the shift, under the consecutive day limit and rested since
+// the last shift, counting the days scheduled before the start.  Among the
+// employees who can work a shift, the ones with the fewest shifts (and, on
+// weekends or mid shifts, the fewest of those) are picked first, so the work
+// is shared out fairly.
+func (w *Workcenter) GenerateSchedule(site string, emps []employees.Employee,
+	opts GenerateOptions) *GeneratedSchedule {
+	if opts.Hours <= 0 {
+		opts.Hours = 8
+	}
+	if opts.DaysPerWeek <= 0 {
+		opts.DaysPerWeek = 5
+	}
+	if opts.MaxConsecutiveDays <= 0 {
+		opts.MaxConsecutiveDays = 6
+	}
+	if opts.MinRestHours <= 0 {
+		opts.MinRestHours = 8
+	}
+	answer := &GeneratedSchedule{
+		Site:       site,
+		Workcenter: w.ID,
+	}
+	shifts := make([]Shift, len(w.Shifts))
+	copy(shifts, w.Shifts)
+	sort.Sort(ByShift(shifts))
+
+	start := time.Date(opts.StartDate.Year(), opts.StartDate.Month(),
+		opts.StartDate.Day(), 0, 0, 0, 0, time.UTC)
+	end := time.Date(opts.EndDate.Year(), opts.EndDate.Month(),
+		opts.EndDate.Day(), 0, 0, 0, 0, time.UTC)
+
+	gens := make([]*genEmployee, len(emps))
+	for i := range emps {
+		gens[i] = &genEmployee{
+			emp:    &emps[i],
+			shifts: make(map[time.Time]*Shift),
+		}
+		gens[i].seed(start, &opts)
+	}
+
+	weekTarget := opts.DaysPerWeek
+	for date := start; !date.After(end); date = date.AddDate(0, 0, 1) {
+		// days left in the week (and the period), including the date.
+		daysLeft := 7 - int(date.Weekday())
+		if left := int(end.Sub(date).Hours()/24) + 1; left < daysLeft {
+			daysLeft = left
+		}
+		if date.Weekday() == time.Sunday || date.Equal(start) {
+			for _, g := range gens {
+				g.weekDays = 0
+			}
+			// a partial week needs fewer days.
+			weekTarget = int(math.Round(float64(opts.DaysPerWeek*daysLeft) / 7))
+		}
+		var available []*genEmployee
+		for _, g := range gens {
+			assigned, onLeave := w.genAvailable(g.emp, site, date)
+			if assigned {
+				g.available = true
+			}
+			if assigned && !onLeave {
+				available = append(available, g)
+			} else {
+				if onLeave {
+					g.weekDays++
+				}
+				g.consecutive = 0
+			}
+		}
+		counts := make([]int, len(shifts))
+
+		// fill each shift to its minimum.
+		for s := range shifts {
+			shft := &shifts[s]
+			for counts[s] < int(shft.Minimums) {
+				best := w.genPick(available, shft, date, &opts)
+				if best == nil {
+					break
+				}
+				best.assign(shft, date, &opts)
+				counts[s]++
+			}
+			if counts[s] < int(shft.Minimums) {
+				answer.Unfilled = append(answer.Unfilled, CoverageShortfall{
+					Date: date,
+					CoverageCount: CoverageCount{
+						Workcenter: w.ID,
+						Shift:      shft.ID,
+						Minimum:    shft.Minimums,
+						Count:      counts[s],
+					},
+				})
+			}
+		}
+
+		// add the employees who need the day for their days in the week.
+		for _, g := range available {
+			if _, ok := g.shifts[date]; ok || weekTarget-g.weekDays < daysLeft {
+				continue
+			}
+			best := -1
+			for s := range shifts {
+				if !genCanWork(g, &shifts[s], date, &opts) {
+					continue
+				}
+				if best < 0 || counts[s]-int(shifts[s].Minimums) <
+					counts[best]-int(shifts[best].Minimums) {
+					best = s
+				}
+			}
+			if best >= 0 {
+				g.assign(&shifts[best], date, &opts)
+				counts[best]++
+			}
+		}
+		for _, g := range available {
+			if _, ok := g.shifts[date]; !ok {
+				g.consecutive = 0
+			}
+		}
+	}
+
+	for _, g := range gens {
+		if !g.available {
+			continue
+		}
+		answer.Variations = append(answer.Variations, DraftVariation{
+			EmployeeID: g.emp.ID.Hex(),
+			Name:       g.emp.Name.GetLastFirst(),
+			Variation:  w.genVariation(g, site, start, end, &opts),
+		})
+	}
+	return answer
+}
+
+// genAvailable tells if the employee is assigned to the workcenter at the site
+// on the date, and if so, if the employee is on approved leave.  A day of
+// leave counts as a day worked in the week.
+func (w *Workcenter) genAvailable(emp *employees.Employee, site string,
+	date time.Time) (bool, bool) {
+	assigned := false
+	for _, asgmt := range emp.Assignments {
+		if asgmt.UseAssignment(site, date) &&
+			strings.EqualFold(asgmt.Workcenter, w.ID) {
+			assigned = true
+		}
+	}
+	if !assigned {
+		return false, false
+	}
+	day := emp.ResolveDay(date)
+	return true, day.Source == employees.SourceLeave
+}
+
+// genPick picks the available employee who best fits the shift, or nil if
+// none can work it.
+func (w *Workcenter) genPick(available []*genEmployee, shft *Shift,
+	date time.Time, opts *GenerateOptions) *genEmployee {
+	var best *genEmployee
+	bestScore := 0
+	weekend := date.Weekday() == time.Saturday || date.Weekday() == time.Sunday
+	mid := genIsMid(shft, opts)
+	for _, g := range available {
+		if _, ok := g.shifts[date]; ok || !genCanWork(g, shft, date, opts) {
+			continue
+		}
+		score := g.total
+		if weekend {
+			score += 3 * g.weekends
+		}
+		if mid {
+			score += 3 * g.mids
+		}
+		if best == nil || score < bestScore || (score == bestScore &&
+			g.emp.ID.Hex() < best.emp.ID.Hex()) {
+			best = g
+			bestScore = score
+		}
+	}
+	return best
+}
+
+// genCanWork checks the employee's qualifications, consecutive days and rest
+// for working the shift on the date.
+func genCanWork(g *genEmployee, shft *Shift, date time.Time,
+	opts *GenerateOptions) bool {
+	for _, spec := range opts.Specialties[shft.ID] {
+		qualified := false
+		for _, sp := range g.emp.Specialties {
+			if sp.SpecialtyID == spec && sp.Qualified {
+				qualified = true
+			}
+		}
+		if !qualified {
+			return false
+		}
+	}
+	if g.consecutive >= opts.MaxConsecutiveDays {
+		return false
+	}
+	if !g.lastEnd.IsZero() {
+		rest := genShiftStart(shft, date, opts).Sub(g.lastEnd).Hours()
+		if rest < opts.MinRestHours {
+			return false
+		}
+	}
+	return true
+}
+
+func (g *genEmployee) assign(shft *Shift, date time.Time,
+	opts *GenerateOptions) {
+	g.shifts[date] = shft
+	g.total++
+	g.weekDays++
+	g.consecutive++
+	if date.Weekday() == time.Saturday || date.Weekday() == time.Sunday {
+		g.weekends++
+	}
+	if genIsMid(shft, opts) {
+		g.mids++
+	}
+	g.lastEnd = genShiftStart(shft, date, opts).Add(
+		time.Duration(opts.Hours * float64(time.Hour)))
+}
+
+// seed sets the employee's consecutive days and the end of their last shift
+// from the days they're scheduled to work just before the start, so the
+// first days of the period respect the limits too.
+func (g *genEmployee) seed(start time.Time, opts *GenerateOptions) {
+	days := g.emp.ResolveSchedule(start.AddDate(0, 0, -opts.MaxConsecutiveDays),
+		start.AddDate(0, 0, -1))
+	for _, day := range days {
+		if !day.IsWorkday() {
+			g.consecutive = 0
+			continue
+		}
+		g.consecutive++
+		hours := day.Hours
+		if hours <= 0 {
+			hours = opts.Hours
+		}
+		g.lastEnd = genCodeStart(day.Code, day.Date, opts).Add(
+			time.Duration(hours * float64(time.Hour)))
+	}
+}
+
+// genShiftStart provides the time the shift starts on the date, from the
+// workcode of its first associated code.
+func genShiftStart(shft *Shift, date time.Time,
+	opts *GenerateOptions) time.Time {
+	code := ""
+	if len(shft.AssociatedCodes) > 0 {
+		code = shft.AssociatedCodes[0]
+	}
+	return genCodeStart(code, date, opts)
+}
+
+// genCodeStart provides the time the workcode's shift starts on the date,
+// midnight for a code that isn't one of the options' workcodes.
+func genCodeStart(code string, date time.Time,
+	opts *GenerateOptions) time.Time {
+	for _, wc := range opts.Workcodes {
+		if strings.EqualFold(wc.Id, code) {
+			return wc.StartsAt(date, opts.Location)
+		}
+	}
+	wc := labor.Workcode{}
+	return wc.StartsAt(date, opts.Location)
+}
+
+func genIsMid(shft *Shift, opts *GenerateOptions) bool {
+	for _, id := range opts.MidShifts {
+		if strings.EqualFold(id, shft.ID) {
+			return true
+		}
+	}
+	return false
+}
+
+// genVariation builds the employee's variation for the period, with its
+// schedule covering the whole weeks like Variation.SetScheduleDays.
+func (w *Workcenter) genVariation(g *genEmployee, site string, start,
+	end time.Time, opts *GenerateOptions) employees.Variation {
+	id := uint(0)
+	for _, vari := range g.emp.Variations {
+		if vari.ID > id {
+			id = vari.ID
+		}
+	}
+	vari := employees.Variation{
+		ID:        id + 1,
+		Site:      site,
+		StartDate: start,
+		EndDate:   end,
+	}
+	first := start
+	for first.Weekday() != time.Sunday {
+		first = first.AddDate(0, 0, -1)
+	}
+	last := end
+	for last.Weekday() != time.Saturday {
+		last = last.AddDate(0, 0, 1)
+	}
+	i := uint(0)
+	for date := first; !date.After(last); date = date.AddDate(0, 0, 1) {
+		wd := employees.Workday{
+			ID: i,
+		}
+		if shft, ok := g.shifts[date]; ok {
+			wd.Workcenter = w.ID
+			wd.Hours = opts.Hours
+			if len(shft.AssociatedCodes) > 0 {
+				wd.Code = shft.AssociatedCodes[0]
+			}
+			if genIsMid(shft, opts) {
+				vari.IsMids = true
+			}
+		}
+		vari.Schedule.Workdays = append(vari.Schedule.Workdays, wd)
+		i++
+	}
+	return vari
+}
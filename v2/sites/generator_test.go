@@ -0,0 +1,200 @@
+package sites
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/erneap/models/v2/employees"
+	"github.com/erneap/models/v2/labor"
+)
+
+func TestWorkcenterGenerateSchedule(t *testing.T) {
+	// two whole weeks, Sunday June 2 through Saturday June 15, 2024.
+	start := date(2024, time.June, 2)
+	end := date(2024, time.June, 15)
+	workcodes := []labor.Workcode{
+		{Id: "D", StartTime: 6},
+		{Id: "N", StartTime: 22},
+	}
+	day := Shift{ID: "day", SortID: 1, AssociatedCodes: []string{"D"}}
+	night := Shift{ID: "night", SortID: 2, AssociatedCodes: []string{"N"}}
+
+	tests := []struct {
+		name    string
+		count   int
+		shifts  []Shift
+		opts    GenerateOptions
+		onLeave time.Time
+		// nightsBefore has the first employee working nights every day of
+		// the week before the start.
+		nightsBefore bool
+		unfilled     int
+	}{
+		{
+			name:  "covered",
+			count: 3,
+			shifts: []Shift{
+				{ID: day.ID, AssociatedCodes: day.AssociatedCodes, Minimums: 2},
+			},
+		},
+		{
+			name:  "short of employees",
+			count: 2,
+			shifts: []Shift{
+				{ID: day.ID, AssociatedCodes: day.AssociatedCodes, Minimums: 3},
+			},
+			unfilled: 14,
+		},
+		{
+			name:  "day and night",
+			count: 4,
+			shifts: []Shift{
+				{ID: day.ID, SortID: day.SortID, AssociatedCodes: day.AssociatedCodes,
+					Minimums: 1},
+				{ID: night.ID, SortID: night.SortID,
+					AssociatedCodes: night.AssociatedCodes, Minimums: 1},
+			},
+		},
+		{
+			name:  "leave",
+			count: 3,
+			shifts: []Shift{
+				{ID: day.ID, AssociatedCodes: day.AssociatedCodes, Minimums: 2},
+			},
+			onLeave: date(2024, time.June, 5),
+		},
+		{
+			name:  "no one qualified",
+			count: 2,
+			shifts: []Shift{
+				{ID: day.ID, AssociatedCodes: day.AssociatedCodes, Minimums: 1},
+			},
+			opts:     GenerateOptions{Specialties: map[string][]int{"day": {7}}},
+			unfilled: 14,
+		},
+		{
+			name:  "consecutive days",
+			count: 3,
+			shifts: []Shift{
+				{ID: day.ID, AssociatedCodes: day.AssociatedCodes, Minimums: 1},
+			},
+			opts: GenerateOptions{DaysPerWeek: 4, MaxConsecutiveDays: 2},
+		},
+		{
+			name:  "worked before the start",
+			count: 3,
+			shifts: []Shift{
+				{ID: day.ID, AssociatedCodes: day.AssociatedCodes, Minimums: 2},
+			},
+			nightsBefore: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			wc := Workcenter{ID: "wc", Shifts: tt.shifts}
+			var emps []employees.Employee
+			for i := 0; i < tt.count; i++ {
+				emp := testEmployee("s", fmt.Sprintf("E%d", i))
+				if i == 0 && !tt.onLeave.IsZero() {
+					emp.Leaves = append(emp.Leaves, employees.LeaveDay{ID: 1,
+						LeaveDate: tt.onLeave, Code: "V", Hours: 8,
+						Status: "APPROVED"})
+				}
+				if i == 0 && tt.nightsBefore {
+					vari := employees.Variation{ID: 1, Site: "s",
+						StartDate: start.AddDate(0, 0, -7),
+						EndDate:   start.AddDate(0, 0, -1)}
+					for d := uint(0); d < 7; d++ {
+						vari.Schedule.Workdays = append(vari.Schedule.Workdays,
+							employees.Workday{ID: d, Workcenter: "wc", Code: "N",
+								Hours: 8})
+					}
+					emp.Variations = append(emp.Variations, vari)
+				}
+				emps = append(emps, emp)
+			}
+			// someone at another workcenter isn't scheduled.
+			other := testEmployee("s", "Other")
+			other.Assignments[0].Workcenter = "other"
+			emps = append(emps, other)
+
+			opts := tt.opts
+			opts.StartDate = start
+			opts.EndDate = end
+			opts.Workcodes = workcodes
+			got := wc.GenerateSchedule("s", emps, opts)
+			if len(got.Unfilled) != tt.unfilled {
+				t.Errorf("unfilled = %d, want %d", len(got.Unfilled), tt.unfilled)
+			}
+			if len(got.Variations) != tt.count {
+				t.Fatalf("variations = %d, want %d", len(got.Variations),
+					tt.count)
+			}
+
+			maxDays, maxConsec := 5, 6
+			if opts.DaysPerWeek > 0 {
+				maxDays = opts.DaysPerWeek
+			}
+			if opts.MaxConsecutiveDays > 0 {
+				maxConsec = opts.MaxConsecutiveDays
+			}
+			counts := make(map[string]int)
+			for v, draft := range got.Variations {
+				vari := draft.Variation
+				if !vari.StartDate.Equal(start) || !vari.EndDate.Equal(end) ||
+					len(vari.Schedule.Workdays) != 14 {
+					t.Fatalf("variation = %+v", vari)
+				}
+				week, consec := 0, 0
+				var lastEnd time.Time
+				if v == 0 && tt.nightsBefore {
+					consec = 7
+					lastEnd = start.Add(6 * time.Hour)
+				}
+				for d, wd := range vari.Schedule.Workdays {
+					date := start.AddDate(0, 0, d)
+					if date.Weekday() == time.Sunday {
+						week = 0
+					}
+					if wd.Code == "" {
+						consec = 0
+						continue
+					}
+					if v == 0 && date.Equal(tt.onLeave) {
+						t.Errorf("scheduled on leave %s", date.Format("Jan 2"))
+					}
+					counts[date.Format("Jan 2")+wd.Code]++
+					week++
+					consec++
+					begin := date.Add(6 * time.Hour)
+					if wd.Code == "N" {
+						begin = date.Add(22 * time.Hour)
+					}
+					if !lastEnd.IsZero() && begin.Sub(lastEnd).Hours() < 8 {
+						t.Errorf("%s: %v rest before %s", draft.Name,
+							begin.Sub(lastEnd), date.Format("Jan 2"))
+					}
+					lastEnd = begin.Add(8 * time.Hour)
+					// filling the minimums can take more than the days for the
+					// week.
+					if (tt.unfilled == 0 && week > maxDays) || consec > maxConsec {
+						t.Errorf("%s: %d days in the week, %d in a row by %s",
+							draft.Name, week, consec, date.Format("Jan 2"))
+					}
+				}
+			}
+			if tt.unfilled == 0 {
+				for d := 0; d < 14; d++ {
+					date := start.AddDate(0, 0, d).Format("Jan 2")
+					for _, shft := range tt.shifts {
+						if counts[date+shft.AssociatedCodes[0]] < int(shft.Minimums) {
+							t.Errorf("%s %s has %d", date, shft.ID,
+								counts[date+shft.AssociatedCodes[0]])
+						}
+					}
+				}
+			}
+		})
+	}
+}
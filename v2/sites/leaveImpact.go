@@ -0,0 +1,227 @@
+package sites
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/erneap/models/v2/employees"
+)
+
+// The levels of a leave request's impact on the site's staffing.  A warning
+// is shown to the employee and the approvers, a block stops the request from
+// being submitted or approved.
+const (
+	ImpactNone    = ""
+	ImpactWarning = "warning"
+	ImpactBlock   = "block"
+)
+
+// ErrLeaveBlocked is matched (with errors.Is) by every LeaveBlockedError.
+var ErrLeaveBlocked = errors.New("leave is blocked by the site's staffing rules")
+
+// LeaveImpactRules are a site's rules for judging leave's impact on its
+// shifts.  Leave that takes a shift below its minimum is a warning, or a
+// block with BlockBelowMinimum, and leave that leaves the shift fewer than
+// WarnBuffer employees above its minimum is a warning.  MaxOnLeave blocks
+// leave when more employees than it are off the same shift on a day, zero
+// being no limit.  CountRequested counts others' leave that is requested but
+// not approved yet as taken.
+type LeaveImpactRules struct {
+	WarnBuffer        uint `json:"warnBuffer,omitempty" bson:"warnBuffer,omitempty"`
+	BlockBelowMinimum bool `json:"blockBelowMinimum,omitempty" bson:"blockBelowMinimum,omitempty"`
+	MaxOnLeave        int  `json:"maxOnLeave,omitempty" bson:"maxOnLeave,omitempty"`
+	CountRequested    bool `json:"countRequested,omitempty" bson:"countRequested,omitempty"`
+}
+
+// GetLeaveImpactRules provides the site's leave impact rules, or for a site
+// without them, warnings for leave that takes a shift below its minimum,
+// counting requested leave.
+func (s *Site) GetLeaveImpactRules() LeaveImpactRules {
+	if s.LeaveImpact != nil {
+		return *s.LeaveImpact
+	}
+	return LeaveImpactRules{
+		CountRequested: true,
+	}
+}
+
+// LeaveImpactDay is the coverage of the shift or position the leave takes the
+// employee from on a day, with the leave, and the others off it (for all or,
+// with partial leave, part of the day).  Requested lists the employees off
+// whose leave isn't approved yet.
+type LeaveImpactDay struct {
+	Date time.Time `json:"date"`
+	CoverageCount
+	Requested []string `json:"requested,omitempty"`
+	Level     string   `json:"level,omitempty"`
+	Message   string   `json:"message,omitempty"`
+}
+
+// LeaveImpact is a leave request's impact on the site's staffing, on each day
+// the leave overlaps others' leave or is short of the shift's minimum, and
+// the highest level of those days.
+type LeaveImpact struct {
+	RequestID string           `json:"requestid"`
+	Level     string           `json:"level,omitempty"`
+	Days      []LeaveImpactDay `json:"days,omitempty"`
+}
+
+// IsBlocked tells if the leave is blocked by the site's rules.
+func (li *LeaveImpact) IsBlocked() bool {
+	return li.Level == ImpactBlock
+}
+
+// LeaveBlockedError tells which leave request the site's staffing rules
+// blocked and why.
+type LeaveBlockedError struct {
+	Impact *LeaveImpact
+}
+
+func (e *LeaveBlockedError) Error() string {
+	for _, day := range e.Impact.Days {
+		if day.Level == ImpactBlock {
+			return fmt.Sprintf("leave request %s: %s: %s", e.Impact.RequestID,
+				day.Date.Format("2006-01-02"), day.Message)
+		}
+	}
+	return ErrLeaveBlocked.Error()
+}
+
+func (e *LeaveBlockedError) Is(target error) bool {
+	return target == ErrLeaveBlocked
+}
+
+// GetLeaveImpact checks the employee's leave request against the site's
+// coverage.  The site's employees (who need their work loaded for past dates)
+// are resolved with the request's leave days taken, along with, by the
+// site's rules, the others' requested leave, and the days of the shift or
+// position the request takes the employee from are judged by the site's
+// rules.  Modified time requests have no impact.
+func (s *Site) GetLeaveImpact(emp *employees.Employee,
+	req *employees.LeaveRequest, emps []employees.Employee) *LeaveImpact {
+	answer := &LeaveImpact{
+		RequestID: req.ID,
+	}
+	if strings.EqualFold(req.PrimaryCode, "mod") {
+		return answer
+	}
+	rules := s.GetLeaveImpactRules()
+	requested := make(map[string]map[time.Time]bool)
+	var staff []employees.Employee
+	requester := *emp
+	found := false
+	for _, e := range emps {
+		if e.ID == emp.ID {
+			// the site's copy has the employee's work loaded.
+			requester = e
+			found = true
+			continue
+		}
+		e.Leaves = append([]employees.LeaveDay{}, e.Leaves...)
+		if rules.CountRequested {
+			for _, lr := range e.Requests {
+				if lr.GetStatus() != employees.LeaveRequested ||
+					lr.EndDate.Before(req.StartDate) ||
+					lr.StartDate.After(req.EndDate) {
+					continue
+				}
+				for _, day := range impactLeaveDays(lr) {
+					if requested[e.ID.Hex()] == nil {
+						requested[e.ID.Hex()] = make(map[time.Time]bool)
+					}
+					requested[e.ID.Hex()][day.LeaveDate] = true
+					e.Leaves = append(e.Leaves, day)
+				}
+			}
+		}
+		staff = append(staff, e)
+	}
+	if !found && !strings.EqualFold(emp.SiteID, s.ID) {
+		return answer
+	}
+	requester.Leaves = nil
+	for _, lv := range emp.Leaves {
+		if lv.RequestID != req.ID {
+			requester.Leaves = append(requester.Leaves, lv)
+		}
+	}
+	requester.Leaves = append(requester.Leaves, impactLeaveDays(*req)...)
+	staff = append(staff, requester)
+
+	coverage := s.GetCoverage(staff, req.StartDate, req.EndDate)
+	for _, cday := range coverage.Days {
+		for _, count := range cday.Counts {
+			off, partial := false, false
+			for _, lv := range count.OnLeave {
+				if lv.EmployeeID == emp.ID.Hex() {
+					off = true
+				}
+			}
+			for _, lv := range count.Partial {
+				if lv.EmployeeID == emp.ID.Hex() {
+					partial = true
+				}
+			}
+			if !off && !partial {
+				continue
+			}
+			day := LeaveImpactDay{
+				Date:          cday.Date,
+				CoverageCount: count,
+			}
+			for _, lv := range append(count.OnLeave, count.Partial...) {
+				if requested[lv.EmployeeID][cday.Date] {
+					day.Requested = append(day.Requested, lv.EmployeeID)
+				}
+			}
+			if count.Shift != "" && count.IsShort() {
+				day.Level = ImpactWarning
+				if rules.BlockBelowMinimum {
+					day.Level = ImpactBlock
+				}
+				day.Message = fmt.Sprintf("%s %s would have %d of its minimum %d",
+					count.Workcenter, count.Shift, count.Count, count.Minimum)
+			} else if count.Shift != "" &&
+				count.Count < int(count.Minimum+rules.WarnBuffer) {
+				day.Level = ImpactWarning
+				day.Message = fmt.Sprintf("%s %s would have %d, near its minimum %d",
+					count.Workcenter, count.Shift, count.Count, count.Minimum)
+			}
+			if off && rules.MaxOnLeave > 0 && len(count.OnLeave) > rules.MaxOnLeave {
+				day.Level = ImpactBlock
+				day.Message = fmt.Sprintf("%d would be on leave from %s %s%s, "+
+					"more than the %d allowed", len(count.OnLeave), count.Workcenter,
+					count.Shift, count.Position, rules.MaxOnLeave)
+			}
+			if others := len(count.OnLeave) + len(count.Partial) - 1; day.Level ==
+				ImpactNone && others > 0 {
+				day.Message = fmt.Sprintf("%d others on leave from %s %s%s",
+					others, count.Workcenter, count.Shift, count.Position)
+			}
+			if day.Level == ImpactNone && day.Message == "" {
+				continue
+			}
+			if day.Level == ImpactBlock ||
+				(day.Level == ImpactWarning && answer.Level == ImpactNone) {
+				answer.Level = day.Level
+			}
+			answer.Days = append(answer.Days, day)
+		}
+	}
+	return answer
+}
+
+// impactLeaveDays provides the request's leave days as taken leave.
+func impactLeaveDays(lr employees.LeaveRequest) []employees.LeaveDay {
+	var answer []employees.LeaveDay
+	for _, day := range lr.RequestedDays {
+		if day.Code != "" && day.Hours > 0.0 {
+			day.Status = employees.LeaveApproved
+			day.RequestID = lr.ID
+			answer = append(answer, day)
+		}
+	}
+	return answer
+}
@@ -0,0 +1,145 @@
+package sites
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/erneap/models/v2/employees"
+)
+
+func TestSiteGetLeaveImpact(t *testing.T) {
+	monday := date(2024, time.June, 3)
+	leave := employees.LeaveDay{LeaveDate: monday, Code: "V", Hours: 8}
+	request := func(id, code, status string) employees.LeaveRequest {
+		return employees.LeaveRequest{
+			ID:            id,
+			PrimaryCode:   code,
+			Status:        status,
+			StartDate:     monday,
+			EndDate:       monday,
+			RequestedDays: []employees.LeaveDay{leave},
+		}
+	}
+	// the other day shift employee's leave on the Monday.
+	approved := leave
+	approved.ID = 1
+	approved.Status = employees.LeaveApproved
+	approved.RequestID = "b"
+
+	tests := []struct {
+		name    string
+		minimum uint
+		rules   *LeaveImpactRules
+		code    string
+		// the other day shift employee's leave, requested or approved.
+		requested bool
+		approved  bool
+		level     string
+		days      int
+		// the other's leave is listed as requested.
+		listed bool
+	}{
+		{
+			name:    "covered",
+			minimum: 1,
+		},
+		{
+			name:    "below the minimum",
+			minimum: 2,
+			level:   ImpactWarning,
+			days:    1,
+		},
+		{
+			name:    "blocked below the minimum",
+			minimum: 2,
+			rules:   &LeaveImpactRules{BlockBelowMinimum: true},
+			level:   ImpactBlock,
+			days:    1,
+		},
+		{
+			name:    "near the minimum",
+			minimum: 1,
+			rules:   &LeaveImpactRules{WarnBuffer: 1},
+			level:   ImpactWarning,
+			days:    1,
+		},
+		{
+			name:      "others' requested leave counted",
+			minimum:   1,
+			requested: true,
+			level:     ImpactWarning,
+			days:      1,
+			listed:    true,
+		},
+		{
+			name:      "others' requested leave not counted",
+			minimum:   1,
+			rules:     &LeaveImpactRules{},
+			requested: true,
+		},
+		{
+			name:     "too many on leave",
+			rules:    &LeaveImpactRules{MaxOnLeave: 1},
+			approved: true,
+			level:    ImpactBlock,
+			days:     1,
+		},
+		{
+			name:     "others on leave",
+			approved: true,
+			days:     1,
+		},
+		{
+			name:    "modified time",
+			minimum: 2,
+			code:    "mod",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := testEmployee("s", "A")
+			b := testEmployee("s", "B")
+			if tt.requested {
+				b.Requests = append(b.Requests,
+					request("b", "V", employees.LeaveRequested))
+			}
+			if tt.approved {
+				b.Leaves = append(b.Leaves, approved)
+			}
+			site := testSite(tt.minimum, "")
+			site.LeaveImpact = tt.rules
+			code := tt.code
+			if code == "" {
+				code = "V"
+			}
+			req := request("r", code, employees.LeaveDraft)
+
+			got := site.GetLeaveImpact(&a, &req, []employees.Employee{a, b})
+			if got.RequestID != "r" || got.Level != tt.level ||
+				len(got.Days) != tt.days {
+				t.Fatalf("impact = %+v, want %q with %d days", got, tt.level,
+					tt.days)
+			}
+			if got.IsBlocked() != (tt.level == ImpactBlock) {
+				t.Errorf("blocked = %v", got.IsBlocked())
+			}
+			for _, day := range got.Days {
+				if !day.Date.Equal(monday) || day.Shift != "day" ||
+					day.Message == "" {
+					t.Errorf("day = %+v", day)
+				}
+				if (len(day.Requested) == 1 && day.Requested[0] == b.ID.Hex()) !=
+					tt.listed || len(day.Requested) > 1 {
+					t.Errorf("requested = %v", day.Requested)
+				}
+			}
+			if got.IsBlocked() {
+				err := error(&LeaveBlockedError{Impact: got})
+				if !errors.Is(err, ErrLeaveBlocked) || err.Error() == "" {
+					t.Errorf("blocked error = %v", err)
+				}
+			}
+		})
+	}
+}
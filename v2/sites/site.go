@@ -1,20 +1,29 @@
 package sites
 
 import (
+	"fmt"
+	"time"
+	// the zone database is embedded so sites' time zones load on servers
+	// without one.
+	_ "time/tzdata"
+
 	"github.com/erneap/models/v2/employees"
 	"github.com/erneap/models/v2/labor"
 )
 
 type Site struct {
-	ID              string               `json:"id" bson:"id"`
-	Name            string               `json:"name" bson:"name"`
-	UtcOffset       float64              `json:"utcOffset" bson:"utcOffset"`
-	ShowMids        bool                 `json:"showMids" bson:"showMids"`
-	Workcenters     []Workcenter         `json:"workcenters,omitempty" bson:"workcenters,omitempty"`
-	LaborCodes      []labor.LaborCode    `json:"laborCodes,omitempty" bson:"laborCodes,omitempty"`
-	ForecastReports []ForecastReport     `json:"forecasts,omitempty" bson:"forecasts,omitempty"`
-	CofSReports     []CofSReport         `json:"cofs,omitempty" bson:"cofs,omitempty"`
-	Employees       []employees.Employee `json:"employees,omitempty" bson:"-"`
+	ID              string                   `json:"id" bson:"id"`
+	Name            string                   `json:"name" bson:"name"`
+	TimeZone        string                   `json:"timeZone,omitempty" bson:"timeZone,omitempty"`
+	UtcOffset       float64                  `json:"utcOffset" bson:"utcOffset"`
+	ShowMids        bool                     `json:"showMids" bson:"showMids"`
+	Workcenters     []Workcenter             `json:"workcenters,omitempty" bson:"workcenters,omitempty"`
+	LaborCodes      []labor.LaborCode        `json:"laborCodes,omitempty" bson:"laborCodes,omitempty"`
+	ForecastReports []ForecastReport         `json:"forecasts,omitempty" bson:"forecasts,omitempty"`
+	CofSReports     []CofSReport             `json:"cofs,omitempty" bson:"cofs,omitempty"`
+	Approvals       *employees.ApprovalChain `json:"approvals,omitempty" bson:"approvals,omitempty"`
+	LeaveImpact     *LeaveImpactRules        `json:"leaveImpact,omitempty" bson:"leaveImpact,omitempty"`
+	Employees       []employees.Employee     `json:"employees,omitempty" bson:"-"`
 }
 
 type BySites []Site
@@ -24,3 +33,49 @@ func (c BySites) Less(i, j int) bool {
 	return c[i].Name < c[j].Name
 }
 func (c BySites) Swap(i, j int) { c[i], c[j] = c[j], c[i] }
+
+// GetLocation provides the location of the site's time zone (an IANA name like
+// "America/Chicago").  A site without a time zone, or with one that can't be
+// loaded, uses a fixed zone at its UTC offset, which doesn't follow daylight
+// saving time.
+func (s *Site) GetLocation() *time.Location {
+	if s.TimeZone != "" {
+		if loc, err := time.LoadLocation(s.TimeZone); err == nil {
+			return loc
+		}
+	}
+	if s.UtcOffset == 0.0 {
+		return time.UTC
+	}
+	return time.FixedZone(OffsetZone(s.UtcOffset),
+		int(s.UtcOffset*float64(time.Hour/time.Second)))
+}
+
+// SetTimeZone sets the site's time zone, and its UTC offset to the zone's
+// current offset for the clients still using it.
+func (s *Site) SetTimeZone(zone string) error {
+	loc, err := time.LoadLocation(zone)
+	if err != nil {
+		return err
+	}
+	s.TimeZone = loc.String()
+	_, offset := time.Now().In(loc).Zone()
+	s.UtcOffset = float64(offset) / float64(time.Hour/time.Second)
+	return nil
+}
+
+// OffsetZone provides the IANA zone for a UTC offset in whole hours (whose
+// "Etc/GMT" names have the sign reversed), or a "UTC+5.5" style name for an
+// offset without one.
+func OffsetZone(offset float64) string {
+	hours := int(offset)
+	switch {
+	case offset == 0.0:
+		return "UTC"
+	case float64(hours) == offset && hours >= -12 && hours <= 14:
+		return fmt.Sprintf("Etc/GMT%+d", -hours)
+	case offset > 0.0:
+		return fmt.Sprintf("UTC+%0.1f", offset)
+	}
+	return fmt.Sprintf("UTC%0.1f", offset)
+}
@@ -0,0 +1,140 @@
+package svcs
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/erneap/models/v2/employees"
+	"github.com/erneap/models/v2/labor"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// GetLeaveBalance provides the employee's month by month balance of the leave
+// code for the year, by the accrual policy of the employee's company.
+func GetLeaveBalance(id, code string, year int) (*employees.LeaveBalance,
+	error) {
+	return GetLeaveBalanceContext(context.Background(), id, code, year)
+}
+
+func GetLeaveBalanceContext(ctx context.Context, id, code string,
+	year int) (*employees.LeaveBalance, error) {
+	ctx, cancel := readContext(ctx)
+	defer cancel()
+	oEmpID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, err
+	}
+	emp, err := store.FindEmployee(ctx, oEmpID)
+	if err != nil {
+		return nil, err
+	}
+	policy, err := accrualPolicy(ctx, emp, code)
+	if err != nil {
+		return nil, err
+	}
+	bal := emp.GetLeaveBalance(*policy, year)
+	return &bal, nil
+}
+
+// ProjectLeaveBalance provides the employee's month by month projected
+// balance of the leave code through the end of the year, as of the date, by
+// the accrual policy of the employee's company.
+func ProjectLeaveBalance(id, code string, year int,
+	asOf time.Time) (*employees.LeaveProjection, error) {
+	return ProjectLeaveBalanceContext(context.Background(), id, code, year,
+		asOf)
+}
+
+func ProjectLeaveBalanceContext(ctx context.Context, id, code string,
+	year int, asOf time.Time) (*employees.LeaveProjection, error) {
+	ctx, cancel := readContext(ctx)
+	defer cancel()
+	oEmpID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, err
+	}
+	emp, err := store.FindEmployee(ctx, oEmpID)
+	if err != nil {
+		return nil, err
+	}
+	policy, err := accrualPolicy(ctx, emp, code)
+	if err != nil {
+		return nil, err
+	}
+	proj := emp.ProjectLeaveBalanceWithPolicy(*policy, year, asOf)
+	return &proj, nil
+}
+
+// ApplyLeaveAccruals stores the year's annual leave of each of the team's
+// employees working for the company, by each of the company's accrual
+// policies (or the default vacation policy), providing the number of
+// employees changed.  The changes are audited by the system when the context
+// has no actor.
+func ApplyLeaveAccruals(teamid, companyid string, year int) (int, error) {
+	return ApplyLeaveAccrualsContext(context.Background(), teamid, companyid,
+		year)
+}
+
+func ApplyLeaveAccrualsContext(ctx context.Context, teamid, companyid string,
+	year int) (int, error) {
+	ctx, cancel := writeContext(ctx)
+	defer cancel()
+	if GetActor(ctx) == "" {
+		ctx = WithActor(ctx, SystemActor)
+	}
+	team, err := GetTeamContext(ctx, teamid)
+	if err != nil {
+		return 0, err
+	}
+	co := companyOf(team, companyid)
+	if co == nil {
+		return 0, errors.New("company not found")
+	}
+	policies := co.Accruals
+	if len(policies) == 0 {
+		policies = []labor.AccrualPolicy{labor.DefaultAccrualPolicy()}
+	}
+	emps, err := store.ListEmployees(ctx, team.ID, "")
+	if err != nil {
+		return 0, err
+	}
+	count := 0
+	for _, emp := range emps {
+		if !strings.EqualFold(emp.CompanyInfo.Company, co.ID) {
+			continue
+		}
+		_, err := ModifyEmployeeContext(ctx, emp.ID.Hex(),
+			func(emp *employees.Employee) error {
+				for _, policy := range policies {
+					emp.ApplyAccrualPolicy(policy, year)
+				}
+				return nil
+			})
+		if err != nil {
+			return count, err
+		}
+		count++
+	}
+	return count, nil
+}
+
+// accrualPolicy provides the policy of the employee's company for the leave
+// code, or the default vacation policy for an employee without a company.
+func accrualPolicy(ctx context.Context, emp *employees.Employee,
+	code string) (*labor.AccrualPolicy, error) {
+	team, err := GetTeamContext(ctx, emp.TeamID.Hex())
+	if err != nil {
+		return nil, err
+	}
+	if co := companyOf(team, emp.CompanyInfo.Company); co != nil {
+		if policy := co.GetAccrualPolicy(code); policy != nil {
+			return policy, nil
+		}
+	} else if strings.EqualFold(code, "V") {
+		policy := labor.DefaultAccrualPolicy()
+		return &policy, nil
+	}
+	return nil, errors.New("no accrual policy for leave code " + code)
+}
@@ -0,0 +1,171 @@
+package svcs
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/erneap/models/v2/employees"
+	"github.com/erneap/models/v2/sites"
+	"github.com/erneap/models/v2/teams"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// SubmitLeaveRequest submits the employee's leave request for approval,
+// giving it the approval steps of its site's (or team's) approval chain.  A
+// request the site's staffing rules block gives a LeaveBlockedError.
+func SubmitLeaveRequest(id, request, actor string) (string,
+	*employees.LeaveRequest, error) {
+	return SubmitLeaveRequestContext(context.Background(), id, request, actor)
+}
+
+func SubmitLeaveRequestContext(ctx context.Context, id, request,
+	actor string) (string, *employees.LeaveRequest, error) {
+	ctx, cancel := writeContext(ctx)
+	defer cancel()
+	team, emp, loc, err := leaveRequestTeam(ctx, id)
+	if err != nil {
+		return "", nil, err
+	}
+	impact, err := leaveImpact(ctx, team, emp, request)
+	if err != nil {
+		return "", nil, err
+	}
+	if impact.IsBlocked() {
+		return "", nil, &sites.LeaveBlockedError{Impact: impact}
+	}
+	message := ""
+	var answer *employees.LeaveRequest
+	_, err = ModifyEmployeeAsContext(ctx, id, actor,
+		func(emp *employees.Employee) error {
+			msg, req, err := emp.UpdateLeaveRequestWithActor(request, "requested",
+				"", actor, loc)
+			if err != nil {
+				return err
+			}
+			req.RouteApprovals(team.GetApprovalChain(emp.SiteID))
+			for r := range emp.Requests {
+				if emp.Requests[r].ID == req.ID {
+					emp.Requests[r] = *req
+				}
+			}
+			message, answer = msg, req
+			return nil
+		})
+	if err != nil {
+		return "", nil, err
+	}
+	return message, answer, nil
+}
+
+// ApproveLeaveRequest records the approver's approval of the next step of the
+// employee's leave request, as one of the step's approvers or their delegate.
+// The request is approved once all its steps are, or by a single approval
+// when its site and team have no approval chain.  A request the site's
+// staffing rules block gives a LeaveBlockedError.
+func ApproveLeaveRequest(id, request, approver string) (string,
+	*employees.LeaveRequest, error) {
+	return ApproveLeaveRequestContext(context.Background(), id, request,
+		approver)
+}
+
+func ApproveLeaveRequestContext(ctx context.Context, id, request,
+	approver string) (string, *employees.LeaveRequest, error) {
+	ctx, cancel := writeContext(ctx)
+	defer cancel()
+	team, emp, loc, err := leaveRequestTeam(ctx, id)
+	if err != nil {
+		return "", nil, err
+	}
+	impact, err := leaveImpact(ctx, team, emp, request)
+	if err != nil {
+		return "", nil, err
+	}
+	if impact.IsBlocked() {
+		return "", nil, &sites.LeaveBlockedError{Impact: impact}
+	}
+	message := ""
+	var answer *employees.LeaveRequest
+	_, err = ModifyEmployeeAsContext(ctx, id, approver,
+		func(emp *employees.Employee) error {
+			// the request's days may have changed since it was routed.
+			for r, req := range emp.Requests {
+				if req.ID == request &&
+					req.GetStatus() == employees.LeaveRequested {
+					req.RouteApprovals(team.GetApprovalChain(emp.SiteID))
+					emp.Requests[r] = req
+				}
+			}
+			msg, req, err := emp.ApproveLeaveStep(request, approver,
+				team.Delegations, loc, team.Workcodes)
+			if err != nil {
+				return err
+			}
+			message, answer = msg, req
+			return nil
+		})
+	if err != nil {
+		return "", nil, err
+	}
+	return message, answer, nil
+}
+
+// DelegateApprovals lets the delegate approve leave requests for the approver
+// from start through end.
+func DelegateApprovals(teamid, approver, delegate string, start,
+	end time.Time) (*teams.Team, error) {
+	return DelegateApprovalsContext(context.Background(), teamid, approver,
+		delegate, start, end)
+}
+
+func DelegateApprovalsContext(ctx context.Context, teamid, approver,
+	delegate string, start, end time.Time) (*teams.Team, error) {
+	ctx, cancel := writeContext(ctx)
+	defer cancel()
+	return ModifyTeamContext(ctx, teamid, func(team *teams.Team) error {
+		team.SetDelegation(approver, delegate, start, end)
+		return nil
+	})
+}
+
+// RemoveApprovalDelegation removes the approver's delegation to the delegate.
+func RemoveApprovalDelegation(teamid, approver,
+	delegate string) (*teams.Team, error) {
+	return RemoveApprovalDelegationContext(context.Background(), teamid,
+		approver, delegate)
+}
+
+func RemoveApprovalDelegationContext(ctx context.Context, teamid, approver,
+	delegate string) (*teams.Team, error) {
+	ctx, cancel := writeContext(ctx)
+	defer cancel()
+	return ModifyTeamContext(ctx, teamid, func(team *teams.Team) error {
+		team.RemoveDelegation(approver, delegate)
+		return nil
+	})
+}
+
+// leaveRequestTeam provides the employee's team, the employee and the
+// location of the employee's site, or UTC when the site isn't found.
+func leaveRequestTeam(ctx context.Context, id string) (*teams.Team,
+	*employees.Employee, *time.Location, error) {
+	oEmpID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	emp, err := store.FindEmployee(ctx, oEmpID)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	team, err := GetTeamContext(ctx, emp.TeamID.Hex())
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	loc := time.UTC
+	for _, site := range team.Sites {
+		if strings.EqualFold(site.ID, emp.SiteID) {
+			loc = site.GetLocation()
+		}
+	}
+	return team, emp, loc, nil
+}
@@ -0,0 +1,63 @@
+package svcs
+
+import (
+	"testing"
+	"time"
+
+	"github.com/erneap/models/v2/employees"
+)
+
+func TestApproveLeaveRequestRoutesRequests(t *testing.T) {
+	SetStore(NewMemoryStore())
+	team := CreateTeam("approvals", false)
+	team.Approvals = &employees.ApprovalChain{
+		Levels: []employees.ApprovalLevel{
+			{Level: employees.ApproverSupervisor, Approvers: []string{"boss"}},
+			{Level: employees.ApproverSiteLead, Approvers: []string{"lead"}},
+		},
+	}
+	if err := UpdateTeam(team); err != nil {
+		t.Fatal(err)
+	}
+	emp := employees.Employee{
+		Name: employees.EmployeeName{FirstName: "A", LastName: "R"},
+	}
+	emp.AddAssignment("s", "w", time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+	e, err := CreateEmployee(emp, "pw", "", team.ID.Hex(), "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the request is requested without being submitted through the chain.
+	var request string
+	_, err = ModifyEmployeeAs(e.ID.Hex(), "e",
+		func(emp *employees.Employee) error {
+			req := emp.NewLeaveRequestAt(emp.ID.Hex(), "V",
+				time.Date(2024, 6, 3, 0, 0, 0, 0, time.UTC),
+				time.Date(2024, 6, 4, 0, 0, 0, 0, time.UTC), nil, "")
+			request = req.ID
+			_, _, err := emp.UpdateLeaveRequestWithActor(req.ID, "requested", "",
+				"e", nil)
+			return err
+		})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		approver string
+		status   string
+	}{
+		{approver: "boss", status: employees.LeaveRequested},
+		{approver: "lead", status: employees.LeaveApproved},
+	}
+	for _, tt := range tests {
+		_, req, err := ApproveLeaveRequest(e.ID.Hex(), request, tt.approver)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if req.Status != tt.status || len(req.Approvals) != 2 {
+			t.Errorf("after %s's approval: %+v", tt.approver, req)
+		}
+	}
+}
@@ -0,0 +1,139 @@
+package svcs
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/erneap/models/v2/employees"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// Every employee update records an audit entry for each field it changes,
+// with the actor given to UpdateEmployeeAs or ModifyEmployeeAs, or taken
+// from the update's context.  A web handler names the actor with something
+// like:
+//
+//	err := svcs.UpdateEmployeeAs(emp, svcs.GetRequestor(c))
+//
+// or, for the functions without an actor:
+//
+//	ctx := svcs.WithActor(c.Request.Context(), svcs.GetRequestor(c))
+//	err := svcs.UpdateEmployeeContext(ctx, emp)
+
+// SystemActor is the actor of the changes the scheduler makes on its own, like
+// the purges and retention, when the caller gives no actor.
+const SystemActor = "system"
+
+type actorKey struct{}
+
+// WithActor provides a context that names who is making the changes.
+func WithActor(ctx context.Context, actor string) context.Context {
+	return context.WithValue(ctx, actorKey{}, actor)
+}
+
+// GetActor provides the actor given to WithActor, or blank if none was given.
+func GetActor(ctx context.Context) string {
+	actor, _ := ctx.Value(actorKey{}).(string)
+	return actor
+}
+
+// replaceEmployee stores the employee and its audit entries together.
+func replaceEmployee(ctx context.Context, emp *employees.Employee) error {
+	version := emp.Version
+	return WithTransaction(ctx, func(ctx context.Context) error {
+		emp.Version = version
+		prev, err := store.FindEmployee(ctx, emp.ID)
+		if err != nil && err != mongo.ErrNoDocuments {
+			return err
+		}
+		if err = checkEmployee(emp, prev); err != nil {
+			return err
+		}
+		if err = store.ReplaceEmployee(ctx, emp); err != nil {
+			return err
+		}
+		if prev == nil {
+			return nil
+		}
+
+		now := time.Now().UTC()
+		actor := GetActor(ctx)
+		entries := emp.GetChanges(prev)
+		for i := range entries {
+			entries[i].ID = primitive.NewObjectID()
+			entries[i].EmployeeID = emp.ID
+			entries[i].Actor = actor
+			entries[i].Date = now
+		}
+		return store.InsertAuditEntries(ctx, entries)
+	})
+}
+
+// auditRemoval records the employee's removal.
+func auditRemoval(ctx context.Context, emp *employees.Employee) error {
+	entry := emp.GetRemoval()
+	entry.ID = primitive.NewObjectID()
+	entry.EmployeeID = emp.ID
+	entry.Actor = GetActor(ctx)
+	entry.Date = time.Now().UTC()
+	return store.InsertAuditEntries(ctx, []employees.AuditEntry{entry})
+}
+
+func GetAuditEntriesByEmployee(id string) ([]employees.AuditEntry, error) {
+	return GetAuditEntriesByEmployeeContext(context.Background(), id)
+}
+
+func GetAuditEntriesByEmployeeContext(ctx context.Context,
+	id string) ([]employees.AuditEntry, error) {
+	return GetAuditEntriesContext(ctx, id, "", time.Time{}, time.Time{})
+}
+
+func GetAuditEntriesByActor(actor string) ([]employees.AuditEntry, error) {
+	return GetAuditEntriesByActorContext(context.Background(), actor)
+}
+
+func GetAuditEntriesByActorContext(ctx context.Context,
+	actor string) ([]employees.AuditEntry, error) {
+	return GetAuditEntriesContext(ctx, "", actor, time.Time{}, time.Time{})
+}
+
+func GetAuditEntriesBetweenDates(start,
+	end time.Time) ([]employees.AuditEntry, error) {
+	return GetAuditEntriesBetweenDatesContext(context.Background(), start, end)
+}
+
+func GetAuditEntriesBetweenDatesContext(ctx context.Context, start,
+	end time.Time) ([]employees.AuditEntry, error) {
+	return GetAuditEntriesContext(ctx, "", "", start, end)
+}
+
+// GetAuditEntries selects the audit entries by employee, actor and date range
+// (inclusive), a blank employee id or actor or a zero date isn't used in the
+// selection.
+func GetAuditEntries(empID, actor string, start,
+	end time.Time) ([]employees.AuditEntry, error) {
+	return GetAuditEntriesContext(context.Background(), empID, actor, start, end)
+}
+
+func GetAuditEntriesContext(ctx context.Context, empID, actor string, start,
+	end time.Time) ([]employees.AuditEntry, error) {
+	ctx, cancel := readContext(ctx)
+	defer cancel()
+	oEmpID := primitive.NilObjectID
+	if empID != "" {
+		var err error
+		oEmpID, err = primitive.ObjectIDFromHex(empID)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	entries, err := store.ListAuditEntries(ctx, oEmpID, actor, start, end)
+	if err != nil {
+		return entries, err
+	}
+	sort.Sort(employees.ByAuditEntry(entries))
+	return entries, nil
+}
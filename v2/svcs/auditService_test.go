@@ -0,0 +1,117 @@
+package svcs
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/erneap/models/v2/employees"
+)
+
+func TestUpdateEmployeeAudit(t *testing.T) {
+	SetStore(NewMemoryStore())
+	team := CreateTeam("audit", false)
+	emp := employees.Employee{
+		Name: employees.EmployeeName{FirstName: "A", LastName: "U"},
+	}
+	emp.AddAssignment("s", "w", time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC))
+	e, err := CreateEmployee(emp, "pw", "", team.ID.Hex(), "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	e.Email = "new@x"
+	e.Assignments[0].Workcenter = "w2"
+	ctx := WithActor(context.Background(), "boss")
+	if err := UpdateEmployeeContext(ctx, e); err != nil {
+		t.Fatal(err)
+	}
+
+	entries, err := GetAuditEntriesByEmployee(e.ID.Hex())
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]bool{
+		"assignments[id=1].workcenter": true,
+		"email":                        true,
+	}
+	if len(entries) != len(want) {
+		t.Fatalf("entries = %+v", entries)
+	}
+	for _, entry := range entries {
+		if !want[entry.Path] || entry.Actor != "boss" ||
+			entry.EmployeeID != e.ID || entry.Date.IsZero() {
+			t.Errorf("entry = %+v", entry)
+		}
+	}
+	byActor, err := GetAuditEntriesByActor("boss")
+	if err != nil || len(byActor) != len(want) {
+		t.Errorf("entries by actor = %+v, %v", byActor, err)
+	}
+	byOther, err := GetAuditEntriesByActor("other")
+	if err != nil || len(byOther) != 0 {
+		t.Errorf("entries by another actor = %+v, %v", byOther, err)
+	}
+}
+
+func TestUpdateEmployeeAsAudit(t *testing.T) {
+	SetStore(NewMemoryStore())
+	team := CreateTeam("audit", false)
+	emp := employees.Employee{
+		Name: employees.EmployeeName{FirstName: "A", LastName: "S"},
+	}
+	emp.AddAssignment("s", "w", time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC))
+	e, err := CreateEmployee(emp, "pw", "", team.ID.Hex(), "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the actor given is used over the context's.
+	ctx := WithActor(context.Background(), "other")
+	tests := []struct {
+		name   string
+		actor  string
+		update func(actor string) error
+	}{
+		{
+			name:  "update",
+			actor: "boss",
+			update: func(actor string) error {
+				e.Email = "as@x"
+				return UpdateEmployeeAsContext(ctx, e, actor)
+			},
+		},
+		{
+			name:  "modify",
+			actor: "employee",
+			update: func(actor string) error {
+				_, err := ModifyEmployeeAsContext(ctx, e.ID.Hex(), actor,
+					func(emp *employees.Employee) error {
+						emp.NewLeaveRequestAt(emp.ID.Hex(), "V",
+							time.Date(2024, 6, 3, 0, 0, 0, 0, time.UTC),
+							time.Date(2024, 6, 4, 0, 0, 0, 0, time.UTC), nil, "")
+						return nil
+					})
+				return err
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.update(tt.actor); err != nil {
+				t.Fatal(err)
+			}
+			entries, err := GetAuditEntriesByActor(tt.actor)
+			if err != nil || len(entries) == 0 {
+				t.Fatalf("entries = %+v, %v", entries, err)
+			}
+			for _, entry := range entries {
+				if entry.EmployeeID != e.ID {
+					t.Errorf("entry = %+v", entry)
+				}
+			}
+		})
+	}
+	if others, err := GetAuditEntriesByActor("other"); err != nil ||
+		len(others) != 0 {
+		t.Errorf("entries by the context's actor = %+v, %v", others, err)
+	}
+}
@@ -0,0 +1,128 @@
+package svcs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/erneap/models/v2/employees"
+	"github.com/erneap/models/v2/teams"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Employees and teams (with their sites) carry a version that every update
+// checks, so two users editing the same document can't silently overwrite
+// each other's changes.  The update that loses gets a ConflictError, and can
+// either reload the document and show it to the user again, or use
+// ModifyEmployee or ModifyTeam to re-apply its change to the latest version.
+
+// ErrConflict is matched (with errors.Is) by every ConflictError.
+var ErrConflict = errors.New("document was changed by another update")
+
+// ConflictError tells which document couldn't be updated because it was
+// changed after the version given was read.
+type ConflictError struct {
+	Collection string
+	ID         primitive.ObjectID
+	Version    int64
+}
+
+func (e *ConflictError) Error() string {
+	return fmt.Sprintf("%s %s version %d: %s", e.Collection, e.ID.Hex(),
+		e.Version, ErrConflict.Error())
+}
+
+func (e *ConflictError) Is(target error) bool {
+	return target == ErrConflict
+}
+
+// MaxModifyAttempts is the number of times ModifyEmployee and ModifyTeam apply
+// their change before giving up on conflicts.
+var MaxModifyAttempts = 5
+
+// ModifyEmployee applies the change to the latest version of the employee and
+// stores it.  When another update stores the employee first, the employee is
+// read again and the change re-applied, so the change merges with the other
+// update instead of replacing it.  This is how the leave and assignment
+// changes (like AddLeave, NewLeaveRequest, AddAssignment) should be stored.
+// The change may be called more than once, and returning an error from it
+// stops the update.
+func ModifyEmployee(id string,
+	change func(emp *employees.Employee) error) (*employees.Employee, error) {
+	return ModifyEmployeeContext(context.Background(), id, change)
+}
+
+func ModifyEmployeeContext(ctx context.Context, id string,
+	change func(emp *employees.Employee) error) (*employees.Employee, error) {
+	ctx, cancel := writeContext(ctx)
+	defer cancel()
+	oEmpID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, err
+	}
+
+	for attempt := 0; attempt < MaxModifyAttempts; attempt++ {
+		emp, err := store.FindEmployee(ctx, oEmpID)
+		if err != nil {
+			return nil, err
+		}
+		if err = change(emp); err != nil {
+			return nil, err
+		}
+		err = replaceEmployee(ctx, emp)
+		if err == nil {
+			return emp, nil
+		} else if !errors.Is(err, ErrConflict) {
+			return nil, err
+		}
+	}
+	return nil, &ConflictError{Collection: "employees", ID: oEmpID}
+}
+
+// ModifyEmployeeAs applies and stores the change like ModifyEmployee, with the
+// actor recorded in the employee's audit entries.
+func ModifyEmployeeAs(id, actor string,
+	change func(emp *employees.Employee) error) (*employees.Employee, error) {
+	return ModifyEmployeeAsContext(context.Background(), id, actor, change)
+}
+
+func ModifyEmployeeAsContext(ctx context.Context, id, actor string,
+	change func(emp *employees.Employee) error) (*employees.Employee, error) {
+	return ModifyEmployeeContext(WithActor(ctx, actor), id, change)
+}
+
+// ModifyTeam applies the change to the latest version of the team and stores
+// it, re-applying the change when another update stores the team first, just
+// like ModifyEmployee.  The site functions use it since the sites are stored
+// with their team.
+func ModifyTeam(id string,
+	change func(team *teams.Team) error) (*teams.Team, error) {
+	return ModifyTeamContext(context.Background(), id, change)
+}
+
+func ModifyTeamContext(ctx context.Context, id string,
+	change func(team *teams.Team) error) (*teams.Team, error) {
+	ctx, cancel := writeContext(ctx)
+	defer cancel()
+	teamid, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, err
+	}
+
+	for attempt := 0; attempt < MaxModifyAttempts; attempt++ {
+		team, err := store.FindTeam(ctx, teamid)
+		if err != nil {
+			return nil, err
+		}
+		if err = change(team); err != nil {
+			return nil, err
+		}
+		err = store.ReplaceTeam(ctx, team)
+		if err == nil {
+			return team, nil
+		} else if !errors.Is(err, ErrConflict) {
+			return nil, err
+		}
+	}
+	return nil, &ConflictError{Collection: "teams", ID: teamid}
+}
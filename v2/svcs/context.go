@@ -0,0 +1,40 @@
+package svcs
+
+import (
+	"context"
+	"time"
+)
+
+// Each service function has a Context variant that takes a context.Context as
+// its first argument, so a caller (like a web request handler) can cancel the
+// work or give it a deadline.  The functions without a context use
+// context.Background().  When the context given has no deadline, the default
+// read or write timeout below is applied, so no database call can hang
+// forever.
+
+// DefaultReadTimeout limits the service functions that only read data when the
+// caller's context has no deadline.
+var DefaultReadTimeout = 30 * time.Second
+
+// DefaultWriteTimeout limits the service functions that change data when the
+// caller's context has no deadline.
+var DefaultWriteTimeout = 30 * time.Second
+
+func withDefaultTimeout(ctx context.Context,
+	timeout time.Duration) (context.Context, context.CancelFunc) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	if _, ok := ctx.Deadline(); ok || timeout <= 0 {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeout(ctx, timeout)
+}
+
+func readContext(ctx context.Context) (context.Context, context.CancelFunc) {
+	return withDefaultTimeout(ctx, DefaultReadTimeout)
+}
+
+func writeContext(ctx context.Context) (context.Context, context.CancelFunc) {
+	return withDefaultTimeout(ctx, DefaultWriteTimeout)
+}
@@ -8,13 +8,16 @@ import (
 	"github.com/erneap/models/v2/config"
 	"github.com/erneap/models/v2/general"
 	"github.com/gin-gonic/gin"
-	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
 func CreateDBLogEntryWithDate(dt time.Time, app, cat, title, name, msg string) (*general.LogEntry, error) {
-	logCol := config.GetCollection(config.DB, "general", "logs")
+	return CreateDBLogEntryWithDateContext(context.Background(), dt, app, cat, title, name, msg)
+}
 
+func CreateDBLogEntryWithDateContext(ctx context.Context, dt time.Time, app, cat, title, name, msg string) (*general.LogEntry, error) {
+	ctx, cancel := writeContext(ctx)
+	defer cancel()
 	// new log entry
 	entry := &general.LogEntry{
 		ID:          primitive.NewObjectID(),
@@ -26,7 +29,7 @@ func CreateDBLogEntryWithDate(dt time.Time, app, cat, title, name, msg string) (
 		Message:     msg,
 	}
 
-	_, err := logCol.InsertOne(context.TODO(), entry)
+	err := store.InsertLogEntry(ctx, entry)
 	if err != nil {
 		return nil, err
 	}
@@ -35,12 +38,16 @@ func CreateDBLogEntryWithDate(dt time.Time, app, cat, title, name, msg string) (
 
 // CRUD Methods for this data collection
 func CreateDBLogEntry(app, cat, title, name, msg string, c *gin.Context) (*general.LogEntry, error) {
-	logCol := config.GetCollection(config.DB, "general", "logs")
+	return CreateDBLogEntryContext(context.Background(), app, cat, title, name, msg, c)
+}
 
+func CreateDBLogEntryContext(ctx context.Context, app, cat, title, name, msg string, c *gin.Context) (*general.LogEntry, error) {
+	ctx, cancel := writeContext(ctx)
+	defer cancel()
 	if name == "" && c != nil {
 		userid := GetRequestor(c)
 		if userid != "" {
-			user, _ := GetUserByID(userid)
+			user, _ := GetUserByIDContext(ctx, userid)
 			if user != nil {
 				name = user.LastName
 			}
@@ -58,7 +65,7 @@ func CreateDBLogEntry(app, cat, title, name, msg string, c *gin.Context) (*gener
 		Message:     msg,
 	}
 
-	_, err := logCol.InsertOne(context.TODO(), entry)
+	err := store.InsertLogEntry(ctx, entry)
 	if err != nil {
 		return nil, err
 	}
@@ -66,19 +73,18 @@ func CreateDBLogEntry(app, cat, title, name, msg string, c *gin.Context) (*gener
 }
 
 func UpdateDBLogEntry(id, cat, title, name, msg string) (*general.LogEntry, error) {
-	logCol := config.GetCollection(config.DB, "general", "logs")
+	return UpdateDBLogEntryContext(context.Background(), id, cat, title, name, msg)
+}
 
+func UpdateDBLogEntryContext(ctx context.Context, id, cat, title, name, msg string) (*general.LogEntry, error) {
+	ctx, cancel := writeContext(ctx)
+	defer cancel()
 	oId, err := primitive.ObjectIDFromHex(id)
 	if err != nil {
 		return nil, err
 	}
 
-	filter := bson.M{
-		"_id": oId,
-	}
-
-	var entry general.LogEntry
-	err = logCol.FindOne(context.TODO(), filter).Decode(&entry)
+	entry, err := store.FindLogEntry(ctx, oId)
 	if err != nil {
 		return nil, err
 	}
@@ -94,171 +100,135 @@ func UpdateDBLogEntry(id, cat, title, name, msg string) (*general.LogEntry, erro
 	}
 	entry.Message = msg
 
-	_, err = logCol.ReplaceOne(context.TODO(), filter, &entry)
+	err = store.ReplaceLogEntry(ctx, entry)
 	if err != nil {
 		return nil, err
 	}
-	return &entry, nil
+	return entry, nil
 }
 
 func DeleteDBLogEntry(id string) error {
-	logCol := config.GetCollection(config.DB, "general", "logs")
+	return DeleteDBLogEntryContext(context.Background(), id)
+}
 
+func DeleteDBLogEntryContext(ctx context.Context, id string) error {
+	ctx, cancel := writeContext(ctx)
+	defer cancel()
 	oId, err := primitive.ObjectIDFromHex(id)
 	if err != nil {
 		return err
 	}
 
-	filter := bson.M{
-		"_id": oId,
-	}
-
-	_, err = logCol.DeleteOne(context.TODO(), filter)
+	_, err = store.DeleteLogEntry(ctx, oId)
 	return err
 }
 
+// PurgeLogs removes the log entries from before the date given, through the
+// retention engine (see ApplyRetention).
 func PurgeLogs(dt time.Time) error {
-	logCol := config.GetCollection(config.DB, "general", "logs")
-
-	filter := bson.M{
-		"entrydate": bson.M{"$lt": dt},
-	}
+	return PurgeLogsContext(context.Background(), dt)
+}
 
-	_, err := logCol.DeleteMany(context.TODO(), filter)
+func PurgeLogsContext(ctx context.Context, dt time.Time) error {
+	_, err := ApplyRetentionContext(ctx, []RetentionPolicy{
+		{Collection: config.ColLogs, Before: dt},
+	}, time.Now().UTC(), false)
 	return err
 }
 
 func GetDBLogEntry(id string) (*general.LogEntry, error) {
-	logCol := config.GetCollection(config.DB, "general", "logs")
+	return GetDBLogEntryContext(context.Background(), id)
+}
 
+func GetDBLogEntryContext(ctx context.Context, id string) (*general.LogEntry, error) {
+	ctx, cancel := readContext(ctx)
+	defer cancel()
 	oId, err := primitive.ObjectIDFromHex(id)
 	if err != nil {
 		return nil, err
 	}
 
-	filter := bson.M{
-		"_id": oId,
-	}
-
-	var entry general.LogEntry
-	err = logCol.FindOne(context.TODO(), filter).Decode(&entry)
-	if err != nil {
-		return nil, err
-	}
-	return &entry, nil
+	return store.FindLogEntry(ctx, oId)
 }
 
 func GetDBLogEntriesAll() ([]general.LogEntry, error) {
-	logCol := config.GetCollection(config.DB, "general", "logs")
-
-	filter := bson.M{}
-
-	var logs []general.LogEntry
+	return GetDBLogEntriesAllContext(context.Background())
+}
 
-	cursor, err := logCol.Find(context.TODO(), filter)
+func GetDBLogEntriesAllContext(ctx context.Context) ([]general.LogEntry, error) {
+	ctx, cancel := readContext(ctx)
+	defer cancel()
+	logs, err := store.ListLogEntries(ctx, "", "", time.Time{},
+		time.Time{})
 	if err != nil {
 		return logs, err
 	}
-
-	if err = cursor.All(context.TODO(), &logs); err != nil {
-		return logs, err
-	}
 	sort.Sort(general.ByLogEntries(logs))
 	return logs, nil
 }
 
 func GetDBLogEntriesByApplication(app string) ([]general.LogEntry, error) {
-	logCol := config.GetCollection(config.DB, "general", "logs")
-
-	filter := bson.M{
-		"application": app,
-	}
-
-	var logs []general.LogEntry
+	return GetDBLogEntriesByApplicationContext(context.Background(), app)
+}
 
-	cursor, err := logCol.Find(context.TODO(), filter)
+func GetDBLogEntriesByApplicationContext(ctx context.Context, app string) ([]general.LogEntry, error) {
+	ctx, cancel := readContext(ctx)
+	defer cancel()
+	logs, err := store.ListLogEntries(ctx, app, "", time.Time{},
+		time.Time{})
 	if err != nil {
 		return logs, err
 	}
-
-	if err = cursor.All(context.TODO(), &logs); err != nil {
-		return logs, err
-	}
 	sort.Sort(general.ByLogEntries(logs))
 	return logs, nil
 }
 
 func GetDBLogEntriesByApplicationBetweenDates(app string, dt1,
 	dt2 time.Time) ([]general.LogEntry, error) {
-	logCol := config.GetCollection(config.DB, "general", "logs")
-
-	filter := bson.M{
-		"application": app,
-		"entrydate": bson.M{
-			"$gte": dt1,
-			"$lte": dt2,
-		},
-	}
-
-	var logs []general.LogEntry
+	return GetDBLogEntriesByApplicationBetweenDatesContext(context.Background(), app, dt1, dt2)
+}
 
-	cursor, err := logCol.Find(context.TODO(), filter)
+func GetDBLogEntriesByApplicationBetweenDatesContext(ctx context.Context, app string, dt1,
+	dt2 time.Time) ([]general.LogEntry, error) {
+	ctx, cancel := readContext(ctx)
+	defer cancel()
+	logs, err := store.ListLogEntries(ctx, app, "", dt1, dt2)
 	if err != nil {
 		return logs, err
 	}
-
-	if err = cursor.All(context.TODO(), &logs); err != nil {
-		return logs, err
-	}
 	sort.Sort(general.ByLogEntries(logs))
 	return logs, nil
 }
 
 func GetDBLogEntriesByApplicationCategory(app, cat string) ([]general.LogEntry, error) {
-	logCol := config.GetCollection(config.DB, "general", "logs")
-
-	filter := bson.M{
-		"application": app,
-		"category":    cat,
-	}
-
-	var logs []general.LogEntry
+	return GetDBLogEntriesByApplicationCategoryContext(context.Background(), app, cat)
+}
 
-	cursor, err := logCol.Find(context.TODO(), filter)
+func GetDBLogEntriesByApplicationCategoryContext(ctx context.Context, app, cat string) ([]general.LogEntry, error) {
+	ctx, cancel := readContext(ctx)
+	defer cancel()
+	logs, err := store.ListLogEntries(ctx, app, cat, time.Time{},
+		time.Time{})
 	if err != nil {
 		return logs, err
 	}
-
-	if err = cursor.All(context.TODO(), &logs); err != nil {
-		return logs, err
-	}
 	sort.Sort(general.ByLogEntries(logs))
 	return logs, nil
 }
 
 func GetDBLogEntriesByApplicationCategoryBetweenDates(app, cat string, dt1,
 	dt2 time.Time) ([]general.LogEntry, error) {
-	logCol := config.GetCollection(config.DB, "general", "logs")
-
-	filter := bson.M{
-		"application": app,
-		"category":    cat,
-		"entrydate": bson.M{
-			"$gte": dt1,
-			"$lte": dt2,
-		},
-	}
-
-	var logs []general.LogEntry
+	return GetDBLogEntriesByApplicationCategoryBetweenDatesContext(context.Background(), app, cat, dt1, dt2)
+}
 
-	cursor, err := logCol.Find(context.TODO(), filter)
+func GetDBLogEntriesByApplicationCategoryBetweenDatesContext(ctx context.Context, app, cat string, dt1,
+	dt2 time.Time) ([]general.LogEntry, error) {
+	ctx, cancel := readContext(ctx)
+	defer cancel()
+	logs, err := store.ListLogEntries(ctx, app, cat, dt1, dt2)
 	if err != nil {
 		return logs, err
 	}
-
-	if err = cursor.All(context.TODO(), &logs); err != nil {
-		return logs, err
-	}
 	sort.Sort(general.ByLogEntries(logs))
 	return logs, nil
 }
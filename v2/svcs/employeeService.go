@@ -4,15 +4,12 @@ import (
 	"context"
 	"errors"
 	"fmt"
-	"log"
 	"sort"
 	"strings"
 	"time"
 
-	"github.com/erneap/models/v2/config"
 	"github.com/erneap/models/v2/employees"
 	"github.com/erneap/models/v2/users"
-	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
 )
@@ -27,94 +24,98 @@ import (
 // made to ensure their object ID is the same.
 func CreateEmployee(emp employees.Employee, passwd, workgroup, teamID,
 	siteid string) (*employees.Employee, error) {
-	userCol := config.GetCollection(config.DB, "authenticate", "users")
-	empCol := config.GetCollection(config.DB, "scheduler", "employees")
+	return CreateEmployeeContext(context.Background(), emp, passwd, workgroup, teamID, siteid)
+}
+
+func CreateEmployeeContext(ctx context.Context, emp employees.Employee, passwd, workgroup, teamID,
+	siteid string) (*employees.Employee, error) {
+	ctx, cancel := writeContext(ctx)
+	defer cancel()
 	teamid, err := primitive.ObjectIDFromHex(teamID)
 	if err != nil {
 		return nil, err
 	}
 
-	filter := bson.M{
-		"name.firstName": emp.Name.FirstName,
-		"name.lastName":  emp.Name.LastName,
-		"team":           teamid,
-	}
-
 	// first check to see of an employee already exists for this first and last
 	// name.  If present, change filter to include middle if not blank, but if
 	// middle is blank, return old employee record
-	var tEmp employees.Employee
-	err = empCol.FindOne(context.TODO(), filter).Decode(&tEmp)
+	_, err = store.FindEmployeeByName(ctx, teamid, emp.Name.FirstName,
+		emp.Name.LastName)
 	if err == nil || err != mongo.ErrNoDocuments {
 		if emp.Name.MiddleName == "" {
 			return &emp, nil
 		}
-		filter = bson.M{
-			"name.firstName":  emp.Name.FirstName,
-			"name.middleName": emp.Name.MiddleName,
-			"name.lastName":   emp.Name.LastName,
-			"team":            teamid,
-		}
 
-		err = empCol.FindOne(context.TODO(), filter).Decode(&emp)
-		if err == nil || err != mongo.ErrNoDocuments {
+		tEmp, err := store.FindEmployeeByFullName(ctx, teamid,
+			emp.Name.FirstName, emp.Name.MiddleName, emp.Name.LastName)
+		if err == nil {
+			return tEmp, nil
+		} else if err != mongo.ErrNoDocuments {
 			return &emp, nil
 		}
 	}
 
-	// check user collection for new employee
-	filter = bson.M{
-		"firstName": emp.Name.FirstName,
-		"lastName":  emp.Name.LastName,
-	}
-
-	var user users.User
-
-	err = userCol.FindOne(context.TODO(), filter).Decode(&user)
-	if err == mongo.ErrNoDocuments {
-		emp.ID = primitive.NewObjectID()
-		// create user record with provided password.
-		user = users.User{
-			ID:           emp.ID,
-			EmailAddress: emp.Email,
-			FirstName:    emp.Name.FirstName,
-			MiddleName:   emp.Name.MiddleName,
-			LastName:     emp.Name.LastName,
-			Workgroups: []string{
-				"scheduler-employee",
-			},
-		}
-		if workgroup != "" {
-			user.Workgroups = append(user.Workgroups, workgroup)
-		}
-		user.SetPassword(passwd)
-		userCol.InsertOne(context.TODO(), user)
-	} else {
-		emp.ID = user.ID
-	}
-
+	// the user and employee records are created together, so a failure can't
+	// leave a user without an employee record.
 	emp.TeamID = teamid
 	emp.SiteID = siteid
+	if len(emp.EmailAddresses) == 0 && emp.Email != "" {
+		emp.AddEmailAddress(emp.Email)
+	}
+	if err = checkEmployee(&emp, nil); err != nil {
+		return nil, err
+	}
+	err = WithTransaction(ctx, func(ctx context.Context) error {
+		// check user collection for new employee
+		user, err := store.FindUserByName(ctx, emp.Name.FirstName,
+			emp.Name.LastName)
+		if err == mongo.ErrNoDocuments {
+			emp.ID = primitive.NewObjectID()
+			// create user record with provided password.
+			user = &users.User{
+				ID:           emp.ID,
+				EmailAddress: emp.Email,
+				FirstName:    emp.Name.FirstName,
+				MiddleName:   emp.Name.MiddleName,
+				LastName:     emp.Name.LastName,
+				Workgroups: []string{
+					"scheduler-employee",
+				},
+			}
+			if workgroup != "" {
+				user.Workgroups = append(user.Workgroups, workgroup)
+			}
+			user.SetPassword(passwd)
+			if err = store.InsertUser(ctx, user); err != nil {
+				return err
+			}
+		} else if err != nil {
+			return err
+		} else {
+			emp.ID = user.ID
+		}
 
-	empCol.InsertOne(context.TODO(), emp)
+		return store.InsertEmployee(ctx, &emp)
+	})
+	if err != nil {
+		return nil, err
+	}
 	return &emp, nil
 }
 
 func GetEmployee(id string) (*employees.Employee, error) {
-	empCol := config.GetCollection(config.DB, "scheduler", "employees")
-	userCol := config.GetCollection(config.DB, "authenticate", "users")
+	return GetEmployeeContext(context.Background(), id)
+}
 
+func GetEmployeeContext(ctx context.Context, id string) (*employees.Employee, error) {
+	ctx, cancel := readContext(ctx)
+	defer cancel()
 	oEmpID, err := primitive.ObjectIDFromHex(id)
 	if err != nil {
 		return nil, err
 	}
 
-	filter := bson.M{
-		"_id": oEmpID,
-	}
-
-	var emp employees.Employee
-	err = empCol.FindOne(context.TODO(), filter).Decode(&emp)
+	emp, err := store.FindEmployee(ctx, oEmpID)
 	if err != nil {
 		fmt.Println(err.Error())
 		return nil, err
@@ -122,50 +123,45 @@ func GetEmployee(id string) (*employees.Employee, error) {
 	if len(emp.EmailAddresses) <= 0 {
 		if emp.Email != "" {
 			emp.AddEmailAddress(emp.Email)
-			UpdateEmployee(&emp)
+			UpdateEmployeeAsContext(ctx, emp, SystemActor)
 		}
 	}
-	var user users.User
-	userCol.FindOne(context.TODO(), filter).Decode(&user)
-	emp.User = &user
+	user, err := store.FindUser(ctx, oEmpID)
+	if err != nil {
+		user = &users.User{}
+	}
+	emp.User = user
 
 	// any work records for current year and previous
 	now := time.Now().UTC()
 	year1 := now.Year()
 	year2 := year1 - 1
 
-	work, err := GetEmployeeWork(id, uint(year1))
+	work, err := GetEmployeeWorkContext(ctx, id, uint(year1))
 	if err == nil {
 		emp.Work = append(emp.Work, work.Work...)
 	}
 
-	work, err = GetEmployeeWork(id, uint(year2))
+	work, err = GetEmployeeWorkContext(ctx, id, uint(year2))
 	if err == nil {
 		emp.Work = append(emp.Work, work.Work...)
 	}
-	return &emp, nil
+	return emp, nil
 }
 
 func GetEmployeeByName(first, middle, last string) (*employees.Employee, error) {
-	empCol := config.GetCollection(config.DB, "scheduler", "employees")
-	userCol := config.GetCollection(config.DB, "authenticate", "users")
-
-	filter := bson.M{
-		"name.firstname":  first,
-		"name.middlename": middle,
-		"name.lastname":   last,
-	}
+	return GetEmployeeByNameContext(context.Background(), first, middle, last)
+}
 
-	var emp employees.Employee
-	err := empCol.FindOne(context.TODO(), filter).Decode(&emp)
+func GetEmployeeByNameContext(ctx context.Context, first, middle, last string) (*employees.Employee, error) {
+	ctx, cancel := readContext(ctx)
+	defer cancel()
+	emp, err := store.FindEmployeeByFullName(ctx, primitive.NilObjectID,
+		first, middle, last)
 	if err != nil {
-		if err == mongo.ErrNoDocuments {
-			filter = bson.M{
-				"name.firstname":  first,
-				"name.middlename": middle[:1],
-				"name.lastname":   last,
-			}
-			err = empCol.FindOne(context.TODO(), filter).Decode(&emp)
+		if err == mongo.ErrNoDocuments && middle != "" {
+			emp, err = store.FindEmployeeByFullName(ctx,
+				primitive.NilObjectID, first, middle[:1], last)
 			if err != nil {
 				return nil, err
 			}
@@ -176,189 +172,273 @@ func GetEmployeeByName(first, middle, last string) (*employees.Employee, error)
 	if len(emp.EmailAddresses) <= 0 {
 		if emp.Email != "" {
 			emp.AddEmailAddress(emp.Email)
-			UpdateEmployee(&emp)
+			UpdateEmployeeAsContext(ctx, emp, SystemActor)
 		}
 	}
-	var user users.User
-	filter = bson.M{
-		"_id": emp.ID,
+	user, err := store.FindUser(ctx, emp.ID)
+	if err != nil {
+		user = &users.User{}
 	}
-	userCol.FindOne(context.TODO(), filter).Decode(&user)
-	emp.User = &user
+	emp.User = user
 
 	// any work records for current year and previous
 	now := time.Now().UTC()
 	year1 := now.Year()
 	year2 := year1 - 1
 
-	work, err := GetEmployeeWork(emp.ID.Hex(), uint(year1))
+	work, err := GetEmployeeWorkContext(ctx, emp.ID.Hex(), uint(year1))
 	if err == nil {
 		emp.Work = append(emp.Work, work.Work...)
 	}
 
-	work, err = GetEmployeeWork(emp.ID.Hex(), uint(year2))
+	work, err = GetEmployeeWorkContext(ctx, emp.ID.Hex(), uint(year2))
 	if err == nil {
 		emp.Work = append(emp.Work, work.Work...)
 	}
 
-	return &emp, nil
+	return emp, nil
 }
 
+// EmployeeOptions picks what is loaded with a list of employees.  Everything
+// is loaded for the whole list at once, so the number of queries doesn't grow
+// with the number of employees.
+type EmployeeOptions struct {
+	// Users attaches each employee's user, or an empty user if it has none.
+	Users bool
+	// WorkYears are the years of work records attached to each employee.
+	WorkYears []uint
+}
+
+// WorkYears provides the years from the start date's year through the end
+// date's year.
+func WorkYears(start, end time.Time) []uint {
+	var answer []uint
+	for year := start.Year(); year <= end.Year(); year++ {
+		answer = append(answer, uint(year))
+	}
+	return answer
+}
+
+// GetEmployees provides the team's employees at the site, with their users and
+// their work for the current and previous years.
 func GetEmployees(teamid, siteid string) ([]employees.Employee, error) {
-	empCol := config.GetCollection(config.DB, "scheduler", "employees")
-	userCol := config.GetCollection(config.DB, "authenticate", "users")
+	return GetEmployeesContext(context.Background(), teamid, siteid)
+}
+
+func GetEmployeesContext(ctx context.Context, teamid, siteid string) ([]employees.Employee, error) {
 	now := time.Now().UTC()
+	return GetEmployeesWithOptionsContext(ctx, teamid, siteid, EmployeeOptions{
+		Users:     true,
+		WorkYears: []uint{uint(now.Year() - 1), uint(now.Year())},
+	})
+}
 
-	oTID, _ := primitive.ObjectIDFromHex(teamid)
-	filter := bson.M{
-		"team": oTID,
-		"site": siteid,
-	}
+// GetEmployeesForTeam provides all the team's employees with their users.
+func GetEmployeesForTeam(teamid string) ([]employees.Employee, error) {
+	return GetEmployeesForTeamContext(context.Background(), teamid)
+}
 
-	var emps []employees.Employee
+func GetEmployeesForTeamContext(ctx context.Context, teamid string) ([]employees.Employee, error) {
+	return GetEmployeesWithOptionsContext(ctx, teamid, "", EmployeeOptions{
+		Users: true,
+	})
+}
+
+// GetEmployeesWithOptions provides the team's employees, at the site if one
+// is given, with the users and work picked by the options.
+func GetEmployeesWithOptions(teamid, siteid string,
+	opts EmployeeOptions) ([]employees.Employee, error) {
+	return GetEmployeesWithOptionsContext(context.Background(), teamid, siteid,
+		opts)
+}
 
-	cursor, err := empCol.Find(context.TODO(), filter)
+func GetEmployeesWithOptionsContext(ctx context.Context, teamid, siteid string,
+	opts EmployeeOptions) ([]employees.Employee, error) {
+	ctx, cancel := readContext(ctx)
+	defer cancel()
+	oTID, _ := primitive.ObjectIDFromHex(teamid)
+
+	emps, err := store.ListEmployees(ctx, oTID, siteid)
 	if err != nil {
 		return emps[:0], err
 	}
-
-	if err = cursor.All(context.TODO(), &emps); err != nil {
-		log.Println(err)
+	if err = LoadEmployeeDetailsContext(ctx, emps, opts); err != nil {
+		return emps[:0], err
 	}
+	return emps, nil
+}
+
+// LoadEmployeeDetails attaches the users and work picked by the options to the
+// employees given, replacing any they had.
+func LoadEmployeeDetails(emps []employees.Employee, opts EmployeeOptions) error {
+	return LoadEmployeeDetailsContext(context.Background(), emps, opts)
+}
 
+func LoadEmployeeDetailsContext(ctx context.Context, emps []employees.Employee,
+	opts EmployeeOptions) error {
+	ctx, cancel := readContext(ctx)
+	defer cancel()
+	if len(emps) == 0 {
+		return nil
+	}
+	ids := make([]primitive.ObjectID, len(emps))
 	for i, emp := range emps {
-		if len(emp.EmailAddresses) <= 0 {
-			if emp.Email != "" {
-				emp.AddEmailAddress(emp.Email)
-				UpdateEmployee(&emp)
-			}
+		ids[i] = emp.ID
+	}
+
+	if opts.Users {
+		userList, err := store.FindUsers(ctx, ids)
+		if err != nil {
+			return err
 		}
-		filter = bson.M{
-			"_id": emp.ID,
+		userMap := make(map[primitive.ObjectID]*users.User)
+		for u := range userList {
+			userMap[userList[u].ID] = &userList[u]
 		}
-		var user users.User
-		userCol.FindOne(context.TODO(), filter).Decode(&user)
-		emp.User = &user
+		for i := range emps {
+			user, ok := userMap[emps[i].ID]
+			if !ok {
+				user = &users.User{}
+			}
+			emps[i].User = user
+		}
+	}
 
-		work, _ := GetEmployeeWork(emp.ID.Hex(), uint(now.Year()))
-		if work != nil {
-			emp.Work = append(emp.Work, work.Work...)
+	if len(opts.WorkYears) > 0 {
+		records, err := store.ListWorkForEmployees(ctx, ids, opts.WorkYears)
+		if err != nil {
+			return err
 		}
-		work, _ = GetEmployeeWork(emp.ID.Hex(), uint(now.Year()-1))
-		if work != nil {
-			emp.Work = append(emp.Work, work.Work...)
+		workMap := make(map[primitive.ObjectID][]employees.Work)
+		for _, rec := range records {
+			workMap[rec.EmployeeID] = append(workMap[rec.EmployeeID], rec.Work...)
+		}
+		for i := range emps {
+			emps[i].Work = workMap[emps[i].ID]
+			sort.Sort(employees.ByEmployeeWork(emps[i].Work))
 		}
-		sort.Sort(employees.ByEmployeeWork(emp.Work))
-
-		emps[i] = emp
 	}
-
-	return emps, nil
+	return nil
 }
 
-func GetEmployeesForTeam(teamid string) ([]employees.Employee, error) {
-	empCol := config.GetCollection(config.DB, "scheduler", "employees")
-	userCol := config.GetCollection(config.DB, "authenticate", "users")
-
-	oTID, _ := primitive.ObjectIDFromHex(teamid)
-	filter := bson.M{
-		"team": oTID,
-	}
-
-	var employees []employees.Employee
+// GetEmployeeSchedule provides the employee's resolved schedule for each
+// calendar date from start through end, with the work for the period loaded.
+func GetEmployeeSchedule(id string, start,
+	end time.Time) ([]employees.ScheduleDay, error) {
+	return GetEmployeeScheduleContext(context.Background(), id, start, end)
+}
 
-	cursor, err := empCol.Find(context.TODO(), filter)
+func GetEmployeeScheduleContext(ctx context.Context, id string, start,
+	end time.Time) ([]employees.ScheduleDay, error) {
+	ctx, cancel := readContext(ctx)
+	defer cancel()
+	oEmpID, err := primitive.ObjectIDFromHex(id)
 	if err != nil {
-		return employees[:0], err
+		return nil, err
 	}
-
-	if err = cursor.All(context.TODO(), &employees); err != nil {
-		log.Println(err)
+	emp, err := store.FindEmployee(ctx, oEmpID)
+	if err != nil {
+		return nil, err
 	}
-
-	for i, emp := range employees {
-		if len(emp.EmailAddresses) <= 0 {
-			if emp.Email != "" {
-				emp.AddEmailAddress(emp.Email)
-				UpdateEmployee(&emp)
-			}
-		}
-		filter = bson.M{
-			"_id": emp.ID,
-		}
-		var user users.User
-		userCol.FindOne(context.TODO(), filter).Decode(&user)
-		emp.User = &user
-		employees[i] = emp
+	emps := []employees.Employee{*emp}
+	err = LoadEmployeeDetailsContext(ctx, emps, EmployeeOptions{
+		WorkYears: WorkYears(start, end),
+	})
+	if err != nil {
+		return nil, err
 	}
-
-	return employees, nil
+	return emps[0].ResolveSchedule(start, end), nil
 }
 
 func GetAllEmployees() ([]employees.Employee, error) {
-	empCol := config.GetCollection(config.DB, "scheduler", "employees")
-
-	var emps []employees.Employee
+	return GetAllEmployeesContext(context.Background())
+}
 
-	cursor, err := empCol.Find(context.TODO(), bson.M{})
+func GetAllEmployeesContext(ctx context.Context) ([]employees.Employee, error) {
+	ctx, cancel := readContext(ctx)
+	defer cancel()
+	emps, err := store.ListEmployees(ctx, primitive.NilObjectID, "")
 	if err != nil {
 		return emps[:0], err
 	}
 
-	if err = cursor.All(context.TODO(), &emps); err != nil {
-		log.Println(err)
-	}
-
 	return emps, nil
 }
 
 func UpdateEmployee(emp *employees.Employee) error {
-	empCol := config.GetCollection(config.DB, "scheduler", "employees")
+	return UpdateEmployeeContext(context.Background(), emp)
+}
 
-	filter := bson.M{
-		"_id": emp.ID,
-	}
+func UpdateEmployeeContext(ctx context.Context, emp *employees.Employee) error {
+	ctx, cancel := writeContext(ctx)
+	defer cancel()
+	return replaceEmployee(ctx, emp)
+}
 
-	_, err := empCol.ReplaceOne(context.TODO(), filter, emp)
-	return err
+// UpdateEmployeeAs stores the employee like UpdateEmployee, with the actor
+// recorded in its audit entries.
+func UpdateEmployeeAs(emp *employees.Employee, actor string) error {
+	return UpdateEmployeeAsContext(context.Background(), emp, actor)
+}
+
+func UpdateEmployeeAsContext(ctx context.Context, emp *employees.Employee,
+	actor string) error {
+	return UpdateEmployeeContext(WithActor(ctx, actor), emp)
 }
 
 func DeleteEmployee(empID string) error {
-	empCol := config.GetCollection(config.DB, "scheduler", "employees")
-	userCol := config.GetCollection(config.DB, "authenticate", "users")
+	return DeleteEmployeeContext(context.Background(), empID)
+}
 
+func DeleteEmployeeContext(ctx context.Context, empID string) error {
+	ctx, cancel := writeContext(ctx)
+	defer cancel()
 	oEmpID, _ := primitive.ObjectIDFromHex(empID)
-	filter := bson.M{
-		"_id": oEmpID,
-	}
 
-	result, err := empCol.DeleteOne(context.TODO(), filter)
+	return WithTransaction(ctx, func(ctx context.Context) error {
+		return deleteEmployee(ctx, oEmpID)
+	})
+}
+
+// deleteEmployee removes the employee record, recording its removal in the
+// audit entries, and the employee's user loses its scheduler workgroups (or
+// is removed if it has no others).  It is run inside a transaction by the
+// caller.
+func deleteEmployee(ctx context.Context, oEmpID primitive.ObjectID) error {
+	emp, err := store.FindEmployee(ctx, oEmpID)
+	if err == mongo.ErrNoDocuments {
+		return errors.New("employee not found")
+	} else if err != nil {
+		return err
+	}
+	count, err := store.DeleteEmployee(ctx, oEmpID)
 	if err != nil {
 		return err
 	}
-	if result.DeletedCount <= 0 {
+	if count <= 0 {
 		return errors.New("employee not found")
 	}
+	if err = auditRemoval(ctx, emp); err != nil {
+		return err
+	}
 
-	var user users.User
-	err = userCol.FindOne(context.TODO(), filter).Decode(&user)
-	if err == nil {
-		found := false
-		for i := len(user.Workgroups) - 1; i >= 0; i-- {
-			parts := strings.Split(user.Workgroups[i], "-")
-			if strings.EqualFold(parts[0], "scheduler") {
-				found = true
-				user.Workgroups = append(user.Workgroups[:i], user.Workgroups[i+1:]...)
-			}
-		}
-		if found && len(user.Workgroups) > 0 {
-			userCol.UpdateOne(context.TODO(), filter, user)
-		} else {
-			_, err = userCol.DeleteOne(context.TODO(), filter)
-			if err != nil {
-				fmt.Println(err.Error())
-			}
+	user, err := store.FindUser(ctx, oEmpID)
+	if err == mongo.ErrNoDocuments {
+		return nil
+	} else if err != nil {
+		return err
+	}
+	found := false
+	for i := len(user.Workgroups) - 1; i >= 0; i-- {
+		parts := strings.Split(user.Workgroups[i], "-")
+		if strings.EqualFold(parts[0], "scheduler") {
+			found = true
+			user.Workgroups = append(user.Workgroups[:i], user.Workgroups[i+1:]...)
 		}
 	}
-	return nil
+	if found && len(user.Workgroups) > 0 {
+		return store.ReplaceUser(ctx, user)
+	}
+	_, err = store.DeleteUser(ctx, oEmpID)
+	return err
 }
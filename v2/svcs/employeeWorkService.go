@@ -2,12 +2,9 @@ package svcs
 
 import (
 	"context"
-	"log"
 	"time"
 
-	"github.com/erneap/models/v2/config"
 	"github.com/erneap/models/v2/employees"
-	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
 )
@@ -18,88 +15,65 @@ import (
 // the retrieve function will only be for individual employee's year
 
 func CreateEmployeeWork(work *employees.EmployeeWorkRecord) error {
-	empWCol := config.GetCollection(config.DB, "scheduler", "employeework")
-
-	filter := bson.M{
-		"employeeID": work.EmployeeID,
-		"year":       work.Year,
-	}
-
-	var tEmpWork employees.EmployeeWorkRecord
+	return CreateEmployeeWorkContext(context.Background(), work)
+}
 
-	err := empWCol.FindOne(context.TODO(), filter).Decode(&tEmpWork)
+func CreateEmployeeWorkContext(ctx context.Context, work *employees.EmployeeWorkRecord) error {
+	ctx, cancel := writeContext(ctx)
+	defer cancel()
+	tEmpWork, err := store.FindWork(ctx, work.EmployeeID, work.Year)
 	if err == mongo.ErrNoDocuments {
 		work.ID = primitive.NewObjectID()
-		_, err = empWCol.InsertOne(context.TODO(), work)
+		return store.InsertWork(ctx, work)
+	} else if err != nil {
 		return err
 	} else {
-		filter = bson.M{
-			"_id": tEmpWork.ID,
-		}
 		work.ID = tEmpWork.ID
-		_, err = empWCol.ReplaceOne(context.TODO(), filter, work)
-		return err
+		return store.ReplaceWork(ctx, work)
 	}
 }
 
 func GetEmployeeWork(id string, year uint) (*employees.EmployeeWorkRecord, error) {
-	empWCol := config.GetCollection(config.DB, "scheduler", "employeework")
+	return GetEmployeeWorkContext(context.Background(), id, year)
+}
 
+func GetEmployeeWorkContext(ctx context.Context, id string, year uint) (*employees.EmployeeWorkRecord, error) {
+	ctx, cancel := readContext(ctx)
+	defer cancel()
 	empID, _ := primitive.ObjectIDFromHex(id)
 
-	filter := bson.M{
-		"employeeID": empID,
-		"year":       year,
-	}
-
-	var eWork employees.EmployeeWorkRecord
-
-	err := empWCol.FindOne(context.TODO(), filter).Decode(&eWork)
-	if err != nil {
-		return nil, err
-	}
-	return &eWork, nil
+	return store.FindWork(ctx, empID, year)
 }
 
 func UpdateEmployeeWork(eWork *employees.EmployeeWorkRecord) error {
-	empWCol := config.GetCollection(config.DB, "scheduler", "employeework")
-
-	filter := bson.M{
-		"_id": eWork.ID,
-	}
+	return UpdateEmployeeWorkContext(context.Background(), eWork)
+}
 
-	_, err := empWCol.ReplaceOne(context.TODO(), filter, eWork)
-	return err
+func UpdateEmployeeWorkContext(ctx context.Context, eWork *employees.EmployeeWorkRecord) error {
+	ctx, cancel := writeContext(ctx)
+	defer cancel()
+	return store.ReplaceWork(ctx, eWork)
 }
 
 func DeleteEmployeeWork(id string, year uint) error {
-	empWCol := config.GetCollection(config.DB, "scheduler", "employeework")
+	return DeleteEmployeeWorkContext(context.Background(), id, year)
+}
 
+func DeleteEmployeeWorkContext(ctx context.Context, id string, year uint) error {
+	ctx, cancel := writeContext(ctx)
+	defer cancel()
 	empID, _ := primitive.ObjectIDFromHex(id)
 
-	filter := bson.M{
-		"employeeID": empID,
-		"year":       year,
-	}
-
-	_, err := empWCol.DeleteOne(context.TODO(), filter)
+	_, err := store.DeleteWork(ctx, empID, year)
 	return err
 }
 
 func GetEmployeeWorkForPurge(purgeDate time.Time) ([]employees.EmployeeWorkRecord, error) {
-	empWCol := config.GetCollection(config.DB, "scheduler", "employeework")
-
-	filter := bson.M{"year": bson.M{"$lte": purgeDate.Year()}}
-
-	var empWork []employees.EmployeeWorkRecord
-
-	cursor, err := empWCol.Find(context.TODO(), filter)
-	if err != nil {
-		return empWork[:0], err
-	}
+	return GetEmployeeWorkForPurgeContext(context.Background(), purgeDate)
+}
 
-	if err = cursor.All(context.TODO(), &empWork); err != nil {
-		log.Println(err)
-	}
-	return empWork, err
+func GetEmployeeWorkForPurgeContext(ctx context.Context, purgeDate time.Time) ([]employees.EmployeeWorkRecord, error) {
+	ctx, cancel := readContext(ctx)
+	defer cancel()
+	return store.ListWorkThrough(ctx, uint(purgeDate.Year()), nil)
 }
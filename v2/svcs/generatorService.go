@@ -0,0 +1,90 @@
+package svcs
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/erneap/models/v2/employees"
+	"github.com/erneap/models/v2/sites"
+)
+
+// GenerateSchedule proposes a schedule for the site's workcenter over the
+// options' period, as draft variations for the scheduler to review.  The
+// team's workcodes are used when the options don't give any, and the site's
+// location when they don't give one.  Nothing is stored until the drafts are
+// given to PublishSchedule.
+func GenerateSchedule(teamid, siteid, wkctr string,
+	opts sites.GenerateOptions) (*sites.GeneratedSchedule, error) {
+	return GenerateScheduleContext(context.Background(), teamid, siteid, wkctr,
+		opts)
+}
+
+func GenerateScheduleContext(ctx context.Context, teamid, siteid,
+	wkctr string, opts sites.GenerateOptions) (*sites.GeneratedSchedule,
+	error) {
+	ctx, cancel := readContext(ctx)
+	defer cancel()
+	if opts.EndDate.Before(opts.StartDate) {
+		return nil, errors.New("schedule ends before it starts")
+	}
+	team, err := GetTeamContext(ctx, teamid)
+	if err != nil {
+		return nil, err
+	}
+	if len(opts.Workcodes) == 0 {
+		opts.Workcodes = team.Workcodes
+	}
+	for _, site := range team.Sites {
+		if !strings.EqualFold(site.ID, siteid) {
+			continue
+		}
+		if opts.Location == nil {
+			opts.Location = site.GetLocation()
+		}
+		for _, wc := range site.Workcenters {
+			if strings.EqualFold(wc.ID, wkctr) {
+				emps, err := GetEmployeesWithOptionsContext(ctx, teamid, site.ID,
+					EmployeeOptions{})
+				if err != nil {
+					return nil, err
+				}
+				return wc.GenerateSchedule(site.ID, emps, opts), nil
+			}
+		}
+		return nil, errors.New("workcenter not found")
+	}
+	return nil, errors.New("site not found")
+}
+
+// PublishSchedule adds the draft variations to their employees, each given
+// the employee's next variation id.
+func PublishSchedule(drafts []sites.DraftVariation) error {
+	return PublishScheduleContext(context.Background(), drafts)
+}
+
+func PublishScheduleContext(ctx context.Context,
+	drafts []sites.DraftVariation) error {
+	ctx, cancel := writeContext(ctx)
+	defer cancel()
+	return WithTransaction(ctx, func(ctx context.Context) error {
+		for _, draft := range drafts {
+			_, err := ModifyEmployeeContext(ctx, draft.EmployeeID,
+				func(emp *employees.Employee) error {
+					vari := draft.Variation
+					vari.ID = 1
+					for _, v := range emp.Variations {
+						if v.ID >= vari.ID {
+							vari.ID = v.ID + 1
+						}
+					}
+					emp.Variations = append(emp.Variations, vari)
+					return nil
+				})
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
@@ -66,11 +66,12 @@ func GetRequestor(context *gin.Context) string {
 
 func CheckJWT(app string) gin.HandlerFunc {
 	return func(context *gin.Context) {
+		ctx := context.Request.Context()
 		tokenString := context.GetHeader("Authorization")
 		userID := GetRequestor(context)
-		user, _ := GetUserByID(userID)
+		user, _ := GetUserByIDContext(ctx, userID)
 		if tokenString == "" {
-			CreateDBLogEntry("authentication", app, "CheckJWT Error", "",
+			CreateDBLogEntryContext(ctx, "authentication", app, "CheckJWT Error", "",
 				"No Authentication Token Passed", context)
 			context.JSON(http.StatusUnauthorized, gin.H{"error": "request does not contain an access token"})
 			context.Abort()
@@ -78,7 +79,7 @@ func CheckJWT(app string) gin.HandlerFunc {
 		}
 		claims, err := ValidateToken(tokenString)
 		if err != nil {
-			CreateDBLogEntry("authentication", app, "CheckJWT Error", user.LastName,
+			CreateDBLogEntryContext(ctx, "authentication", app, "CheckJWT Error", user.LastName,
 				"Validation Error", context)
 			context.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
 			context.Abort()
@@ -86,7 +87,7 @@ func CheckJWT(app string) gin.HandlerFunc {
 		}
 
 		// replace token by passing a new token in the response header
-		CreateDBLogEntry("authentication", app, "CheckJWT", user.LastName,
+		CreateDBLogEntryContext(ctx, "authentication", app, "CheckJWT", user.LastName,
 			"Token Verified", context)
 		id, _ := primitive.ObjectIDFromHex(claims.UserID)
 		tokenString, _ = CreateToken(id, claims.EmailAddress)
@@ -97,9 +98,10 @@ func CheckJWT(app string) gin.HandlerFunc {
 
 func CheckRole(prog, role string) gin.HandlerFunc {
 	return func(c *gin.Context) {
+		ctx := c.Request.Context()
 		tokenString := c.GetHeader("Authorization")
 		if tokenString == "" {
-			CreateDBLogEntry("authentication", prog, "CheckRole Error", "",
+			CreateDBLogEntryContext(ctx, "authentication", prog, "CheckRole Error", "",
 				"No Authentication Token Passed", c)
 			c.JSON(http.StatusUnauthorized, gin.H{"error": "request does not contain an access token"})
 			c.Abort()
@@ -107,23 +109,23 @@ func CheckRole(prog, role string) gin.HandlerFunc {
 		}
 		_, err := ValidateToken(tokenString)
 		userID := GetRequestor(c)
-		user, err2 := GetUserByID(userID)
+		user, err2 := GetUserByIDContext(ctx, userID)
 		if err != nil {
-			CreateDBLogEntry("authentication", prog, "CheckRole Error", user.LastName,
+			CreateDBLogEntryContext(ctx, "authentication", prog, "CheckRole Error", user.LastName,
 				"Validation Error", c)
 			c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
 			c.Abort()
 			return
 		}
 		if err2 != nil {
-			CreateDBLogEntry("authentication", prog, "CheckRole Error", userID,
+			CreateDBLogEntryContext(ctx, "authentication", prog, "CheckRole Error", userID,
 				"User Not Found", c)
 			c.JSON(http.StatusNotFound, gin.H{"error": "user not found: " + err.Error()})
 			c.Abort()
 			return
 		}
 		if !user.IsInGroup(prog, role) {
-			CreateDBLogEntry("authentication", prog, "CheckRole Error", user.LastName,
+			CreateDBLogEntryContext(ctx, "authentication", prog, "CheckRole Error", user.LastName,
 				"User Not In Group", c)
 			c.JSON(http.StatusUnauthorized, gin.H{"error": "user not in group"})
 			c.Abort()
@@ -135,9 +137,10 @@ func CheckRole(prog, role string) gin.HandlerFunc {
 
 func CheckRoles(prog string, roles []string) gin.HandlerFunc {
 	return func(c *gin.Context) {
+		ctx := c.Request.Context()
 		tokenString := c.GetHeader("Authorization")
 		if tokenString == "" {
-			CreateDBLogEntry("authentication", prog, "CheckRoles Error", "",
+			CreateDBLogEntryContext(ctx, "authentication", prog, "CheckRoles Error", "",
 				"No Authentication Token passed", c)
 			c.JSON(http.StatusUnauthorized, gin.H{"error": "request does not contain an access token"})
 			c.Abort()
@@ -145,15 +148,15 @@ func CheckRoles(prog string, roles []string) gin.HandlerFunc {
 		}
 		claims, err := ValidateToken(tokenString)
 		if err != nil {
-			CreateDBLogEntry("authentication", prog, "CheckRoles Error", "",
+			CreateDBLogEntryContext(ctx, "authentication", prog, "CheckRoles Error", "",
 				"Validation error", c)
 			c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
 			c.Abort()
 			return
 		}
-		user, err := GetUserByID(claims.UserID)
+		user, err := GetUserByIDContext(ctx, claims.UserID)
 		if err != nil {
-			CreateDBLogEntry("authentication", prog, "CheckRoles Error", claims.UserID,
+			CreateDBLogEntryContext(ctx, "authentication", prog, "CheckRoles Error", claims.UserID,
 				"User Not Found", c)
 			c.JSON(http.StatusNotFound, gin.H{"error": "user not found: " + err.Error()})
 			c.Abort()
@@ -166,7 +169,7 @@ func CheckRoles(prog string, roles []string) gin.HandlerFunc {
 			}
 		}
 		if !inRole {
-			CreateDBLogEntry("authentication", prog, "CheckRoles Error", user.LastName,
+			CreateDBLogEntryContext(ctx, "authentication", prog, "CheckRoles Error", user.LastName,
 				"User not in Groups", c)
 			c.JSON(http.StatusUnauthorized, gin.H{"error": "user not in group"})
 			c.Abort()
@@ -178,9 +181,10 @@ func CheckRoles(prog string, roles []string) gin.HandlerFunc {
 
 func CheckRoleList(app string, roles []string) gin.HandlerFunc {
 	return func(c *gin.Context) {
+		ctx := c.Request.Context()
 		tokenString := c.GetHeader("Authorization")
 		if tokenString == "" {
-			CreateDBLogEntry("authentication", app, "CheckRoleList Error", "",
+			CreateDBLogEntryContext(ctx, "authentication", app, "CheckRoleList Error", "",
 				"No Authentication Token passed", c)
 			c.JSON(http.StatusUnauthorized, gin.H{"error": "request does not contain an access token"})
 			c.Abort()
@@ -188,15 +192,15 @@ func CheckRoleList(app string, roles []string) gin.HandlerFunc {
 		}
 		claims, err := ValidateToken(tokenString)
 		if err != nil {
-			CreateDBLogEntry("authentication", app, "CheckRoleList Error", "",
+			CreateDBLogEntryContext(ctx, "authentication", app, "CheckRoleList Error", "",
 				"Validation Error: "+err.Error(), c)
 			c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
 			c.Abort()
 			return
 		}
-		user, err := GetUserByID(claims.UserID)
+		user, err := GetUserByIDContext(ctx, claims.UserID)
 		if err != nil {
-			CreateDBLogEntry("authentication", app, "CheckRoleList Error", claims.UserID,
+			CreateDBLogEntryContext(ctx, "authentication", app, "CheckRoleList Error", claims.UserID,
 				"User Not Found: "+err.Error(), c)
 			c.JSON(http.StatusNotFound, gin.H{"error": "user not found: " + err.Error()})
 			c.Abort()
@@ -210,7 +214,7 @@ func CheckRoleList(app string, roles []string) gin.HandlerFunc {
 			}
 		}
 		if !inRole {
-			CreateDBLogEntry("authentication", app, "CheckRoleList Error", user.LastName,
+			CreateDBLogEntryContext(ctx, "authentication", app, "CheckRoleList Error", user.LastName,
 				"User Not in list of roles provided", c)
 			c.JSON(http.StatusUnauthorized, gin.H{"error": "user not in group"})
 			c.Abort()
@@ -0,0 +1,58 @@
+package svcs
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/erneap/models/v2/employees"
+	"github.com/erneap/models/v2/sites"
+	"github.com/erneap/models/v2/teams"
+)
+
+// CheckLeaveImpact provides the impact of the employee's leave request on its
+// site's staffing, with the warnings and blocks of the site's rules.
+func CheckLeaveImpact(id, request string) (*sites.LeaveImpact, error) {
+	return CheckLeaveImpactContext(context.Background(), id, request)
+}
+
+func CheckLeaveImpactContext(ctx context.Context, id,
+	request string) (*sites.LeaveImpact, error) {
+	ctx, cancel := readContext(ctx)
+	defer cancel()
+	team, emp, _, err := leaveRequestTeam(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return leaveImpact(ctx, team, emp, request)
+}
+
+// leaveImpact checks the employee's request against the coverage of the
+// employee's site, with no impact for an employee without a site.
+func leaveImpact(ctx context.Context, team *teams.Team,
+	emp *employees.Employee, request string) (*sites.LeaveImpact, error) {
+	var req *employees.LeaveRequest
+	for r := range emp.Requests {
+		if emp.Requests[r].ID == request {
+			req = &emp.Requests[r]
+		}
+	}
+	if req == nil {
+		return nil, errors.New("leave request not found")
+	}
+	for _, site := range team.Sites {
+		if strings.EqualFold(site.ID, emp.SiteID) {
+			emps, err := GetEmployeesWithOptionsContext(ctx, team.ID.Hex(),
+				site.ID, EmployeeOptions{
+					WorkYears: WorkYears(req.StartDate, req.EndDate),
+				})
+			if err != nil {
+				return nil, err
+			}
+			return site.GetLeaveImpact(emp, req, emps), nil
+		}
+	}
+	return &sites.LeaveImpact{
+		RequestID: request,
+	}, nil
+}
@@ -0,0 +1,955 @@
+package svcs
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/erneap/models/v2/employees"
+	"github.com/erneap/models/v2/general"
+	"github.com/erneap/models/v2/metrics"
+	"github.com/erneap/models/v2/notifications"
+	"github.com/erneap/models/v2/teams"
+	"github.com/erneap/models/v2/users"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// MemoryStore keeps every collection in memory, for unit tests and local
+// demonstrations that can't use a database.  Documents are copied through
+// their bson encoding on the way in and out, so the stored documents can't be
+// changed by the caller and fields not stored in the database (like the
+// employee's user and work) are dropped just like they would be with mongo.
+type MemoryStore struct {
+	mutex       sync.RWMutex
+	employees   []employees.Employee
+	teams       []teams.Team
+	users       []users.User
+	work        []employees.EmployeeWorkRecord
+	audit       []employees.AuditEntry
+	swaps       []employees.ShiftSwap
+	logs        []general.LogEntry
+	reports     []general.DBReport
+	reportTypes []general.ReportType
+	messages    []notifications.Notification
+	missions    []metrics.Mission
+	outages     []metrics.GroundOutage
+	archives    []general.ArchiveRecord
+	migrations  []general.MigrationRecord
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{}
+}
+
+// WithTransaction just runs fn, as the memory store has no transactions.  A
+// failure part way through fn leaves the changes made before it.
+func (s *MemoryStore) WithTransaction(ctx context.Context,
+	fn func(ctx context.Context) error) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return fn(ctx)
+}
+
+// copyDocument copies the source document into the destination through their
+// bson encoding.
+func copyDocument(src, dst interface{}) error {
+	data, err := bson.Marshal(src)
+	if err != nil {
+		return err
+	}
+	return bson.Unmarshal(data, dst)
+}
+
+// copyDocuments copies the selected source documents into a new slice.
+func copyDocuments[T any](list []T, use func(*T) bool) ([]T, error) {
+	var answer []T
+	for i := range list {
+		if use(&list[i]) {
+			var doc T
+			if err := copyDocument(&list[i], &doc); err != nil {
+				return answer, err
+			}
+			answer = append(answer, doc)
+		}
+	}
+	return answer, nil
+}
+
+// findDocument provides a copy of the first document selected.
+func findDocument[T any](list []T, use func(*T) bool) (*T, error) {
+	for i := range list {
+		if use(&list[i]) {
+			var doc T
+			if err := copyDocument(&list[i], &doc); err != nil {
+				return nil, err
+			}
+			return &doc, nil
+		}
+	}
+	return nil, mongo.ErrNoDocuments
+}
+
+// replaceDocument replaces the first document selected with a copy of the
+// document given.  Like mongo, it isn't an error when nothing is selected.
+func replaceDocument[T any](list []T, doc *T, use func(*T) bool) error {
+	for i := range list {
+		if use(&list[i]) {
+			var stored T
+			if err := copyDocument(doc, &stored); err != nil {
+				return err
+			}
+			list[i] = stored
+			return nil
+		}
+	}
+	return nil
+}
+
+// removeDocuments removes the selected documents, but no more than the
+// limit when it is above zero, and returns the number removed.
+func removeDocuments[T any](list *[]T, limit int, use func(*T) bool) int64 {
+	count := int64(0)
+	for i := 0; i < len(*list); i++ {
+		if limit > 0 && int(count) >= limit {
+			break
+		}
+		if use(&(*list)[i]) {
+			*list = append((*list)[:i], (*list)[i+1:]...)
+			count++
+			i--
+		}
+	}
+	return count
+}
+
+// insertDocument adds a copy of the document to the list.
+func insertDocument[T any](list *[]T, doc *T) error {
+	var stored T
+	if err := copyDocument(doc, &stored); err != nil {
+		return err
+	}
+	*list = append(*list, stored)
+	return nil
+}
+
+// inRange checks the date against a date range, where a zero date leaves
+// that end of the range open.  The end date is inclusive unless exclusive is
+// set.
+func inRange(date, start, end time.Time, exclusive bool) bool {
+	if !start.IsZero() && date.Before(start) {
+		return false
+	}
+	if !end.IsZero() {
+		if exclusive && !date.Before(end) {
+			return false
+		} else if !exclusive && date.After(end) {
+			return false
+		}
+	}
+	return true
+}
+
+// containsID checks for the id in the list.
+func containsID(ids []primitive.ObjectID, id primitive.ObjectID) bool {
+	for _, listID := range ids {
+		if listID == id {
+			return true
+		}
+	}
+	return false
+}
+
+// Employee storage
+
+func (s *MemoryStore) InsertEmployee(ctx context.Context,
+	emp *employees.Employee) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return insertDocument(&s.employees, emp)
+}
+
+func (s *MemoryStore) FindEmployee(ctx context.Context,
+	id primitive.ObjectID) (*employees.Employee, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	return findDocument(s.employees, func(e *employees.Employee) bool {
+		return e.ID == id
+	})
+}
+
+func (s *MemoryStore) FindEmployeeByName(ctx context.Context,
+	teamid primitive.ObjectID, first, last string) (*employees.Employee, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	return findDocument(s.employees, func(e *employees.Employee) bool {
+		return (teamid.IsZero() || e.TeamID == teamid) &&
+			e.Name.FirstName == first && e.Name.LastName == last
+	})
+}
+
+func (s *MemoryStore) FindEmployeeByFullName(ctx context.Context,
+	teamid primitive.ObjectID, first, middle,
+	last string) (*employees.Employee, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	return findDocument(s.employees, func(e *employees.Employee) bool {
+		return (teamid.IsZero() || e.TeamID == teamid) &&
+			e.Name.FirstName == first && e.Name.MiddleName == middle &&
+			e.Name.LastName == last
+	})
+}
+
+func (s *MemoryStore) ListEmployees(ctx context.Context,
+	teamid primitive.ObjectID, siteid string) ([]employees.Employee, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	return copyDocuments(s.employees, func(e *employees.Employee) bool {
+		return (teamid.IsZero() || e.TeamID == teamid) &&
+			(siteid == "" || e.SiteID == siteid)
+	})
+}
+
+func (s *MemoryStore) ReplaceEmployee(ctx context.Context,
+	emp *employees.Employee) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	for _, e := range s.employees {
+		if e.ID == emp.ID && e.Version != emp.Version {
+			return &ConflictError{Collection: "employees", ID: emp.ID,
+				Version: emp.Version}
+		}
+	}
+	emp.Version++
+	return replaceDocument(s.employees, emp, func(e *employees.Employee) bool {
+		return e.ID == emp.ID
+	})
+}
+
+func (s *MemoryStore) DeleteEmployee(ctx context.Context,
+	id primitive.ObjectID) (int64, error) {
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return removeDocuments(&s.employees, 1, func(e *employees.Employee) bool {
+		return e.ID == id
+	}), nil
+}
+
+// Team storage
+
+func (s *MemoryStore) InsertTeam(ctx context.Context, team *teams.Team) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return insertDocument(&s.teams, team)
+}
+
+func (s *MemoryStore) FindTeam(ctx context.Context,
+	id primitive.ObjectID) (*teams.Team, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	return findDocument(s.teams, func(t *teams.Team) bool {
+		return t.ID == id
+	})
+}
+
+func (s *MemoryStore) FindTeamByName(ctx context.Context,
+	name string) (*teams.Team, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	return findDocument(s.teams, func(t *teams.Team) bool {
+		return t.Name == name
+	})
+}
+
+func (s *MemoryStore) ListTeams(ctx context.Context) ([]teams.Team, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	return copyDocuments(s.teams, func(t *teams.Team) bool {
+		return true
+	})
+}
+
+func (s *MemoryStore) ReplaceTeam(ctx context.Context, team *teams.Team) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	for _, t := range s.teams {
+		if t.ID == team.ID && t.Version != team.Version {
+			return &ConflictError{Collection: "teams", ID: team.ID,
+				Version: team.Version}
+		}
+	}
+	team.Version++
+	return replaceDocument(s.teams, team, func(t *teams.Team) bool {
+		return t.ID == team.ID
+	})
+}
+
+func (s *MemoryStore) DeleteTeam(ctx context.Context,
+	id primitive.ObjectID) (int64, error) {
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return removeDocuments(&s.teams, 1, func(t *teams.Team) bool {
+		return t.ID == id
+	}), nil
+}
+
+// User storage
+
+func (s *MemoryStore) InsertUser(ctx context.Context, user *users.User) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return insertDocument(&s.users, user)
+}
+
+func (s *MemoryStore) FindUser(ctx context.Context,
+	id primitive.ObjectID) (*users.User, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	return findDocument(s.users, func(u *users.User) bool {
+		return u.ID == id
+	})
+}
+
+func (s *MemoryStore) FindUserByEmail(ctx context.Context,
+	email string) (*users.User, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	return findDocument(s.users, func(u *users.User) bool {
+		return u.EmailAddress == email
+	})
+}
+
+func (s *MemoryStore) FindUserByName(ctx context.Context, first,
+	last string) (*users.User, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	return findDocument(s.users, func(u *users.User) bool {
+		return u.FirstName == first && u.LastName == last
+	})
+}
+
+func (s *MemoryStore) FindUsers(ctx context.Context,
+	ids []primitive.ObjectID) ([]users.User, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	return copyDocuments(s.users, func(u *users.User) bool {
+		return containsID(ids, u.ID)
+	})
+}
+
+func (s *MemoryStore) ListUsers(ctx context.Context) ([]users.User, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	return copyDocuments(s.users, func(u *users.User) bool {
+		return true
+	})
+}
+
+func (s *MemoryStore) ReplaceUser(ctx context.Context, user *users.User) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return replaceDocument(s.users, user, func(u *users.User) bool {
+		return u.ID == user.ID
+	})
+}
+
+func (s *MemoryStore) DeleteUser(ctx context.Context,
+	id primitive.ObjectID) (int64, error) {
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return removeDocuments(&s.users, 1, func(u *users.User) bool {
+		return u.ID == id
+	}), nil
+}
+
+// Employee work storage
+
+func (s *MemoryStore) InsertWork(ctx context.Context,
+	work *employees.EmployeeWorkRecord) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return insertDocument(&s.work, work)
+}
+
+func (s *MemoryStore) FindWork(ctx context.Context, empID primitive.ObjectID,
+	year uint) (*employees.EmployeeWorkRecord, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	return findDocument(s.work, func(w *employees.EmployeeWorkRecord) bool {
+		return w.EmployeeID == empID && w.Year == year
+	})
+}
+
+func (s *MemoryStore) ListWorkForEmployees(ctx context.Context,
+	empIDs []primitive.ObjectID,
+	years []uint) ([]employees.EmployeeWorkRecord, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	return copyDocuments(s.work, func(w *employees.EmployeeWorkRecord) bool {
+		empFound := containsID(empIDs, w.EmployeeID)
+		yearFound := false
+		for _, year := range years {
+			if w.Year == year {
+				yearFound = true
+			}
+		}
+		return empFound && yearFound
+	})
+}
+
+func (s *MemoryStore) ListWorkThrough(ctx context.Context, year uint,
+	empIDs []primitive.ObjectID) ([]employees.EmployeeWorkRecord, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	return copyDocuments(s.work, func(w *employees.EmployeeWorkRecord) bool {
+		return w.Year <= year && (empIDs == nil || containsID(empIDs, w.EmployeeID))
+	})
+}
+
+func (s *MemoryStore) ReplaceWork(ctx context.Context,
+	work *employees.EmployeeWorkRecord) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return replaceDocument(s.work, work, func(w *employees.EmployeeWorkRecord) bool {
+		return w.ID == work.ID
+	})
+}
+
+func (s *MemoryStore) DeleteWork(ctx context.Context, empID primitive.ObjectID,
+	year uint) (int64, error) {
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return removeDocuments(&s.work, 1, func(w *employees.EmployeeWorkRecord) bool {
+		return w.EmployeeID == empID && w.Year == year
+	}), nil
+}
+
+// Employee audit storage
+
+func (s *MemoryStore) InsertAuditEntries(ctx context.Context,
+	entries []employees.AuditEntry) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	for i := range entries {
+		if err := insertDocument(&s.audit, &entries[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *MemoryStore) ListAuditEntries(ctx context.Context,
+	empID primitive.ObjectID, actor string,
+	start, end time.Time) ([]employees.AuditEntry, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	return copyDocuments(s.audit, func(a *employees.AuditEntry) bool {
+		return (empID.IsZero() || a.EmployeeID == empID) &&
+			(actor == "" || a.Actor == actor) &&
+			inRange(a.Date, start, end, false)
+	})
+}
+
+func (s *MemoryStore) PurgeAuditEntries(ctx context.Context,
+	before time.Time) (int64, error) {
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return removeDocuments(&s.audit, 0, func(a *employees.AuditEntry) bool {
+		return a.Date.Before(before)
+	}), nil
+}
+
+// Shift swap storage
+
+func (s *MemoryStore) InsertShiftSwap(ctx context.Context,
+	swap *employees.ShiftSwap) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return insertDocument(&s.swaps, swap)
+}
+
+func (s *MemoryStore) FindShiftSwap(ctx context.Context,
+	id primitive.ObjectID) (*employees.ShiftSwap, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	return findDocument(s.swaps, func(sw *employees.ShiftSwap) bool {
+		return sw.ID == id
+	})
+}
+
+func (s *MemoryStore) ListShiftSwaps(ctx context.Context, teamID,
+	empID primitive.ObjectID) ([]employees.ShiftSwap, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	return copyDocuments(s.swaps, func(sw *employees.ShiftSwap) bool {
+		return (teamID.IsZero() || sw.TeamID == teamID) &&
+			(empID.IsZero() || sw.RequesterID == empID ||
+				sw.CounterpartID == empID)
+	})
+}
+
+func (s *MemoryStore) ReplaceShiftSwap(ctx context.Context,
+	swap *employees.ShiftSwap) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return replaceDocument(s.swaps, swap, func(sw *employees.ShiftSwap) bool {
+		return sw.ID == swap.ID
+	})
+}
+
+// Log storage
+
+func (s *MemoryStore) InsertLogEntry(ctx context.Context,
+	entry *general.LogEntry) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return insertDocument(&s.logs, entry)
+}
+
+func (s *MemoryStore) FindLogEntry(ctx context.Context,
+	id primitive.ObjectID) (*general.LogEntry, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	return findDocument(s.logs, func(l *general.LogEntry) bool {
+		return l.ID == id
+	})
+}
+
+func (s *MemoryStore) ListLogEntries(ctx context.Context, app, cat string,
+	start, end time.Time) ([]general.LogEntry, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	return copyDocuments(s.logs, func(l *general.LogEntry) bool {
+		return (app == "" || l.Application == app) &&
+			(cat == "" || l.Category == cat) &&
+			inRange(l.EntryDate, start, end, false)
+	})
+}
+
+func (s *MemoryStore) ReplaceLogEntry(ctx context.Context,
+	entry *general.LogEntry) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return replaceDocument(s.logs, entry, func(l *general.LogEntry) bool {
+		return l.ID == entry.ID
+	})
+}
+
+func (s *MemoryStore) DeleteLogEntry(ctx context.Context,
+	id primitive.ObjectID) (int64, error) {
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return removeDocuments(&s.logs, 1, func(l *general.LogEntry) bool {
+		return l.ID == id
+	}), nil
+}
+
+func (s *MemoryStore) DeleteLogEntries(ctx context.Context,
+	ids []primitive.ObjectID) (int64, error) {
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return removeDocuments(&s.logs, 0, func(l *general.LogEntry) bool {
+		return containsID(ids, l.ID)
+	}), nil
+}
+
+// Report storage
+
+func (s *MemoryStore) InsertReport(ctx context.Context,
+	rpt *general.DBReport) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return insertDocument(&s.reports, rpt)
+}
+
+func (s *MemoryStore) FindReport(ctx context.Context,
+	id primitive.ObjectID) (*general.DBReport, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	return findDocument(s.reports, func(r *general.DBReport) bool {
+		return r.ID == id
+	})
+}
+
+func (s *MemoryStore) ListReports(ctx context.Context, typeID primitive.ObjectID,
+	start, end time.Time) ([]general.DBReport, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	return copyDocuments(s.reports, func(r *general.DBReport) bool {
+		return (typeID.IsZero() || r.ReportTypeID == typeID) &&
+			inRange(r.ReportDate, start, end, true)
+	})
+}
+
+func (s *MemoryStore) ReplaceReport(ctx context.Context,
+	rpt *general.DBReport) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return replaceDocument(s.reports, rpt, func(r *general.DBReport) bool {
+		return r.ID == rpt.ID
+	})
+}
+
+func (s *MemoryStore) DeleteReport(ctx context.Context,
+	id primitive.ObjectID) (int64, error) {
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return removeDocuments(&s.reports, 1, func(r *general.DBReport) bool {
+		return r.ID == id
+	}), nil
+}
+
+func (s *MemoryStore) DeleteReports(ctx context.Context,
+	ids []primitive.ObjectID) (int64, error) {
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return removeDocuments(&s.reports, 0, func(r *general.DBReport) bool {
+		return containsID(ids, r.ID)
+	}), nil
+}
+
+func (s *MemoryStore) InsertReportType(ctx context.Context,
+	rptType *general.ReportType) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return insertDocument(&s.reportTypes, rptType)
+}
+
+func (s *MemoryStore) FindReportType(ctx context.Context,
+	id primitive.ObjectID) (*general.ReportType, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	return findDocument(s.reportTypes, func(r *general.ReportType) bool {
+		return r.ID == id
+	})
+}
+
+func (s *MemoryStore) ListReportTypes(ctx context.Context,
+	app string) ([]general.ReportType, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	return copyDocuments(s.reportTypes, func(r *general.ReportType) bool {
+		return app == "" || r.Application == app
+	})
+}
+
+func (s *MemoryStore) ReplaceReportType(ctx context.Context,
+	rptType *general.ReportType) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return replaceDocument(s.reportTypes, rptType, func(r *general.ReportType) bool {
+		return r.ID == rptType.ID
+	})
+}
+
+func (s *MemoryStore) DeleteReportType(ctx context.Context,
+	id primitive.ObjectID) (int64, error) {
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return removeDocuments(&s.reportTypes, 1, func(r *general.ReportType) bool {
+		return r.ID == id
+	}), nil
+}
+
+// Notification message storage
+
+func (s *MemoryStore) InsertMessage(ctx context.Context,
+	msg *notifications.Notification) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return insertDocument(&s.messages, msg)
+}
+
+func (s *MemoryStore) FindMessage(ctx context.Context,
+	id primitive.ObjectID) (*notifications.Notification, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	return findDocument(s.messages, func(m *notifications.Notification) bool {
+		return m.ID == id
+	})
+}
+
+func (s *MemoryStore) ListMessages(ctx context.Context,
+	to string) ([]notifications.Notification, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	return copyDocuments(s.messages, func(m *notifications.Notification) bool {
+		return to == "" || m.To == to
+	})
+}
+
+func (s *MemoryStore) DeleteMessage(ctx context.Context,
+	id primitive.ObjectID) (int64, error) {
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return removeDocuments(&s.messages, 1, func(m *notifications.Notification) bool {
+		return m.ID == id
+	}), nil
+}
+
+// Metrics storage
+
+func (s *MemoryStore) InsertMission(ctx context.Context,
+	msn *metrics.Mission) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return insertDocument(&s.missions, msn)
+}
+
+func (s *MemoryStore) ListMissions(ctx context.Context,
+	start, end time.Time) ([]metrics.Mission, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	return copyDocuments(s.missions, func(m *metrics.Mission) bool {
+		return inRange(m.MissionDate, start, end, false)
+	})
+}
+
+func (s *MemoryStore) InsertGroundOutage(ctx context.Context,
+	outage *metrics.GroundOutage) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return insertDocument(&s.outages, outage)
+}
+
+func (s *MemoryStore) ListGroundOutages(ctx context.Context,
+	start, end time.Time) ([]metrics.GroundOutage, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	return copyDocuments(s.outages, func(o *metrics.GroundOutage) bool {
+		return inRange(o.OutageDate, start, end, false)
+	})
+}
+
+// Archive storage
+
+func (s *MemoryStore) InsertArchives(ctx context.Context,
+	recs []general.ArchiveRecord) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	for i := range recs {
+		if err := insertDocument(&s.archives, &recs[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *MemoryStore) ListArchives(ctx context.Context, collection string,
+	start, end time.Time) ([]general.ArchiveRecord, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	return copyDocuments(s.archives, func(a *general.ArchiveRecord) bool {
+		return (collection == "" || a.Collection == collection) &&
+			inRange(a.ArchiveDate, start, end, false)
+	})
+}
+
+// Migration storage
+
+func (s *MemoryStore) InsertMigration(ctx context.Context,
+	rec *general.MigrationRecord) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return insertDocument(&s.migrations, rec)
+}
+
+func (s *MemoryStore) ListMigrations(
+	ctx context.Context) ([]general.MigrationRecord, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	return copyDocuments(s.migrations, func(m *general.MigrationRecord) bool {
+		return true
+	})
+}
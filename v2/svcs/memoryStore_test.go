@@ -0,0 +1,234 @@
+package svcs
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/erneap/models/v2/employees"
+	"github.com/erneap/models/v2/teams"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+func TestMemoryStoreListEmployees(t *testing.T) {
+	ctx := context.Background()
+	s := NewMemoryStore()
+	teamA, teamB := primitive.NewObjectID(), primitive.NewObjectID()
+	for _, emp := range []employees.Employee{
+		{ID: primitive.NewObjectID(), TeamID: teamA, SiteID: "one"},
+		{ID: primitive.NewObjectID(), TeamID: teamA, SiteID: "two"},
+		{ID: primitive.NewObjectID(), TeamID: teamB, SiteID: "one"},
+	} {
+		if err := s.InsertEmployee(ctx, &emp); err != nil {
+			t.Fatal(err)
+		}
+	}
+	tests := []struct {
+		name   string
+		teamid primitive.ObjectID
+		siteid string
+		want   int
+	}{
+		{name: "everyone", want: 3},
+		{name: "team", teamid: teamA, want: 2},
+		{name: "site of any team", siteid: "one", want: 2},
+		{name: "team's site", teamid: teamA, siteid: "two", want: 1},
+		{name: "no one", teamid: teamB, siteid: "two", want: 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := s.ListEmployees(ctx, tt.teamid, tt.siteid)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(got) != tt.want {
+				t.Errorf("got %d employees, want %d", len(got), tt.want)
+			}
+		})
+	}
+}
+
+func TestMemoryStoreCopies(t *testing.T) {
+	ctx := context.Background()
+	s := NewMemoryStore()
+	emp := employees.Employee{ID: primitive.NewObjectID(), Email: "stored"}
+	if err := s.InsertEmployee(ctx, &emp); err != nil {
+		t.Fatal(err)
+	}
+	emp.Email = "changed"
+	found, _ := s.FindEmployee(ctx, emp.ID)
+	found.Email = "changed"
+	stored, _ := s.FindEmployee(ctx, emp.ID)
+	if stored.Email != "stored" {
+		t.Errorf("stored employee changed to %s", stored.Email)
+	}
+}
+
+func TestMemoryStoreNotFound(t *testing.T) {
+	ctx := context.Background()
+	s := NewMemoryStore()
+	if _, err := s.FindEmployee(ctx, primitive.NewObjectID()); !errors.Is(err,
+		mongo.ErrNoDocuments) {
+		t.Errorf("find error = %v, want %v", err, mongo.ErrNoDocuments)
+	}
+	if n, err := s.DeleteEmployee(ctx, primitive.NewObjectID()); err != nil ||
+		n != 0 {
+		t.Errorf("delete = %d, %v", n, err)
+	}
+	cancelled, cancel := context.WithCancel(ctx)
+	cancel()
+	if _, err := s.ListEmployees(cancelled, primitive.NilObjectID,
+		""); !errors.Is(err, context.Canceled) {
+		t.Errorf("cancelled list error = %v", err)
+	}
+}
+
+func TestMemoryStoreReplaceEmployee(t *testing.T) {
+	tests := []struct {
+		name string
+		// the versions replaced, in order, each read when the store was at
+		// the version.
+		versions []int64
+		// the stored version after each replace, or -1 for a conflict.
+		want []int64
+	}{
+		{
+			name:     "first replace",
+			versions: []int64{0},
+			want:     []int64{1},
+		},
+		{
+			name:     "replaces in turn",
+			versions: []int64{0, 1, 2},
+			want:     []int64{1, 2, 3},
+		},
+		{
+			name:     "stale version",
+			versions: []int64{0, 0},
+			want:     []int64{1, -1},
+		},
+		{
+			name:     "stale then fresh",
+			versions: []int64{0, 0, 1},
+			want:     []int64{1, -1, 2},
+		},
+		{
+			name:     "version from the future",
+			versions: []int64{3},
+			want:     []int64{-1},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			s := NewMemoryStore()
+			emp := employees.Employee{ID: primitive.NewObjectID()}
+			if err := s.InsertEmployee(ctx, &emp); err != nil {
+				t.Fatal(err)
+			}
+			for i, version := range tt.versions {
+				update := emp
+				update.Version = version
+				update.Email = "update"
+				err := s.ReplaceEmployee(ctx, &update)
+				stored, ferr := s.FindEmployee(ctx, emp.ID)
+				if ferr != nil {
+					t.Fatal(ferr)
+				}
+				if tt.want[i] < 0 {
+					var ce *ConflictError
+					if !errors.Is(err, ErrConflict) || !errors.As(err, &ce) ||
+						ce.ID != emp.ID || ce.Version != version {
+						t.Fatalf("replace %d error = %v, want a conflict", i, err)
+					}
+					if update.Version != version {
+						t.Errorf("replace %d conflict changed the version to %d", i,
+							update.Version)
+					}
+					continue
+				}
+				if err != nil {
+					t.Fatalf("replace %d: %v", i, err)
+				}
+				if update.Version != tt.want[i] ||
+					stored.Version != tt.want[i] || stored.Email != "update" {
+					t.Errorf("replace %d version = %d, stored %d, want %d", i,
+						update.Version, stored.Version, tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestMemoryStoreReplaceTeam(t *testing.T) {
+	ctx := context.Background()
+	s := NewMemoryStore()
+	team := teams.Team{ID: primitive.NewObjectID(), Name: "team"}
+	if err := s.InsertTeam(ctx, &team); err != nil {
+		t.Fatal(err)
+	}
+	first, _ := s.FindTeam(ctx, team.ID)
+	second, _ := s.FindTeam(ctx, team.ID)
+	first.Name = "first"
+	if err := s.ReplaceTeam(ctx, first); err != nil || first.Version != 1 {
+		t.Fatalf("replace = %v, version %d", err, first.Version)
+	}
+	second.Name = "second"
+	if err := s.ReplaceTeam(ctx, second); !errors.Is(err, ErrConflict) {
+		t.Fatalf("stale replace error = %v, want a conflict", err)
+	}
+	stored, _ := s.FindTeam(ctx, team.ID)
+	if stored.Name != "first" || stored.Version != 1 {
+		t.Errorf("stored team = %s version %d", stored.Name, stored.Version)
+	}
+}
+
+func TestModifyEmployeeMergesConflicts(t *testing.T) {
+	SetStore(NewMemoryStore())
+	team := CreateTeam("modify", false)
+	emp, err := CreateEmployee(employees.Employee{
+		Name: employees.EmployeeName{FirstName: "M", LastName: "E"},
+	}, "pw", "", team.ID.Hex(), "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	calls := 0
+	got, err := ModifyEmployee(emp.ID.Hex(), func(e *employees.Employee) error {
+		calls++
+		if calls == 1 {
+			// another update gets there first.
+			other, err := GetEmployee(emp.ID.Hex())
+			if err != nil {
+				return err
+			}
+			other.Email = "other@x"
+			if err := UpdateEmployee(other); err != nil {
+				return err
+			}
+		}
+		e.AddAssignment("s", "w", time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC))
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if calls != 2 || got.Email != "other@x" || len(got.Assignments) != 1 {
+		t.Errorf("modified after %d calls: email %s, %d assignments", calls,
+			got.Email, len(got.Assignments))
+	}
+
+	MaxModifyAttempts = 1
+	defer func() { MaxModifyAttempts = 5 }()
+	_, err = ModifyEmployee(emp.ID.Hex(), func(e *employees.Employee) error {
+		other, err := GetEmployee(emp.ID.Hex())
+		if err != nil {
+			return err
+		}
+		return UpdateEmployee(other)
+	})
+	if !errors.Is(err, ErrConflict) {
+		t.Errorf("error = %v, want a conflict", err)
+	}
+}
@@ -0,0 +1,44 @@
+package svcs
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/erneap/models/v2/metrics"
+)
+
+// The metrics functions read the missions and ground outages used by the
+// mission and draw summary reports.  The dates given are inclusive.
+
+func GetMissionsBetweenDates(start, end time.Time) ([]metrics.Mission, error) {
+	return GetMissionsBetweenDatesContext(context.Background(), start, end)
+}
+
+func GetMissionsBetweenDatesContext(ctx context.Context, start,
+	end time.Time) ([]metrics.Mission, error) {
+	ctx, cancel := readContext(ctx)
+	defer cancel()
+	msns, err := store.ListMissions(ctx, start, end)
+	if err != nil {
+		return msns, err
+	}
+	sort.Sort(metrics.ByMission(msns))
+	return msns, nil
+}
+
+func GetOutagesBetweenDates(start, end time.Time) ([]metrics.GroundOutage, error) {
+	return GetOutagesBetweenDatesContext(context.Background(), start, end)
+}
+
+func GetOutagesBetweenDatesContext(ctx context.Context, start,
+	end time.Time) ([]metrics.GroundOutage, error) {
+	ctx, cancel := readContext(ctx)
+	defer cancel()
+	outages, err := store.ListGroundOutages(ctx, start, end)
+	if err != nil {
+		return outages, err
+	}
+	sort.Sort(metrics.ByOutage(outages))
+	return outages, nil
+}
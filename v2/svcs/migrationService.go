@@ -0,0 +1,181 @@
+package svcs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/erneap/models/v2/general"
+)
+
+// Changes to the stored document layouts are made by migrations, which
+// rewrite the stored documents once instead of converting them every time
+// they are read.  Each migration has a version, and MigrateUp applies the
+// migrations not yet recorded in the migrations collection in version order.
+// Applications call MigrateUp (or MigrateDryRun to see what would change) at
+// startup, after the database is opened.
+
+// Migration is a single change to the stored documents.  Up makes the change
+// through the store given, or with dryRun set only counts the documents it
+// would change, and provides that count.  Up stores the documents in batches
+// (see saveInBatches), so it must skip the documents it already changed when
+// it is run again after failing part way.
+type Migration struct {
+	Version int
+	Name    string
+	Up      func(ctx context.Context, s Store, dryRun bool) (int64, error)
+}
+
+// MigrationResult tells what a migration did (or would do for a dry run).
+type MigrationResult struct {
+	Version   int
+	Name      string
+	Documents int64
+	Applied   bool
+}
+
+var migrations []Migration
+
+// migrationBatchSize is the number of documents a migration stores in each
+// transaction, keeping a migration of a large collection within the
+// database's transaction limits.
+const migrationBatchSize = 100
+
+// RegisterMigration adds a migration to the ones run by MigrateUp.  The
+// versions must be unique and above zero.
+func RegisterMigration(m Migration) error {
+	if m.Version <= 0 || m.Up == nil {
+		return errors.New("migration needs a version and an up function")
+	}
+	for _, mig := range migrations {
+		if mig.Version == m.Version {
+			return fmt.Errorf("migration version %d already registered",
+				m.Version)
+		}
+	}
+	migrations = append(migrations, m)
+	sort.Slice(migrations, func(i, j int) bool {
+		return migrations[i].Version < migrations[j].Version
+	})
+	return nil
+}
+
+// GetMigrations provides the registered migrations in version order.
+func GetMigrations() []Migration {
+	return append([]Migration{}, migrations...)
+}
+
+// MigrateUp applies every registered migration not yet applied, recording
+// each one once all its documents are stored, stopping at the first failure.
+// A migration that fails keeps the batches of documents it stored, and
+// finishes the rest when MigrateUp is run again.  Applications must call it at
+// startup, after config.Open, before serving requests:
+//
+//	if _, err := svcs.MigrateUp(); err != nil {
+//		log.Fatal(err)
+//	}
+func MigrateUp() ([]MigrationResult, error) {
+	return MigrateUpContext(context.Background())
+}
+
+func MigrateUpContext(ctx context.Context) ([]MigrationResult, error) {
+	return runMigrations(ctx, false)
+}
+
+// MigrateDryRun provides the number of documents each migration not yet
+// applied would change, without changing anything.
+func MigrateDryRun() ([]MigrationResult, error) {
+	return MigrateDryRunContext(context.Background())
+}
+
+func MigrateDryRunContext(ctx context.Context) ([]MigrationResult, error) {
+	return runMigrations(ctx, true)
+}
+
+// GetAppliedMigrations provides the migration records in version order.
+func GetAppliedMigrations() ([]general.MigrationRecord, error) {
+	return GetAppliedMigrationsContext(context.Background())
+}
+
+func GetAppliedMigrationsContext(
+	ctx context.Context) ([]general.MigrationRecord, error) {
+	ctx, cancel := readContext(ctx)
+	defer cancel()
+	recs, err := store.ListMigrations(ctx)
+	if err != nil {
+		return recs, err
+	}
+	sort.Sort(general.ByMigrationRecord(recs))
+	return recs, nil
+}
+
+func runMigrations(ctx context.Context, dryRun bool) ([]MigrationResult,
+	error) {
+	var answer []MigrationResult
+	applied, err := GetAppliedMigrationsContext(ctx)
+	if err != nil {
+		return answer, err
+	}
+	done := make(map[int]bool)
+	for _, rec := range applied {
+		done[rec.Version] = true
+	}
+
+	for _, mig := range GetMigrations() {
+		if done[mig.Version] {
+			continue
+		}
+		result := MigrationResult{
+			Version: mig.Version,
+			Name:    mig.Name,
+		}
+		if dryRun {
+			result.Documents, err = mig.Up(ctx, store, true)
+			if err != nil {
+				return answer, err
+			}
+			answer = append(answer, result)
+			continue
+		}
+		result.Documents, err = mig.Up(ctx, store, false)
+		if err == nil {
+			err = store.InsertMigration(ctx, &general.MigrationRecord{
+				Version:     mig.Version,
+				Name:        mig.Name,
+				AppliedDate: time.Now().UTC(),
+				Documents:   result.Documents,
+			})
+		}
+		if err != nil {
+			return answer, fmt.Errorf("migration %d (%s): %w", mig.Version,
+				mig.Name, err)
+		}
+		result.Applied = true
+		answer = append(answer, result)
+	}
+	return answer, nil
+}
+
+// saveInBatches stores the changed documents with the save function, in
+// transactions of migrationBatchSize documents.  Each document is saved from a
+// copy, so a retried transaction saves it as it was given.
+func saveInBatches[T any](ctx context.Context, s Store, docs []T,
+	save func(ctx context.Context, doc *T) error) error {
+	for start := 0; start < len(docs); start += migrationBatchSize {
+		batch := docs[start:min(start+migrationBatchSize, len(docs))]
+		err := s.WithTransaction(ctx, func(ctx context.Context) error {
+			for _, doc := range batch {
+				if err := save(ctx, &doc); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,148 @@
+package svcs
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/erneap/models/v2/employees"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestRegisterMigration(t *testing.T) {
+	up := func(ctx context.Context, s Store, dryRun bool) (int64, error) {
+		return 0, nil
+	}
+	tests := []struct {
+		name string
+		mig  Migration
+	}{
+		{name: "no version", mig: Migration{Name: "none", Up: up}},
+		{name: "no up", mig: Migration{Version: 1000, Name: "no up"}},
+		{name: "version taken", mig: Migration{Version: 1, Name: "again", Up: up}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			before := len(GetMigrations())
+			if err := RegisterMigration(tt.mig); err == nil {
+				t.Error("RegisterMigration gave no error")
+			}
+			if len(GetMigrations()) != before {
+				t.Error("migration registered")
+			}
+		})
+	}
+}
+
+func TestMigrateUp(t *testing.T) {
+	SetStore(NewMemoryStore())
+	ctx := context.Background()
+	legacy := employees.Employee{
+		ID: primitive.NewObjectID(),
+		Data: &employees.EmployeeData{
+			Assignments: []employees.Assignment{
+				{ID: 1, Site: "s", Workcenter: "w",
+					StartDate: time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)},
+			},
+			Leaves: []employees.LeaveDay{
+				{ID: 1, LeaveDate: time.Date(2024, 6, 3, 0, 0, 0, 0, time.UTC),
+					Code: "V", Hours: 8, Status: "ACTUAL"},
+			},
+			LaborCodes: []employees.EmployeeLaborCode{
+				{ChargeNumber: "C", Extension: "E"},
+			},
+		},
+	}
+	current := employees.Employee{ID: primitive.NewObjectID()}
+	for _, emp := range []employees.Employee{legacy, current} {
+		if err := store.InsertEmployee(ctx, &emp); err != nil {
+			t.Fatal(err)
+		}
+	}
+	first := func(results []MigrationResult) MigrationResult {
+		for _, r := range results {
+			if r.Version == 1 {
+				return r
+			}
+		}
+		t.Fatalf("no result for migration 1: %+v", results)
+		return MigrationResult{}
+	}
+
+	results, err := MigrateDryRun()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r := first(results); r.Documents != 1 || r.Applied {
+		t.Errorf("dry run = %+v", r)
+	}
+	if emp, _ := store.FindEmployee(ctx, legacy.ID); emp.Data == nil {
+		t.Error("dry run converted the employee")
+	}
+
+	results, err = MigrateUp()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r := first(results); r.Documents != 1 || !r.Applied {
+		t.Errorf("migrate up = %+v", r)
+	}
+	emp, err := store.FindEmployee(ctx, legacy.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if emp.Data != nil || len(emp.Assignments) != 1 || len(emp.Leaves) != 1 ||
+		len(emp.Assignments[0].LaborCodes) != 1 || len(emp.LaborCodes) != 0 {
+		t.Errorf("migrated employee = %+v", emp)
+	}
+	applied, err := GetAppliedMigrations()
+	if err != nil || len(applied) != len(GetMigrations()) {
+		t.Errorf("applied = %+v, %v", applied, err)
+	}
+
+	// nothing is left to apply.
+	results, err = MigrateUp()
+	if err != nil || len(results) != 0 {
+		t.Errorf("second migrate up = %+v, %v", results, err)
+	}
+}
+
+func TestSaveInBatches(t *testing.T) {
+	SetStore(NewMemoryStore())
+	docs := make([]int, 2*migrationBatchSize+50)
+	for i := range docs {
+		docs[i] = i
+	}
+	tests := []struct {
+		name    string
+		failAt  int
+		saved   int
+		wantErr bool
+	}{
+		{name: "all batches", failAt: -1, saved: len(docs)},
+		{name: "failure in the second batch", failAt: migrationBatchSize + 10,
+			saved: migrationBatchSize + 10, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var saved []int
+			err := saveInBatches(context.Background(), store, docs,
+				func(ctx context.Context, doc *int) error {
+					if *doc == tt.failAt {
+						return errors.New("failed")
+					}
+					saved = append(saved, *doc)
+					return nil
+				})
+			if (err != nil) != tt.wantErr || len(saved) != tt.saved {
+				t.Errorf("saved %d, %v", len(saved), err)
+			}
+			for i, doc := range saved {
+				if doc != i {
+					t.Fatalf("saved %d as %d", doc, i)
+				}
+			}
+		})
+	}
+}
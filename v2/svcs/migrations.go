@@ -0,0 +1,129 @@
+package svcs
+
+import (
+	"context"
+	"time"
+
+	"github.com/erneap/models/v2/employees"
+	"github.com/erneap/models/v2/sites"
+	"github.com/erneap/models/v2/teams"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// The migrations for the stored documents, in version order.  New migrations
+// are added to the end with the next version, existing ones are never
+// changed once released.  A migration that can't be registered, like one
+// with a version already used, stops the application at startup.
+func init() {
+	for _, m := range []Migration{
+		{
+			Version: 1,
+			Name:    "convert employee data",
+			Up:      convertEmployeeData,
+		},
+		{
+			Version: 2,
+			Name:    "employee email addresses",
+			Up:      addEmployeeEmailAddresses,
+		},
+		{
+			Version: 3,
+			Name:    "site time zones",
+			Up:      addSiteTimeZones,
+		},
+	} {
+		if err := RegisterMigration(m); err != nil {
+			panic(err)
+		}
+	}
+}
+
+// convertEmployeeData moves the legacy nested employee data into the
+// employee, and copies the employee's labor codes into each assignment.
+func convertEmployeeData(ctx context.Context, s Store,
+	dryRun bool) (int64, error) {
+	emps, err := s.ListEmployees(ctx, primitive.NilObjectID, "")
+	if err != nil {
+		return 0, err
+	}
+	var changed []employees.Employee
+	for _, emp := range emps {
+		if emp.Data == nil && len(emp.LaborCodes) == 0 {
+			continue
+		}
+		emp.ConvertFromData()
+		for _, lc := range emp.LaborCodes {
+			for a, asgmt := range emp.Assignments {
+				asgmt.AddLaborCode(lc.ChargeNumber, lc.Extension)
+				emp.Assignments[a] = asgmt
+			}
+		}
+		emp.LaborCodes = nil
+		changed = append(changed, emp)
+	}
+	count := int64(len(changed))
+	if dryRun {
+		return count, nil
+	}
+	return count, saveInBatches(ctx, s, changed, s.ReplaceEmployee)
+}
+
+// addEmployeeEmailAddresses adds the employee's original email to its email
+// address list when the list is empty.  The employee list functions used to
+// do this every time they were read.
+func addEmployeeEmailAddresses(ctx context.Context, s Store,
+	dryRun bool) (int64, error) {
+	emps, err := s.ListEmployees(ctx, primitive.NilObjectID, "")
+	if err != nil {
+		return 0, err
+	}
+	var changed []employees.Employee
+	for _, emp := range emps {
+		if len(emp.EmailAddresses) > 0 || emp.Email == "" {
+			continue
+		}
+		emp.AddEmailAddress(emp.Email)
+		changed = append(changed, emp)
+	}
+	count := int64(len(changed))
+	if dryRun {
+		return count, nil
+	}
+	return count, saveInBatches(ctx, s, changed, s.ReplaceEmployee)
+}
+
+// addSiteTimeZones gives each site without a time zone the IANA zone for its
+// UTC offset.  Whole hour offsets have a fixed "Etc/GMT" zone, which keeps the
+// times the site had; the sites observing daylight saving time still need
+// their real zone set.  The sites with other offsets keep using the offset.
+func addSiteTimeZones(ctx context.Context, s Store,
+	dryRun bool) (int64, error) {
+	tms, err := s.ListTeams(ctx)
+	if err != nil {
+		return 0, err
+	}
+	count := int64(0)
+	var changed []teams.Team
+	for _, team := range tms {
+		sitesChanged := false
+		for i, site := range team.Sites {
+			if site.TimeZone != "" {
+				continue
+			}
+			zone := sites.OffsetZone(site.UtcOffset)
+			if _, err := time.LoadLocation(zone); err != nil {
+				continue
+			}
+			count++
+			team.Sites[i].TimeZone = zone
+			sitesChanged = true
+		}
+		if sitesChanged {
+			changed = append(changed, team)
+		}
+	}
+	if dryRun {
+		return count, nil
+	}
+	return count, saveInBatches(ctx, s, changed, s.ReplaceTeam)
+}
@@ -0,0 +1,786 @@
+package svcs
+
+import (
+	"context"
+	"errors"
+	"log"
+	"time"
+
+	"github.com/erneap/models/v2/config"
+	"github.com/erneap/models/v2/employees"
+	"github.com/erneap/models/v2/general"
+	"github.com/erneap/models/v2/metrics"
+	"github.com/erneap/models/v2/notifications"
+	"github.com/erneap/models/v2/teams"
+	"github.com/erneap/models/v2/users"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// MongoStore is the default storage for the service functions, with every
+// collection stored in the mongo database opened by the config package.
+type MongoStore struct{}
+
+func NewMongoStore() *MongoStore {
+	return &MongoStore{}
+}
+
+// WithTransaction runs fn in a session transaction, which the driver commits
+// or aborts and retries on transient errors.  A standalone server can't run
+// transactions, so there fn is run again without one.  This is safe because
+// the server rejects the first operation of the transaction, before anything
+// is changed.
+func (s *MongoStore) WithTransaction(ctx context.Context,
+	fn func(ctx context.Context) error) error {
+	// a transaction already started by the caller includes fn
+	if mongo.SessionFromContext(ctx) != nil {
+		return fn(ctx)
+	}
+	client, err := config.Client(ctx)
+	if err != nil {
+		return err
+	}
+	session, err := client.StartSession()
+	if err != nil {
+		return err
+	}
+	defer session.EndSession(ctx)
+
+	_, err = session.WithTransaction(ctx,
+		func(sc mongo.SessionContext) (interface{}, error) {
+			return nil, fn(sc)
+		})
+	if err != nil && transactionsUnsupported(err) {
+		log.Printf("database doesn't support transactions: %s", err.Error())
+		return fn(ctx)
+	}
+	return err
+}
+
+// transactionsUnsupported checks for the IllegalOperation error a standalone
+// server gives for a transaction.
+func transactionsUnsupported(err error) bool {
+	var serverErr mongo.ServerError
+	return errors.As(err, &serverErr) && serverErr.HasErrorCode(20)
+}
+
+// findOne decodes the first document matching the filter into the answer.
+func findOne(ctx context.Context, dbName, colName string, filter interface{},
+	answer interface{}) error {
+	col, err := config.Collection(ctx, dbName, colName)
+	if err != nil {
+		return err
+	}
+	return col.FindOne(ctx, filter).Decode(answer)
+}
+
+// findAll decodes all documents matching the filter into the answer slice.
+func findAll(ctx context.Context, dbName, colName string, filter interface{},
+	answer interface{}) error {
+	col, err := config.Collection(ctx, dbName, colName)
+	if err != nil {
+		return err
+	}
+	cursor, err := col.Find(ctx, filter)
+	if err != nil {
+		return err
+	}
+	return cursor.All(ctx, answer)
+}
+
+func insertOne(ctx context.Context, dbName, colName string,
+	doc interface{}) error {
+	col, err := config.Collection(ctx, dbName, colName)
+	if err != nil {
+		return err
+	}
+	_, err = col.InsertOne(ctx, doc)
+	return err
+}
+
+func replaceOne(ctx context.Context, dbName, colName string, filter interface{},
+	doc interface{}) error {
+	col, err := config.Collection(ctx, dbName, colName)
+	if err != nil {
+		return err
+	}
+	_, err = col.ReplaceOne(ctx, filter, doc)
+	return err
+}
+
+// replaceVersioned replaces the document only if its stored version is the
+// version given, returning a ConflictError when another update got there
+// first.  Documents stored before versioning have no version, which matches
+// version zero.  Like replaceOne, it isn't an error when the document doesn't
+// exist.
+func replaceVersioned(ctx context.Context, dbName, colName string,
+	id primitive.ObjectID, version int64, doc interface{}) error {
+	col, err := config.Collection(ctx, dbName, colName)
+	if err != nil {
+		return err
+	}
+	filter := bson.M{"_id": id, "version": version}
+	if version == 0 {
+		filter["version"] = bson.M{"$in": bson.A{0, nil}}
+	}
+	result, err := col.ReplaceOne(ctx, filter, doc)
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		count, err := col.CountDocuments(ctx, bson.M{"_id": id})
+		if err != nil {
+			return err
+		}
+		if count > 0 {
+			return &ConflictError{Collection: colName, ID: id, Version: version}
+		}
+	}
+	return nil
+}
+
+func deleteOne(ctx context.Context, dbName, colName string,
+	filter interface{}) (int64, error) {
+	col, err := config.Collection(ctx, dbName, colName)
+	if err != nil {
+		return 0, err
+	}
+	result, err := col.DeleteOne(ctx, filter)
+	if err != nil {
+		return 0, err
+	}
+	return result.DeletedCount, nil
+}
+
+func deleteMany(ctx context.Context, dbName, colName string,
+	filter interface{}) (int64, error) {
+	col, err := config.Collection(ctx, dbName, colName)
+	if err != nil {
+		return 0, err
+	}
+	result, err := col.DeleteMany(ctx, filter)
+	if err != nil {
+		return 0, err
+	}
+	return result.DeletedCount, nil
+}
+
+// dateRange adds a date range selection for the field to the filter, a zero
+// date leaves that end of the range open.  The end date is inclusive unless
+// exclusive is set.
+func dateRange(filter bson.M, field string, start, end time.Time,
+	exclusive bool) {
+	dates := bson.M{}
+	if !start.IsZero() {
+		dates["$gte"] = start
+	}
+	if !end.IsZero() {
+		if exclusive {
+			dates["$lt"] = end
+		} else {
+			dates["$lte"] = end
+		}
+	}
+	if len(dates) > 0 {
+		filter[field] = dates
+	}
+}
+
+// Employee storage
+
+func (s *MongoStore) InsertEmployee(ctx context.Context,
+	emp *employees.Employee) error {
+	return insertOne(ctx, config.DBScheduler, config.ColEmployees, emp)
+}
+
+func (s *MongoStore) FindEmployee(ctx context.Context,
+	id primitive.ObjectID) (*employees.Employee, error) {
+	var emp employees.Employee
+	err := findOne(ctx, config.DBScheduler, config.ColEmployees,
+		bson.M{"_id": id}, &emp)
+	if err != nil {
+		return nil, err
+	}
+	return &emp, nil
+}
+
+func (s *MongoStore) FindEmployeeByName(ctx context.Context,
+	teamid primitive.ObjectID, first, last string) (*employees.Employee, error) {
+	filter := bson.M{
+		"name.firstname": first,
+		"name.lastname":  last,
+	}
+	if !teamid.IsZero() {
+		filter["team"] = teamid
+	}
+	var emp employees.Employee
+	err := findOne(ctx, config.DBScheduler, config.ColEmployees, filter, &emp)
+	if err != nil {
+		return nil, err
+	}
+	return &emp, nil
+}
+
+func (s *MongoStore) FindEmployeeByFullName(ctx context.Context,
+	teamid primitive.ObjectID, first, middle,
+	last string) (*employees.Employee, error) {
+	filter := bson.M{
+		"name.firstname":  first,
+		"name.middlename": middle,
+		"name.lastname":   last,
+	}
+	if !teamid.IsZero() {
+		filter["team"] = teamid
+	}
+	var emp employees.Employee
+	err := findOne(ctx, config.DBScheduler, config.ColEmployees, filter, &emp)
+	if err != nil {
+		return nil, err
+	}
+	return &emp, nil
+}
+
+func (s *MongoStore) ListEmployees(ctx context.Context,
+	teamid primitive.ObjectID, siteid string) ([]employees.Employee, error) {
+	filter := bson.M{}
+	if !teamid.IsZero() {
+		filter["team"] = teamid
+	}
+	if siteid != "" {
+		filter["site"] = siteid
+	}
+	var emps []employees.Employee
+	err := findAll(ctx, config.DBScheduler, config.ColEmployees, filter, &emps)
+	return emps, err
+}
+
+func (s *MongoStore) ReplaceEmployee(ctx context.Context,
+	emp *employees.Employee) error {
+	emp.Version++
+	err := replaceVersioned(ctx, config.DBScheduler, config.ColEmployees, emp.ID,
+		emp.Version-1, emp)
+	if err != nil {
+		emp.Version--
+	}
+	return err
+}
+
+func (s *MongoStore) DeleteEmployee(ctx context.Context,
+	id primitive.ObjectID) (int64, error) {
+	return deleteOne(ctx, config.DBScheduler, config.ColEmployees,
+		bson.M{"_id": id})
+}
+
+// Team storage
+
+func (s *MongoStore) InsertTeam(ctx context.Context, team *teams.Team) error {
+	return insertOne(ctx, config.DBScheduler, config.ColTeams, team)
+}
+
+func (s *MongoStore) FindTeam(ctx context.Context,
+	id primitive.ObjectID) (*teams.Team, error) {
+	var team teams.Team
+	err := findOne(ctx, config.DBScheduler, config.ColTeams, bson.M{"_id": id},
+		&team)
+	if err != nil {
+		return nil, err
+	}
+	return &team, nil
+}
+
+func (s *MongoStore) FindTeamByName(ctx context.Context,
+	name string) (*teams.Team, error) {
+	var team teams.Team
+	err := findOne(ctx, config.DBScheduler, config.ColTeams, bson.M{"name": name},
+		&team)
+	if err != nil {
+		return nil, err
+	}
+	return &team, nil
+}
+
+func (s *MongoStore) ListTeams(ctx context.Context) ([]teams.Team, error) {
+	var list []teams.Team
+	err := findAll(ctx, config.DBScheduler, config.ColTeams, bson.M{}, &list)
+	return list, err
+}
+
+func (s *MongoStore) ReplaceTeam(ctx context.Context, team *teams.Team) error {
+	team.Version++
+	err := replaceVersioned(ctx, config.DBScheduler, config.ColTeams, team.ID,
+		team.Version-1, team)
+	if err != nil {
+		team.Version--
+	}
+	return err
+}
+
+func (s *MongoStore) DeleteTeam(ctx context.Context,
+	id primitive.ObjectID) (int64, error) {
+	return deleteOne(ctx, config.DBScheduler, config.ColTeams, bson.M{"_id": id})
+}
+
+// User storage
+
+func (s *MongoStore) InsertUser(ctx context.Context, user *users.User) error {
+	return insertOne(ctx, config.DBAuthenticate, config.ColUsers, user)
+}
+
+func (s *MongoStore) FindUser(ctx context.Context,
+	id primitive.ObjectID) (*users.User, error) {
+	var user users.User
+	err := findOne(ctx, config.DBAuthenticate, config.ColUsers, bson.M{"_id": id},
+		&user)
+	if err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+func (s *MongoStore) FindUserByEmail(ctx context.Context,
+	email string) (*users.User, error) {
+	var user users.User
+	err := findOne(ctx, config.DBAuthenticate, config.ColUsers,
+		bson.M{"emailAddress": email}, &user)
+	if err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+func (s *MongoStore) FindUserByName(ctx context.Context, first,
+	last string) (*users.User, error) {
+	filter := bson.M{
+		"firstName": first,
+		"lastName":  last,
+	}
+	var user users.User
+	err := findOne(ctx, config.DBAuthenticate, config.ColUsers, filter, &user)
+	if err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+func (s *MongoStore) FindUsers(ctx context.Context,
+	ids []primitive.ObjectID) ([]users.User, error) {
+	var list []users.User
+	if len(ids) == 0 {
+		return list, nil
+	}
+	err := findAll(ctx, config.DBAuthenticate, config.ColUsers,
+		bson.M{"_id": bson.M{"$in": ids}}, &list)
+	return list, err
+}
+
+func (s *MongoStore) ListUsers(ctx context.Context) ([]users.User, error) {
+	var list []users.User
+	err := findAll(ctx, config.DBAuthenticate, config.ColUsers, bson.M{}, &list)
+	return list, err
+}
+
+func (s *MongoStore) ReplaceUser(ctx context.Context, user *users.User) error {
+	return replaceOne(ctx, config.DBAuthenticate, config.ColUsers, bson.M{"_id": user.ID}, user)
+}
+
+func (s *MongoStore) DeleteUser(ctx context.Context,
+	id primitive.ObjectID) (int64, error) {
+	return deleteOne(ctx, config.DBAuthenticate, config.ColUsers,
+		bson.M{"_id": id})
+}
+
+// Employee work storage
+
+func (s *MongoStore) InsertWork(ctx context.Context,
+	work *employees.EmployeeWorkRecord) error {
+	return insertOne(ctx, config.DBScheduler, config.ColEmployeeWork, work)
+}
+
+func (s *MongoStore) FindWork(ctx context.Context, empID primitive.ObjectID,
+	year uint) (*employees.EmployeeWorkRecord, error) {
+	filter := bson.M{
+		"employeeID": empID,
+		"year":       year,
+	}
+	var work employees.EmployeeWorkRecord
+	err := findOne(ctx, config.DBScheduler, config.ColEmployeeWork, filter, &work)
+	if err != nil {
+		return nil, err
+	}
+	return &work, nil
+}
+
+func (s *MongoStore) ListWorkForEmployees(ctx context.Context,
+	empIDs []primitive.ObjectID,
+	years []uint) ([]employees.EmployeeWorkRecord, error) {
+	var list []employees.EmployeeWorkRecord
+	if len(empIDs) == 0 || len(years) == 0 {
+		return list, nil
+	}
+	filter := bson.M{
+		"employeeID": bson.M{"$in": empIDs},
+		"year":       bson.M{"$in": years},
+	}
+	err := findAll(ctx, config.DBScheduler, config.ColEmployeeWork, filter,
+		&list)
+	return list, err
+}
+
+func (s *MongoStore) ListWorkThrough(ctx context.Context, year uint,
+	empIDs []primitive.ObjectID) ([]employees.EmployeeWorkRecord, error) {
+	var list []employees.EmployeeWorkRecord
+	filter := bson.M{"year": bson.M{"$lte": year}}
+	if empIDs != nil {
+		filter["employeeID"] = bson.M{"$in": empIDs}
+	}
+	err := findAll(ctx, config.DBScheduler, config.ColEmployeeWork, filter,
+		&list)
+	return list, err
+}
+
+func (s *MongoStore) ReplaceWork(ctx context.Context,
+	work *employees.EmployeeWorkRecord) error {
+	return replaceOne(ctx, config.DBScheduler, config.ColEmployeeWork, bson.M{"_id": work.ID}, work)
+}
+
+func (s *MongoStore) DeleteWork(ctx context.Context, empID primitive.ObjectID,
+	year uint) (int64, error) {
+	filter := bson.M{
+		"employeeID": empID,
+		"year":       year,
+	}
+	return deleteOne(ctx, config.DBScheduler, config.ColEmployeeWork, filter)
+}
+
+// Employee audit storage
+
+func (s *MongoStore) InsertAuditEntries(ctx context.Context,
+	entries []employees.AuditEntry) error {
+	if len(entries) == 0 {
+		return nil
+	}
+	col, err := config.Collection(ctx, config.DBScheduler, config.ColEmployeeAudit)
+	if err != nil {
+		return err
+	}
+	docs := make([]interface{}, len(entries))
+	for i := range entries {
+		docs[i] = &entries[i]
+	}
+	_, err = col.InsertMany(ctx, docs)
+	return err
+}
+
+func (s *MongoStore) ListAuditEntries(ctx context.Context,
+	empID primitive.ObjectID, actor string,
+	start, end time.Time) ([]employees.AuditEntry, error) {
+	filter := bson.M{}
+	if !empID.IsZero() {
+		filter["employeeid"] = empID
+	}
+	if actor != "" {
+		filter["actor"] = actor
+	}
+	dateRange(filter, "date", start, end, false)
+	var list []employees.AuditEntry
+	err := findAll(ctx, config.DBScheduler, config.ColEmployeeAudit, filter, &list)
+	return list, err
+}
+
+func (s *MongoStore) PurgeAuditEntries(ctx context.Context,
+	before time.Time) (int64, error) {
+	return deleteMany(ctx, config.DBScheduler, config.ColEmployeeAudit,
+		bson.M{"date": bson.M{"$lt": before}})
+}
+
+// Shift swap storage
+
+func (s *MongoStore) InsertShiftSwap(ctx context.Context,
+	swap *employees.ShiftSwap) error {
+	return insertOne(ctx, config.DBScheduler, config.ColShiftSwaps, swap)
+}
+
+func (s *MongoStore) FindShiftSwap(ctx context.Context,
+	id primitive.ObjectID) (*employees.ShiftSwap, error) {
+	var swap employees.ShiftSwap
+	err := findOne(ctx, config.DBScheduler, config.ColShiftSwaps,
+		bson.M{"_id": id}, &swap)
+	if err != nil {
+		return nil, err
+	}
+	return &swap, nil
+}
+
+func (s *MongoStore) ListShiftSwaps(ctx context.Context, teamID,
+	empID primitive.ObjectID) ([]employees.ShiftSwap, error) {
+	filter := bson.M{}
+	if !teamID.IsZero() {
+		filter["team"] = teamID
+	}
+	if !empID.IsZero() {
+		filter["$or"] = bson.A{
+			bson.M{"requesterid": empID},
+			bson.M{"counterpartid": empID},
+		}
+	}
+	var list []employees.ShiftSwap
+	err := findAll(ctx, config.DBScheduler, config.ColShiftSwaps, filter, &list)
+	return list, err
+}
+
+func (s *MongoStore) ReplaceShiftSwap(ctx context.Context,
+	swap *employees.ShiftSwap) error {
+	return replaceOne(ctx, config.DBScheduler, config.ColShiftSwaps,
+		bson.M{"_id": swap.ID}, swap)
+}
+
+// Log storage
+
+func (s *MongoStore) InsertLogEntry(ctx context.Context,
+	entry *general.LogEntry) error {
+	return insertOne(ctx, config.DBGeneral, config.ColLogs, entry)
+}
+
+func (s *MongoStore) FindLogEntry(ctx context.Context,
+	id primitive.ObjectID) (*general.LogEntry, error) {
+	var entry general.LogEntry
+	err := findOne(ctx, config.DBGeneral, config.ColLogs, bson.M{"_id": id},
+		&entry)
+	if err != nil {
+		return nil, err
+	}
+	return &entry, nil
+}
+
+func (s *MongoStore) ListLogEntries(ctx context.Context, app, cat string,
+	start, end time.Time) ([]general.LogEntry, error) {
+	filter := bson.M{}
+	if app != "" {
+		filter["application"] = app
+	}
+	if cat != "" {
+		filter["category"] = cat
+	}
+	dateRange(filter, "entrydate", start, end, false)
+	var list []general.LogEntry
+	err := findAll(ctx, config.DBGeneral, config.ColLogs, filter, &list)
+	return list, err
+}
+
+func (s *MongoStore) ReplaceLogEntry(ctx context.Context,
+	entry *general.LogEntry) error {
+	return replaceOne(ctx, config.DBGeneral, config.ColLogs, bson.M{"_id": entry.ID}, entry)
+}
+
+func (s *MongoStore) DeleteLogEntry(ctx context.Context,
+	id primitive.ObjectID) (int64, error) {
+	return deleteOne(ctx, config.DBGeneral, config.ColLogs, bson.M{"_id": id})
+}
+
+func (s *MongoStore) DeleteLogEntries(ctx context.Context,
+	ids []primitive.ObjectID) (int64, error) {
+	if len(ids) == 0 {
+		return 0, nil
+	}
+	return deleteMany(ctx, config.DBGeneral, config.ColLogs,
+		bson.M{"_id": bson.M{"$in": ids}})
+}
+
+// Report storage
+
+func (s *MongoStore) InsertReport(ctx context.Context,
+	rpt *general.DBReport) error {
+	return insertOne(ctx, config.DBGeneral, config.ColReports, rpt)
+}
+
+func (s *MongoStore) FindReport(ctx context.Context,
+	id primitive.ObjectID) (*general.DBReport, error) {
+	var rpt general.DBReport
+	err := findOne(ctx, config.DBGeneral, config.ColReports, bson.M{"_id": id},
+		&rpt)
+	if err != nil {
+		return nil, err
+	}
+	return &rpt, nil
+}
+
+func (s *MongoStore) ListReports(ctx context.Context, typeID primitive.ObjectID,
+	start, end time.Time) ([]general.DBReport, error) {
+	filter := bson.M{}
+	if !typeID.IsZero() {
+		filter["reporttypeid"] = typeID
+	}
+	dateRange(filter, "reportdate", start, end, true)
+	var list []general.DBReport
+	err := findAll(ctx, config.DBGeneral, config.ColReports, filter, &list)
+	return list, err
+}
+
+func (s *MongoStore) ReplaceReport(ctx context.Context,
+	rpt *general.DBReport) error {
+	return replaceOne(ctx, config.DBGeneral, config.ColReports, bson.M{"_id": rpt.ID}, rpt)
+}
+
+func (s *MongoStore) DeleteReport(ctx context.Context,
+	id primitive.ObjectID) (int64, error) {
+	return deleteOne(ctx, config.DBGeneral, config.ColReports, bson.M{"_id": id})
+}
+
+func (s *MongoStore) DeleteReports(ctx context.Context,
+	ids []primitive.ObjectID) (int64, error) {
+	if len(ids) == 0 {
+		return 0, nil
+	}
+	return deleteMany(ctx, config.DBGeneral, config.ColReports,
+		bson.M{"_id": bson.M{"$in": ids}})
+}
+
+func (s *MongoStore) InsertReportType(ctx context.Context,
+	rptType *general.ReportType) error {
+	return insertOne(ctx, config.DBGeneral, config.ColReportTypes, rptType)
+}
+
+func (s *MongoStore) FindReportType(ctx context.Context,
+	id primitive.ObjectID) (*general.ReportType, error) {
+	var rptType general.ReportType
+	err := findOne(ctx, config.DBGeneral, config.ColReportTypes,
+		bson.M{"_id": id}, &rptType)
+	if err != nil {
+		return nil, err
+	}
+	return &rptType, nil
+}
+
+func (s *MongoStore) ListReportTypes(ctx context.Context,
+	app string) ([]general.ReportType, error) {
+	filter := bson.M{}
+	if app != "" {
+		filter["application"] = app
+	}
+	var list []general.ReportType
+	err := findAll(ctx, config.DBGeneral, config.ColReportTypes, filter, &list)
+	return list, err
+}
+
+func (s *MongoStore) ReplaceReportType(ctx context.Context,
+	rptType *general.ReportType) error {
+	return replaceOne(ctx, config.DBGeneral, config.ColReportTypes, bson.M{"_id": rptType.ID}, rptType)
+}
+
+func (s *MongoStore) DeleteReportType(ctx context.Context,
+	id primitive.ObjectID) (int64, error) {
+	return deleteOne(ctx, config.DBGeneral, config.ColReportTypes,
+		bson.M{"_id": id})
+}
+
+// Notification message storage
+
+func (s *MongoStore) InsertMessage(ctx context.Context,
+	msg *notifications.Notification) error {
+	return insertOne(ctx, config.DBScheduler, config.ColNotifications, msg)
+}
+
+func (s *MongoStore) FindMessage(ctx context.Context,
+	id primitive.ObjectID) (*notifications.Notification, error) {
+	var msg notifications.Notification
+	err := findOne(ctx, config.DBScheduler, config.ColNotifications,
+		bson.M{"_id": id}, &msg)
+	if err != nil {
+		return nil, err
+	}
+	return &msg, nil
+}
+
+func (s *MongoStore) ListMessages(ctx context.Context,
+	to string) ([]notifications.Notification, error) {
+	filter := bson.M{}
+	if to != "" {
+		filter["to"] = to
+	}
+	var list []notifications.Notification
+	err := findAll(ctx, config.DBScheduler, config.ColNotifications, filter,
+		&list)
+	return list, err
+}
+
+func (s *MongoStore) DeleteMessage(ctx context.Context,
+	id primitive.ObjectID) (int64, error) {
+	return deleteOne(ctx, config.DBScheduler, config.ColNotifications,
+		bson.M{"_id": id})
+}
+
+// Metrics storage
+
+func (s *MongoStore) InsertMission(ctx context.Context,
+	msn *metrics.Mission) error {
+	return insertOne(ctx, config.DBMetrics, config.ColMissions, msn)
+}
+
+func (s *MongoStore) ListMissions(ctx context.Context,
+	start, end time.Time) ([]metrics.Mission, error) {
+	filter := bson.M{}
+	dateRange(filter, "missionDate", start, end, false)
+	var list []metrics.Mission
+	err := findAll(ctx, config.DBMetrics, config.ColMissions, filter, &list)
+	return list, err
+}
+
+func (s *MongoStore) InsertGroundOutage(ctx context.Context,
+	outage *metrics.GroundOutage) error {
+	return insertOne(ctx, config.DBMetrics, config.ColOutages, outage)
+}
+
+func (s *MongoStore) ListGroundOutages(ctx context.Context,
+	start, end time.Time) ([]metrics.GroundOutage, error) {
+	filter := bson.M{}
+	dateRange(filter, "outageDate", start, end, false)
+	var list []metrics.GroundOutage
+	err := findAll(ctx, config.DBMetrics, config.ColOutages, filter, &list)
+	return list, err
+}
+
+// Archive storage
+
+func (s *MongoStore) InsertArchives(ctx context.Context,
+	recs []general.ArchiveRecord) error {
+	if len(recs) == 0 {
+		return nil
+	}
+	col, err := config.Collection(ctx, config.DBGeneral, config.ColArchive)
+	if err != nil {
+		return err
+	}
+	docs := make([]interface{}, len(recs))
+	for i := range recs {
+		docs[i] = &recs[i]
+	}
+	_, err = col.InsertMany(ctx, docs)
+	return err
+}
+
+func (s *MongoStore) ListArchives(ctx context.Context, collection string,
+	start, end time.Time) ([]general.ArchiveRecord, error) {
+	filter := bson.M{}
+	if collection != "" {
+		filter["collection"] = collection
+	}
+	dateRange(filter, "archivedate", start, end, false)
+	var list []general.ArchiveRecord
+	err := findAll(ctx, config.DBGeneral, config.ColArchive, filter, &list)
+	return list, err
+}
+
+// Migration storage
+
+func (s *MongoStore) InsertMigration(ctx context.Context,
+	rec *general.MigrationRecord) error {
+	return insertOne(ctx, config.DBGeneral, config.ColMigrations, rec)
+}
+
+func (s *MongoStore) ListMigrations(
+	ctx context.Context) ([]general.MigrationRecord, error) {
+	var list []general.MigrationRecord
+	err := findAll(ctx, config.DBGeneral, config.ColMigrations, bson.M{}, &list)
+	return list, err
+}
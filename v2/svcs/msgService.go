@@ -6,82 +6,76 @@ import (
 	"sort"
 	"time"
 
-	"github.com/erneap/models/v2/config"
 	"github.com/erneap/models/v2/notifications"
-	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
 // notification retrieve functions (All, by Employee, and single)
 
 func GetAllMessages() ([]notifications.Notification, error) {
-	noteCol := config.GetCollection(config.DB, "scheduler", "notifications")
-
-	var list []notifications.Notification
+	return GetAllMessagesContext(context.Background())
+}
 
-	cursor, err := noteCol.Find(context.TODO(), bson.M{})
+func GetAllMessagesContext(ctx context.Context) ([]notifications.Notification, error) {
+	ctx, cancel := readContext(ctx)
+	defer cancel()
+	list, err := store.ListMessages(ctx, "")
 	if err != nil {
 		return list, err
 	}
 
-	if err = cursor.All(context.TODO(), &list); err != nil {
-		return list, err
-	}
-
 	sort.Sort(notifications.ByNofication(list))
 
 	return list, nil
 }
 
 func GetMessagesByEmployee(id string) ([]notifications.Notification, error) {
-	noteCol := config.GetCollection(config.DB, "scheduler", "notifications")
-
-	var list []notifications.Notification
-
-	filter := bson.M{
-		"to": id,
-	}
+	return GetMessagesByEmployeeContext(context.Background(), id)
+}
 
-	cursor, err := noteCol.Find(context.TODO(), filter)
+func GetMessagesByEmployeeContext(ctx context.Context, id string) ([]notifications.Notification, error) {
+	ctx, cancel := readContext(ctx)
+	defer cancel()
+	list, err := store.ListMessages(ctx, id)
 	if err != nil {
 		return list, err
 	}
 
-	if err = cursor.All(context.TODO(), &list); err != nil {
-		return list, err
-	}
-
 	sort.Sort(notifications.ByNofication(list))
 
 	return list, nil
 }
 
 func GetMessage(id string) (notifications.Notification, error) {
-	noteCol := config.GetCollection(config.DB, "scheduler", "notifications")
+	return GetMessageContext(context.Background(), id)
+}
 
+func GetMessageContext(ctx context.Context, id string) (notifications.Notification, error) {
+	ctx, cancel := readContext(ctx)
+	defer cancel()
 	var answer notifications.Notification
 	mid, err := primitive.ObjectIDFromHex(id)
 	if err != nil {
 		return answer, err
 	}
 
-	filter := bson.M{
-		"_id": mid,
-	}
-
-	err = noteCol.FindOne(context.TODO(), filter).Decode(&answer)
+	msg, err := store.FindMessage(ctx, mid)
 	if err != nil {
 		return answer, err
 	}
 
-	return answer, nil
+	return *msg, nil
 }
 
 // Create function which include receipent, sender and message.
 // the identifier and date are automatic.
 func CreateMessage(to, from, message string) error {
-	noteCol := config.GetCollection(config.DB, "scheduler", "notifications")
+	return CreateMessageContext(context.Background(), to, from, message)
+}
 
+func CreateMessageContext(ctx context.Context, to, from, message string) error {
+	ctx, cancel := writeContext(ctx)
+	defer cancel()
 	msg := &notifications.Notification{
 		ID:      primitive.NewObjectID(),
 		Date:    time.Now().UTC(),
@@ -90,21 +84,18 @@ func CreateMessage(to, from, message string) error {
 		Message: message,
 	}
 
-	result, err := noteCol.InsertOne(context.TODO(), msg)
-	if err != nil {
-		return err
-	}
-	if result.InsertedID == primitive.NilObjectID {
-		return errors.New("not created")
-	}
-	return nil
+	return store.InsertMessage(ctx, msg)
 }
 
 // Create function which include receipent, sender and message.
 // the identifier and date are automatic.
 func CreateCriticalMessage(to, from, message string) error {
-	noteCol := config.GetCollection(config.DB, "scheduler", "notifications")
+	return CreateCriticalMessageContext(context.Background(), to, from, message)
+}
 
+func CreateCriticalMessageContext(ctx context.Context, to, from, message string) error {
+	ctx, cancel := writeContext(ctx)
+	defer cancel()
 	msg := &notifications.Notification{
 		ID:       primitive.NewObjectID(),
 		Date:     time.Now().UTC(),
@@ -114,14 +105,7 @@ func CreateCriticalMessage(to, from, message string) error {
 		Critical: true,
 	}
 
-	result, err := noteCol.InsertOne(context.TODO(), msg)
-	if err != nil {
-		return err
-	}
-	if result.InsertedID == primitive.NilObjectID {
-		return errors.New("not created")
-	}
-	return nil
+	return store.InsertMessage(ctx, msg)
 }
 
 // There is no update routine because messages can't be updated manually.
@@ -129,23 +113,23 @@ func CreateCriticalMessage(to, from, message string) error {
 // After the message is viewed, it will be acknowledged and removed
 // from the database.  This is the only delete routine.
 func DeleteMessage(id string) error {
-	noteCol := config.GetCollection(config.DB, "scheduler", "notifications")
+	return DeleteMessageContext(context.Background(), id)
+}
 
+func DeleteMessageContext(ctx context.Context, id string) error {
+	ctx, cancel := writeContext(ctx)
+	defer cancel()
 	mid, err := primitive.ObjectIDFromHex(id)
 	if err != nil {
 		return err
 	}
 
-	filter := bson.M{
-		"_id": mid,
-	}
-
-	result, err := noteCol.DeleteOne(context.TODO(), filter)
+	count, err := store.DeleteMessage(ctx, mid)
 	if err != nil {
 		return err
 	}
 
-	if result.DeletedCount <= 0 {
+	if count <= 0 {
 		return errors.New("no message deleted")
 	}
 
@@ -0,0 +1,108 @@
+package svcs
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/erneap/models/v2/employees"
+	"github.com/erneap/models/v2/labor"
+	"github.com/erneap/models/v2/teams"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// GetCompanyOvertime provides the regular, overtime and premium hours for the
+// pay periods from start through end of each of the team's employees working
+// for the company, by the company's overtime rules.
+func GetCompanyOvertime(teamid, companyid string, start,
+	end time.Time) ([]employees.OvertimePeriod, error) {
+	return GetCompanyOvertimeContext(context.Background(), teamid, companyid,
+		start, end)
+}
+
+func GetCompanyOvertimeContext(ctx context.Context, teamid, companyid string,
+	start, end time.Time) ([]employees.OvertimePeriod, error) {
+	ctx, cancel := readContext(ctx)
+	defer cancel()
+	team, err := GetTeamContext(ctx, teamid)
+	if err != nil {
+		return nil, err
+	}
+	co := companyOf(team, companyid)
+	if co == nil {
+		return nil, errors.New("company not found")
+	}
+	rules := co.GetOvertimeRules()
+	first, _ := rules.PayPeriodOf(start)
+	_, last := rules.PayPeriodOf(end)
+	emps, err := GetEmployeesWithOptionsContext(ctx, teamid, "",
+		EmployeeOptions{
+			WorkYears: WorkYears(first, last),
+		})
+	if err != nil {
+		return nil, err
+	}
+	var answer []employees.OvertimePeriod
+	for _, emp := range emps {
+		if strings.EqualFold(emp.CompanyInfo.Company, co.ID) {
+			answer = append(answer, emp.ComputeOvertime(rules,
+				co.GetHolidayDatesBetween(first, last), start, end)...)
+		}
+	}
+	return answer, nil
+}
+
+// GetEmployeeOvertime provides the employee's regular, overtime and premium
+// hours for the pay periods from start through end, by the overtime rules of
+// the employee's company (or the default rules when it has none).
+func GetEmployeeOvertime(id string, start,
+	end time.Time) ([]employees.OvertimePeriod, error) {
+	return GetEmployeeOvertimeContext(context.Background(), id, start, end)
+}
+
+func GetEmployeeOvertimeContext(ctx context.Context, id string, start,
+	end time.Time) ([]employees.OvertimePeriod, error) {
+	ctx, cancel := readContext(ctx)
+	defer cancel()
+	oEmpID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, err
+	}
+	emp, err := store.FindEmployee(ctx, oEmpID)
+	if err != nil {
+		return nil, err
+	}
+	team, err := GetTeamContext(ctx, emp.TeamID.Hex())
+	if err != nil {
+		return nil, err
+	}
+	co := companyOf(team, emp.CompanyInfo.Company)
+	rules := labor.DefaultOvertimeRules()
+	if co != nil {
+		rules = co.GetOvertimeRules()
+	}
+	first, _ := rules.PayPeriodOf(start)
+	_, last := rules.PayPeriodOf(end)
+	var holidays []time.Time
+	if co != nil {
+		holidays = co.GetHolidayDatesBetween(first, last)
+	}
+	emps := []employees.Employee{*emp}
+	err = LoadEmployeeDetailsContext(ctx, emps, EmployeeOptions{
+		WorkYears: WorkYears(first, last),
+	})
+	if err != nil {
+		return nil, err
+	}
+	return emps[0].ComputeOvertime(rules, holidays, start, end), nil
+}
+
+func companyOf(team *teams.Team, companyid string) *teams.Company {
+	for c := range team.Companies {
+		if strings.EqualFold(team.Companies[c].ID, companyid) {
+			return &team.Companies[c]
+		}
+	}
+	return nil
+}
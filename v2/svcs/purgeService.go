@@ -0,0 +1,171 @@
+package svcs
+
+import (
+	"context"
+	"time"
+
+	"github.com/erneap/models/v2/config"
+	"github.com/erneap/models/v2/employees"
+	"github.com/erneap/models/v2/teams"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// The purge functions remove the scheduler data from before the purge date.
+// Each runs as a single transaction, so a failure part way through leaves the
+// data as it was instead of partly purged.  The retention policies (see
+// ApplyRetention) use the same purges.
+
+// PurgeEmployeeWork removes the work records from before the purge date, any
+// employee work record left empty is removed.
+func PurgeEmployeeWork(purgeDate time.Time) error {
+	return PurgeEmployeeWorkContext(context.Background(), purgeDate)
+}
+
+func PurgeEmployeeWorkContext(ctx context.Context, purgeDate time.Time) error {
+	ctx, cancel := writeContext(ctx)
+	defer cancel()
+	return WithTransaction(ctx, func(ctx context.Context) error {
+		return purgeWork(ctx, purgeDate, nil, nil, &retention{})
+	})
+}
+
+// purgeWork purges the work records through the purge date's year of the
+// employees listed (every employee for a nil list), removing all the records
+// of the employees removed.  A zero purge date only removes the records of
+// the employees removed, through the current year.
+func purgeWork(ctx context.Context, purgeDate time.Time,
+	empIDs []primitive.ObjectID, removed map[primitive.ObjectID]bool,
+	r *retention) error {
+	year := purgeDate.Year()
+	if purgeDate.IsZero() {
+		year = time.Now().UTC().Year()
+	}
+	records, err := store.ListWorkThrough(ctx, uint(year), empIDs)
+	if err != nil {
+		return err
+	}
+	for _, rec := range records {
+		orig := rec
+		orig.Work = append([]employees.Work{}, rec.Work...)
+		remove := removed[rec.EmployeeID]
+		if !purgeDate.IsZero() {
+			rec.Purge(purgeDate)
+			remove = remove || len(rec.Work) == 0
+		}
+		if !remove && len(rec.Work) == len(orig.Work) {
+			continue
+		}
+		if err = r.archiveDocuments(ctx, config.ColEmployeeWork,
+			&orig); err != nil {
+			return err
+		}
+		if remove {
+			r.result.Removed++
+			if !r.dryRun {
+				_, err = store.DeleteWork(ctx, rec.EmployeeID, rec.Year)
+			}
+		} else {
+			r.result.Changed++
+			if !r.dryRun {
+				err = store.ReplaceWork(ctx, &rec)
+			}
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// PurgeTeamData purges the team's company holidays and its employees' leave,
+// variations and balances from before the purge date.  Employees whose last
+// assignment ended before the purge date are removed with their work records,
+// and the team's other work records are purged as PurgeEmployeeWork does.
+func PurgeTeamData(teamid string, purgeDate time.Time) error {
+	return PurgeTeamDataContext(context.Background(), teamid, purgeDate)
+}
+
+func PurgeTeamDataContext(ctx context.Context, teamid string,
+	purgeDate time.Time) error {
+	ctx, cancel := writeContext(ctx)
+	defer cancel()
+	return WithTransaction(ctx, func(ctx context.Context) error {
+		team, err := GetTeamContext(ctx, teamid)
+		if err != nil {
+			return err
+		}
+		// the team's employees are listed before purgeTeam removes any.
+		emps, err := store.ListEmployees(ctx, team.ID, "")
+		if err != nil {
+			return err
+		}
+		empIDs := make([]primitive.ObjectID, 0, len(emps))
+		for _, emp := range emps {
+			empIDs = append(empIDs, emp.ID)
+		}
+		removed, err := purgeTeam(ctx, team, purgeDate, &retention{})
+		if err != nil {
+			return err
+		}
+		return purgeWork(ctx, purgeDate, empIDs, removed, &retention{})
+	})
+}
+
+// purgeTeam purges the team and its employees, providing the employees
+// removed.  The employees' changes and removals are audited, by the system
+// when the context has no actor.  The employees' work records aren't
+// changed.
+func purgeTeam(ctx context.Context, team *teams.Team, purgeDate time.Time,
+	r *retention) (map[primitive.ObjectID]bool, error) {
+	if GetActor(ctx) == "" {
+		ctx = WithActor(ctx, SystemActor)
+	}
+	team.PurgeOldData(purgeDate)
+	if !r.dryRun {
+		if err := store.ReplaceTeam(ctx, team); err != nil {
+			return nil, err
+		}
+	}
+
+	emps, err := store.ListEmployees(ctx, team.ID, "")
+	if err != nil {
+		return nil, err
+	}
+	removed := make(map[primitive.ObjectID]bool)
+	for _, emp := range emps {
+		orig, err := bson.Marshal(&emp)
+		if err != nil {
+			return nil, err
+		}
+		var prev employees.Employee
+		if err = bson.Unmarshal(orig, &prev); err != nil {
+			return nil, err
+		}
+		if emp.PurgeOldData(purgeDate) {
+			if err = r.archiveDocuments(ctx, config.ColEmployees,
+				bson.Raw(orig)); err != nil {
+				return nil, err
+			}
+			r.result.Removed++
+			removed[emp.ID] = true
+			if !r.dryRun {
+				if err = deleteEmployee(ctx, emp.ID); err != nil {
+					return nil, err
+				}
+			}
+		} else if len(emp.GetChanges(&prev)) > 0 {
+			if err = r.archiveDocuments(ctx, config.ColEmployees,
+				bson.Raw(orig)); err != nil {
+				return nil, err
+			}
+			r.result.Changed++
+			if !r.dryRun {
+				if err = replaceEmployee(ctx, &emp); err != nil {
+					return nil, err
+				}
+			}
+		}
+	}
+	return removed, nil
+}
@@ -0,0 +1,93 @@
+package svcs
+
+import (
+	"testing"
+	"time"
+
+	"github.com/erneap/models/v2/employees"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestPurgeTeamData(t *testing.T) {
+	SetStore(NewMemoryStore())
+	day := func(year int, month time.Month, d int) time.Time {
+		return time.Date(year, month, d, 0, 0, 0, 0, time.UTC)
+	}
+	newEmployee := func(teamid, last string, end time.Time) *employees.Employee {
+		emp := employees.Employee{
+			Name: employees.EmployeeName{FirstName: "P", LastName: last},
+		}
+		emp.AddAssignment("site", "wc", day(2015, time.January, 1))
+		if !end.IsZero() {
+			emp.Assignments[0].EndDate = end
+		}
+		e, err := CreateEmployee(emp, "pw", "", teamid, "site")
+		if err != nil {
+			t.Fatal(err)
+		}
+		return e
+	}
+	addWork := func(emp *employees.Employee, year int, dates ...time.Time) {
+		rec := employees.EmployeeWorkRecord{
+			ID:         primitive.NewObjectID(),
+			EmployeeID: emp.ID,
+			Year:       uint(year),
+		}
+		for _, d := range dates {
+			rec.Work = append(rec.Work, employees.Work{DateWorked: d, Hours: 8})
+		}
+		if err := CreateEmployeeWork(&rec); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	purged := CreateTeam("purged", false)
+	kept := CreateTeam("kept", false)
+	quit := newEmployee(purged.ID.Hex(), "Quit", day(2018, time.January, 1))
+	working := newEmployee(purged.ID.Hex(), "Working", time.Time{})
+	other := newEmployee(kept.ID.Hex(), "Other", time.Time{})
+	addWork(quit, 2017, day(2017, time.March, 1))
+	addWork(working, 2019, day(2019, time.March, 1), day(2019, time.August, 1))
+	addWork(other, 2019, day(2019, time.March, 1), day(2019, time.August, 1))
+
+	if err := PurgeTeamData(purged.ID.Hex(), day(2019, time.June, 1)); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name string
+		emp  *employees.Employee
+		year uint
+		// the work left, or -1 when the employee is removed.
+		work int
+	}{
+		{name: "employee who left", emp: quit, year: 2017, work: -1},
+		{name: "employee still working", emp: working, year: 2019, work: 1},
+		{name: "another team's employee", emp: other, year: 2019, work: 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := GetEmployee(tt.emp.ID.Hex())
+			rec, werr := GetEmployeeWork(tt.emp.ID.Hex(), tt.year)
+			if tt.work < 0 {
+				if err == nil || werr == nil {
+					t.Errorf("employee or work remains: %v, %v", err, werr)
+				}
+				// the removal is audited by the system.
+				entries, err := GetAuditEntriesByEmployee(tt.emp.ID.Hex())
+				if err != nil || len(entries) != 1 ||
+					entries[0].Actor != SystemActor ||
+					entries[0].OldValue == "" || entries[0].NewValue != "" {
+					t.Errorf("audit entries = %+v, %v", entries, err)
+				}
+				return
+			}
+			if err != nil || werr != nil {
+				t.Fatal(err, werr)
+			}
+			if len(rec.Work) != tt.work {
+				t.Errorf("work = %d, want %d", len(rec.Work), tt.work)
+			}
+		})
+	}
+}
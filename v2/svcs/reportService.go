@@ -7,11 +7,16 @@ import (
 
 	"github.com/erneap/models/v2/config"
 	"github.com/erneap/models/v2/general"
-	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
 func AddReport(typeid, subtype, mimetype string, body []byte) (*general.DBReport, error) {
+	return AddReportContext(context.Background(), typeid, subtype, mimetype, body)
+}
+
+func AddReportContext(ctx context.Context, typeid, subtype, mimetype string, body []byte) (*general.DBReport, error) {
+	ctx, cancel := writeContext(ctx)
+	defer cancel()
 	now := time.Now().UTC()
 	oTypeID, err := primitive.ObjectIDFromHex(typeid)
 	if err != nil {
@@ -26,15 +31,20 @@ func AddReport(typeid, subtype, mimetype string, body []byte) (*general.DBReport
 	}
 	rpt.SetDocument(body)
 
-	rptCol := config.GetCollection(config.DB, "general", "reports")
-
-	rptCol.InsertOne(context.TODO(), rpt)
+	store.InsertReport(ctx, rpt)
 
 	return rpt, nil
 }
 
 func AddReportWithDate(dt time.Time, typeid, subtype,
 	mimetype string, body []byte) (*general.DBReport, error) {
+	return AddReportWithDateContext(context.Background(), dt, typeid, subtype, mimetype, body)
+}
+
+func AddReportWithDateContext(ctx context.Context, dt time.Time, typeid, subtype,
+	mimetype string, body []byte) (*general.DBReport, error) {
+	ctx, cancel := writeContext(ctx)
+	defer cancel()
 	oTypeID, err := primitive.ObjectIDFromHex(typeid)
 	if err != nil {
 		return nil, err
@@ -48,27 +58,24 @@ func AddReportWithDate(dt time.Time, typeid, subtype,
 	}
 	rpt.SetDocument(body)
 
-	rptCol := config.GetCollection(config.DB, "general", "reports")
-
-	rptCol.InsertOne(context.TODO(), rpt)
+	store.InsertReport(ctx, rpt)
 
 	return rpt, nil
 }
 
 func UpdateReport(id, mimetype string, body []byte) (*general.DBReport, error) {
-	rptCol := config.GetCollection(config.DB, "general", "reports")
+	return UpdateReportContext(context.Background(), id, mimetype, body)
+}
 
+func UpdateReportContext(ctx context.Context, id, mimetype string, body []byte) (*general.DBReport, error) {
+	ctx, cancel := writeContext(ctx)
+	defer cancel()
 	oId, err := primitive.ObjectIDFromHex(id)
 	if err != nil {
 		return nil, err
 	}
 
-	filter := bson.M{
-		"_id": oId,
-	}
-
-	var rpt *general.DBReport
-	err = rptCol.FindOne(context.TODO(), filter).Decode(&rpt)
+	rpt, err := store.FindReport(ctx, oId)
 	if err != nil {
 		return nil, err
 	}
@@ -76,7 +83,7 @@ func UpdateReport(id, mimetype string, body []byte) (*general.DBReport, error) {
 	rpt.ReportDate = time.Now().UTC()
 	rpt.MimeType = mimetype
 	rpt.SetDocument(body)
-	_, err = rptCol.ReplaceOne(context.TODO(), filter, rpt)
+	err = store.ReplaceReport(ctx, rpt)
 	if err != nil {
 		return nil, err
 	}
@@ -84,148 +91,131 @@ func UpdateReport(id, mimetype string, body []byte) (*general.DBReport, error) {
 }
 
 func DeleteReport(id string) error {
-	rptCol := config.GetCollection(config.DB, "general", "reports")
+	return DeleteReportContext(context.Background(), id)
+}
 
+func DeleteReportContext(ctx context.Context, id string) error {
+	ctx, cancel := writeContext(ctx)
+	defer cancel()
 	oId, err := primitive.ObjectIDFromHex(id)
 	if err != nil {
 		return err
 	}
 
-	filter := bson.M{
-		"_id": oId,
-	}
-
-	_, err = rptCol.DeleteOne(context.TODO(), filter)
+	_, err = store.DeleteReport(ctx, oId)
 	return err
 }
 
+// PurgeReports removes the reports from before the date given, through the
+// retention engine (see ApplyRetention).
 func PurgeReports(dt time.Time) error {
-	rptCol := config.GetCollection(config.DB, "general", "reports")
-
-	filter := bson.M{
-		"reportdate": bson.M{"$lt": dt},
-	}
+	return PurgeReportsContext(context.Background(), dt)
+}
 
-	_, err := rptCol.DeleteMany(context.TODO(), filter)
+func PurgeReportsContext(ctx context.Context, dt time.Time) error {
+	_, err := ApplyRetentionContext(ctx, []RetentionPolicy{
+		{Collection: config.ColReports, Before: dt},
+	}, time.Now().UTC(), false)
 	return err
 }
 
 func GetReport(id string) (*general.DBReport, error) {
-	rptCol := config.GetCollection(config.DB, "general", "reports")
+	return GetReportContext(context.Background(), id)
+}
 
+func GetReportContext(ctx context.Context, id string) (*general.DBReport, error) {
+	ctx, cancel := readContext(ctx)
+	defer cancel()
 	oId, err := primitive.ObjectIDFromHex(id)
 	if err != nil {
 		return nil, err
 	}
 
-	filter := bson.M{
-		"_id": oId,
-	}
-
-	var rpt *general.DBReport
-	err = rptCol.FindOne(context.TODO(), filter).Decode(&rpt)
-	if err != nil {
-		return nil, err
-	}
-	return rpt, nil
+	return store.FindReport(ctx, oId)
 }
 
 func GetReportsByType(id string) ([]general.DBReport, error) {
-	rptCol := config.GetCollection(config.DB, "general", "reports")
+	return GetReportsByTypeContext(context.Background(), id)
+}
+
+func GetReportsByTypeContext(ctx context.Context, id string) ([]general.DBReport, error) {
+	ctx, cancel := readContext(ctx)
+	defer cancel()
 	oTypeID, err := primitive.ObjectIDFromHex(id)
 	if err != nil {
 		return nil, err
 	}
 
-	filter := bson.M{
-		"reporttypeid": oTypeID,
-	}
-
-	var rpts []general.DBReport
-
-	cursor, err := rptCol.Find(context.TODO(), filter)
+	rpts, err := store.ListReports(ctx, oTypeID, time.Time{},
+		time.Time{})
 	if err != nil {
 		return rpts, err
 	}
-
-	if err = cursor.All(context.TODO(), &rpts); err != nil {
-		return rpts, err
-	}
 	sort.Sort(general.ByDBReports(rpts))
 	return rpts, nil
 }
 
 func GetReportsBetweenDates(date1, date2 time.Time) ([]general.DBReport, error) {
-	rptCol := config.GetCollection(config.DB, "general", "reports")
-
-	filter := bson.M{
-		"reportdate": bson.M{"$gte": primitive.NewDateTimeFromTime(date1),
-			"$lte": primitive.NewDateTimeFromTime(date2.AddDate(0, 0, 1))},
-	}
-
-	var rpts []general.DBReport
+	return GetReportsBetweenDatesContext(context.Background(), date1, date2)
+}
 
-	cursor, err := rptCol.Find(context.TODO(), filter)
+func GetReportsBetweenDatesContext(ctx context.Context, date1, date2 time.Time) ([]general.DBReport, error) {
+	ctx, cancel := readContext(ctx)
+	defer cancel()
+	rpts, err := store.ListReports(ctx, primitive.NilObjectID, date1,
+		date2.AddDate(0, 0, 1))
 	if err != nil {
 		return rpts, err
 	}
-
-	if err = cursor.All(context.TODO(), &rpts); err != nil {
-		return rpts, err
-	}
 	sort.Sort(general.ByDBReports(rpts))
 	return rpts, nil
 }
 
 func GetReportsByTypeAndDates(id string, date1, date2 time.Time) ([]general.DBReport, error) {
-	rptCol := config.GetCollection(config.DB, "general", "reports")
+	return GetReportsByTypeAndDatesContext(context.Background(), id, date1, date2)
+}
+
+func GetReportsByTypeAndDatesContext(ctx context.Context, id string, date1, date2 time.Time) ([]general.DBReport, error) {
+	ctx, cancel := readContext(ctx)
+	defer cancel()
 	oTypeID, err := primitive.ObjectIDFromHex(id)
 	if err != nil {
 		return nil, err
 	}
-	filter := bson.M{
-		"reporttypeid": oTypeID,
-		"reportdate": bson.M{"$gte": primitive.NewDateTimeFromTime(date1),
-			"$lt": primitive.NewDateTimeFromTime(date2.AddDate(0, 0, 1))},
-	}
-
-	var rpts []general.DBReport
 
-	cursor, err := rptCol.Find(context.TODO(), filter)
+	rpts, err := store.ListReports(ctx, oTypeID, date1,
+		date2.AddDate(0, 0, 1))
 	if err != nil {
 		return rpts, err
 	}
-
-	if err = cursor.All(context.TODO(), &rpts); err != nil {
-		return rpts, err
-	}
 	sort.Sort(general.ByDBReports(rpts))
 	return rpts, nil
 }
 
 func GetReportsAll() ([]general.DBReport, error) {
-	rptCol := config.GetCollection(config.DB, "general", "reports")
-
-	filter := bson.M{}
-
-	var rpts []general.DBReport
+	return GetReportsAllContext(context.Background())
+}
 
-	cursor, err := rptCol.Find(context.TODO(), filter)
+func GetReportsAllContext(ctx context.Context) ([]general.DBReport, error) {
+	ctx, cancel := readContext(ctx)
+	defer cancel()
+	rpts, err := store.ListReports(ctx, primitive.NilObjectID,
+		time.Time{}, time.Time{})
 	if err != nil {
 		return rpts, err
 	}
-
-	if err = cursor.All(context.TODO(), &rpts); err != nil {
-		return rpts, err
-	}
 	sort.Sort(general.ByDBReports(rpts))
 	return rpts, nil
 }
 
 // CRUD methods for report types
 func CreateReportType(app, name, rpttype string, subtypes []string) (*general.ReportType, error) {
-	rptCol := config.GetCollection(config.DB, "general", "reporttypes")
+	return CreateReportTypeContext(context.Background(), app, name, rpttype, subtypes)
+}
 
+func CreateReportTypeContext(ctx context.Context, app, name, rpttype string, subtypes []string) (*general.ReportType, error) {
+	ctx, cancel := writeContext(ctx)
+	defer cancel()
 	rpt := &general.ReportType{
 		ID:             primitive.NewObjectID(),
 		Application:    app,
@@ -234,7 +224,7 @@ func CreateReportType(app, name, rpttype string, subtypes []string) (*general.Re
 		SubTypes:       subtypes,
 	}
 
-	_, err := rptCol.InsertOne(context.TODO(), rpt)
+	err := store.InsertReportType(ctx, rpt)
 	if err != nil {
 		return nil, err
 	}
@@ -244,19 +234,19 @@ func CreateReportType(app, name, rpttype string, subtypes []string) (*general.Re
 
 func UpdateReportType(id, app, name, rpttype string,
 	subtypes []string) (*general.ReportType, error) {
-	rptCol := config.GetCollection(config.DB, "general", "reporttypes")
+	return UpdateReportTypeContext(context.Background(), id, app, name, rpttype, subtypes)
+}
 
+func UpdateReportTypeContext(ctx context.Context, id, app, name, rpttype string,
+	subtypes []string) (*general.ReportType, error) {
+	ctx, cancel := writeContext(ctx)
+	defer cancel()
 	oID, err := primitive.ObjectIDFromHex(id)
 	if err != nil {
 		return nil, err
 	}
 
-	filter := bson.M{
-		"_id": oID,
-	}
-
-	var rpt general.ReportType
-	err = rptCol.FindOne(context.TODO(), filter).Decode(&rpt)
+	rpt, err := store.FindReportType(ctx, oID)
 	if err != nil {
 		return nil, err
 	}
@@ -266,27 +256,27 @@ func UpdateReportType(id, app, name, rpttype string,
 	rpt.ReportType = rpttype
 	rpt.SubTypes = subtypes
 
-	_, err = rptCol.ReplaceOne(context.TODO(), filter, rpt)
+	err = store.ReplaceReportType(ctx, rpt)
 	if err != nil {
 		return nil, err
 	}
 
-	return &rpt, nil
+	return rpt, nil
 }
 
 func DeleteReportType(id string) error {
-	rptCol := config.GetCollection(config.DB, "general", "reporttypes")
+	return DeleteReportTypeContext(context.Background(), id)
+}
 
+func DeleteReportTypeContext(ctx context.Context, id string) error {
+	ctx, cancel := writeContext(ctx)
+	defer cancel()
 	oID, err := primitive.ObjectIDFromHex(id)
 	if err != nil {
 		return err
 	}
 
-	filter := bson.M{
-		"_id": oID,
-	}
-
-	_, err = rptCol.DeleteOne(context.TODO(), filter)
+	_, err = store.DeleteReportType(ctx, oID)
 	if err != nil {
 		return err
 	}
@@ -295,61 +285,48 @@ func DeleteReportType(id string) error {
 }
 
 func GetReportTypes() ([]general.ReportType, error) {
-	rptCol := config.GetCollection(config.DB, "general", "reporttypes")
-
-	filter := bson.M{}
-
-	var rpts []general.ReportType
+	return GetReportTypesContext(context.Background())
+}
 
-	cursor, err := rptCol.Find(context.TODO(), filter)
+func GetReportTypesContext(ctx context.Context) ([]general.ReportType, error) {
+	ctx, cancel := readContext(ctx)
+	defer cancel()
+	rpts, err := store.ListReportTypes(ctx, "")
 	if err != nil {
 		return rpts, err
 	}
-
-	if err = cursor.All(context.TODO(), &rpts); err != nil {
-		return rpts, err
-	}
 	sort.Sort(general.ByReportTypes(rpts))
 	return rpts, nil
 
 }
 
 func GetReportTypesByApplication(app string) ([]general.ReportType, error) {
-	rptCol := config.GetCollection(config.DB, "general", "reporttypes")
-
-	filter := bson.M{
-		"application": app,
-	}
-
-	var rpts []general.ReportType
+	return GetReportTypesByApplicationContext(context.Background(), app)
+}
 
-	cursor, err := rptCol.Find(context.TODO(), filter)
+func GetReportTypesByApplicationContext(ctx context.Context, app string) ([]general.ReportType, error) {
+	ctx, cancel := readContext(ctx)
+	defer cancel()
+	rpts, err := store.ListReportTypes(ctx, app)
 	if err != nil {
 		return rpts, err
 	}
-
-	if err = cursor.All(context.TODO(), &rpts); err != nil {
-		return rpts, err
-	}
 	sort.Sort(general.ByReportTypes(rpts))
 	return rpts, nil
 
 }
 
 func GetReportType(id string) (*general.ReportType, error) {
-	rptCol := config.GetCollection(config.DB, "general", "reporttypes")
+	return GetReportTypeContext(context.Background(), id)
+}
+
+func GetReportTypeContext(ctx context.Context, id string) (*general.ReportType, error) {
+	ctx, cancel := readContext(ctx)
+	defer cancel()
 	oId, err := primitive.ObjectIDFromHex(id)
 	if err != nil {
 		return nil, err
 	}
 
-	filter := bson.M{
-		"_id": oId,
-	}
-	var rpt general.ReportType
-	err = rptCol.FindOne(context.TODO(), filter).Decode(&rpt)
-	if err != nil {
-		return nil, err
-	}
-	return &rpt, nil
+	return store.FindReportType(ctx, oId)
 }
@@ -0,0 +1,427 @@
+package svcs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/erneap/models/v2/config"
+	"github.com/erneap/models/v2/general"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// The retention policies say how long each kind of stored data is kept.  A
+// scheduler job calls ApplyRetention with the policies (usually read from the
+// application's configuration), which removes everything older than each
+// policy keeps, after copying it to the archive collection when the policy
+// asks for that.  Running it as a dry run reports what would be removed
+// without changing anything.
+//
+// The logs and reports can have several policies: one for the collection,
+// and more specific ones by application, and for the reports by report type.
+// Each document follows the most specific policy that matches it (report
+// type, then application, then the collection's policy).  The employees
+// policy purges every team's holidays and its employees' leave, variations
+// and balances, removing the employees who left (with their work records).
+
+// DefaultRetentionTimeout limits ApplyRetention when the caller's context has
+// no deadline.
+var DefaultRetentionTimeout = 10 * time.Minute
+
+// RetentionPolicy keeps the documents of a collection (logs, reports,
+// employeework, employeeaudit or employees) for a number of years, counted in
+// whole years back from the start of the year being applied, or from the
+// Before date when it is given.  A policy keeping zero years keeps the
+// documents forever, which lets an application or report type be kept when
+// the rest of the collection isn't.
+type RetentionPolicy struct {
+	Collection  string    `json:"collection"`
+	Application string    `json:"application,omitempty"`
+	ReportType  string    `json:"reporttype,omitempty"`
+	KeepYears   int       `json:"keepyears"`
+	Before      time.Time `json:"before,omitempty"`
+	Archive     bool      `json:"archive"`
+}
+
+// Cutoff provides the date before which the policy removes documents, or a
+// zero date when it keeps them forever.
+func (p *RetentionPolicy) Cutoff(asOf time.Time) time.Time {
+	if !p.Before.IsZero() {
+		return p.Before
+	}
+	if p.KeepYears <= 0 {
+		return time.Time{}
+	}
+	return time.Date(asOf.Year()-p.KeepYears, 1, 1, 0, 0, 0, 0, time.UTC)
+}
+
+// RetentionResult tells what a policy did (or would do for a dry run):  the
+// documents removed, the documents with old data trimmed from them (work
+// records and employees), and the copies archived.
+type RetentionResult struct {
+	Collection  string    `json:"collection"`
+	Application string    `json:"application,omitempty"`
+	ReportType  string    `json:"reporttype,omitempty"`
+	Cutoff      time.Time `json:"cutoff"`
+	Removed     int64     `json:"removed"`
+	Changed     int64     `json:"changed"`
+	Archived    int64     `json:"archived"`
+}
+
+// RetentionReport has a result for each policy applied, in policy order.
+type RetentionReport struct {
+	AsOf    time.Time         `json:"asof"`
+	DryRun  bool              `json:"dryrun"`
+	Results []RetentionResult `json:"results"`
+}
+
+// retention carries a policy's settings and result through the purges.
+type retention struct {
+	dryRun  bool
+	archive bool
+	result  RetentionResult
+}
+
+// archiveDocuments copies the documents to the archive collection when the
+// policy archives, counting them.
+func (r *retention) archiveDocuments(ctx context.Context, collection string,
+	docs ...interface{}) error {
+	if !r.archive || len(docs) == 0 {
+		return nil
+	}
+	r.result.Archived += int64(len(docs))
+	if r.dryRun {
+		return nil
+	}
+	now := time.Now().UTC()
+	recs := make([]general.ArchiveRecord, 0, len(docs))
+	for _, doc := range docs {
+		data, ok := doc.(bson.Raw)
+		if !ok {
+			var err error
+			if data, err = bson.Marshal(doc); err != nil {
+				return err
+			}
+		}
+		recs = append(recs, general.ArchiveRecord{
+			ID:          primitive.NewObjectID(),
+			Collection:  collection,
+			ArchiveDate: now,
+			Document:    data,
+		})
+	}
+	return store.InsertArchives(ctx, recs)
+}
+
+// ApplyRetention applies the retention policies as of the date given, each
+// policy in its own transaction.
+func ApplyRetention(policies []RetentionPolicy, asOf time.Time,
+	dryRun bool) (*RetentionReport, error) {
+	return ApplyRetentionContext(context.Background(), policies, asOf, dryRun)
+}
+
+func ApplyRetentionContext(ctx context.Context, policies []RetentionPolicy,
+	asOf time.Time, dryRun bool) (*RetentionReport, error) {
+	ctx, cancel := withDefaultTimeout(ctx, DefaultRetentionTimeout)
+	defer cancel()
+	if err := validateRetention(policies); err != nil {
+		return nil, err
+	}
+
+	runs := make([]*retention, len(policies))
+	for i, p := range policies {
+		runs[i] = &retention{
+			dryRun:  dryRun,
+			archive: p.Archive,
+			result: RetentionResult{
+				Collection:  p.Collection,
+				Application: p.Application,
+				ReportType:  p.ReportType,
+				Cutoff:      p.Cutoff(asOf),
+			},
+		}
+	}
+
+	var err error
+	for i, p := range policies {
+		if runs[i].result.Cutoff.IsZero() {
+			continue
+		}
+		switch p.Collection {
+		case config.ColEmployeeWork:
+			err = runRetention(ctx, runs[i], func(ctx context.Context) error {
+				return purgeWork(ctx, runs[i].result.Cutoff, nil, nil, runs[i])
+			})
+		case config.ColEmployees:
+			err = runRetention(ctx, runs[i], func(ctx context.Context) error {
+				return retainEmployees(ctx, runs[i])
+			})
+		case config.ColEmployeeAudit:
+			err = runRetention(ctx, runs[i], func(ctx context.Context) error {
+				return retainAuditEntries(ctx, runs[i])
+			})
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%s retention: %w", p.Collection, err)
+		}
+	}
+	if err = retainLogEntries(ctx, policies, runs); err != nil {
+		return nil, fmt.Errorf("%s retention: %w", config.ColLogs, err)
+	}
+	if err = retainReports(ctx, policies, runs); err != nil {
+		return nil, fmt.Errorf("%s retention: %w", config.ColReports, err)
+	}
+
+	answer := &RetentionReport{
+		AsOf:   asOf,
+		DryRun: dryRun,
+	}
+	for _, r := range runs {
+		answer.Results = append(answer.Results, r.result)
+	}
+	return answer, nil
+}
+
+func validateRetention(policies []RetentionPolicy) error {
+	used := make(map[string]bool)
+	for _, p := range policies {
+		switch p.Collection {
+		case config.ColLogs, config.ColReports:
+		case config.ColEmployeeWork, config.ColEmployeeAudit,
+			config.ColEmployees:
+			if p.Application != "" {
+				return fmt.Errorf("%s retention can't be by application",
+					p.Collection)
+			}
+		default:
+			return fmt.Errorf("no retention for collection %q", p.Collection)
+		}
+		if p.ReportType != "" && p.Collection != config.ColReports {
+			return errors.New("only reports retention can be by report type")
+		}
+		key := p.Collection + "|" + strings.ToLower(p.Application) + "|" +
+			strings.ToLower(p.ReportType)
+		if used[key] {
+			return fmt.Errorf("duplicate %s retention policy", p.Collection)
+		}
+		used[key] = true
+	}
+	return nil
+}
+
+// runRetention runs the purge in a transaction, or just runs it for a dry
+// run.  The result is cleared for each try, since a transaction can be
+// retried.
+func runRetention(ctx context.Context, r *retention,
+	purge func(ctx context.Context) error) error {
+	run := func(ctx context.Context) error {
+		r.result.Removed = 0
+		r.result.Changed = 0
+		r.result.Archived = 0
+		return purge(ctx)
+	}
+	if r.dryRun {
+		return run(ctx)
+	}
+	return WithTransaction(ctx, run)
+}
+
+// retentionPolicy finds the most specific policy of the collection for the
+// application and report type given, or -1 if there isn't one.
+func retentionPolicy(policies []RetentionPolicy, collection, app string,
+	rptType *general.ReportType) int {
+	answer := -1
+	best := -1
+	for i, p := range policies {
+		if p.Collection != collection {
+			continue
+		}
+		score := 0
+		if p.Application != "" {
+			if !strings.EqualFold(p.Application, app) {
+				continue
+			}
+			score++
+		}
+		if p.ReportType != "" {
+			if rptType == nil ||
+				(!strings.EqualFold(p.ReportType, rptType.ReportType) &&
+					p.ReportType != rptType.ID.Hex()) {
+				continue
+			}
+			score += 2
+		}
+		if score > best {
+			answer = i
+			best = score
+		}
+	}
+	return answer
+}
+
+// latestCutoff provides the latest cutoff of the collection's policies, since
+// nothing after it is removed.
+func latestCutoff(collection string, policies []RetentionPolicy,
+	runs []*retention) time.Time {
+	var answer time.Time
+	for i, p := range policies {
+		if p.Collection == collection && runs[i].result.Cutoff.After(answer) {
+			answer = runs[i].result.Cutoff
+		}
+	}
+	return answer
+}
+
+func retainLogEntries(ctx context.Context, policies []RetentionPolicy,
+	runs []*retention) error {
+	cutoff := latestCutoff(config.ColLogs, policies, runs)
+	if cutoff.IsZero() {
+		return nil
+	}
+	entries, err := store.ListLogEntries(ctx, "", "", time.Time{}, cutoff)
+	if err != nil {
+		return err
+	}
+	ids := make(map[int][]primitive.ObjectID)
+	docs := make(map[int][]interface{})
+	for i := range entries {
+		entry := &entries[i]
+		pos := retentionPolicy(policies, config.ColLogs, entry.Application, nil)
+		if pos < 0 || !entry.EntryDate.Before(runs[pos].result.Cutoff) {
+			continue
+		}
+		ids[pos] = append(ids[pos], entry.ID)
+		docs[pos] = append(docs[pos], entry)
+	}
+	for pos, list := range ids {
+		r := runs[pos]
+		err = runRetention(ctx, r, func(ctx context.Context) error {
+			if err := r.archiveDocuments(ctx, config.ColLogs,
+				docs[pos]...); err != nil {
+				return err
+			}
+			r.result.Removed = int64(len(list))
+			if r.dryRun {
+				return nil
+			}
+			count, err := store.DeleteLogEntries(ctx, list)
+			r.result.Removed = count
+			return err
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func retainReports(ctx context.Context, policies []RetentionPolicy,
+	runs []*retention) error {
+	cutoff := latestCutoff(config.ColReports, policies, runs)
+	if cutoff.IsZero() {
+		return nil
+	}
+	rptTypes, err := store.ListReportTypes(ctx, "")
+	if err != nil {
+		return err
+	}
+	types := make(map[primitive.ObjectID]*general.ReportType)
+	for i := range rptTypes {
+		types[rptTypes[i].ID] = &rptTypes[i]
+	}
+	rpts, err := store.ListReports(ctx, primitive.NilObjectID, time.Time{},
+		cutoff)
+	if err != nil {
+		return err
+	}
+	ids := make(map[int][]primitive.ObjectID)
+	docs := make(map[int][]interface{})
+	for i := range rpts {
+		rpt := &rpts[i]
+		app := ""
+		rptType := types[rpt.ReportTypeID]
+		if rptType != nil {
+			app = rptType.Application
+		}
+		pos := retentionPolicy(policies, config.ColReports, app, rptType)
+		if pos < 0 || !rpt.ReportDate.Before(runs[pos].result.Cutoff) {
+			continue
+		}
+		ids[pos] = append(ids[pos], rpt.ID)
+		docs[pos] = append(docs[pos], rpt)
+	}
+	for pos, list := range ids {
+		r := runs[pos]
+		err = runRetention(ctx, r, func(ctx context.Context) error {
+			if err := r.archiveDocuments(ctx, config.ColReports,
+				docs[pos]...); err != nil {
+				return err
+			}
+			r.result.Removed = int64(len(list))
+			if r.dryRun {
+				return nil
+			}
+			count, err := store.DeleteReports(ctx, list)
+			r.result.Removed = count
+			return err
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// retainEmployees purges every team's data, then removes the work records of
+// the employees removed.  Those work records are counted with the employees.
+func retainEmployees(ctx context.Context, r *retention) error {
+	tms, err := store.ListTeams(ctx)
+	if err != nil {
+		return err
+	}
+	removed := make(map[primitive.ObjectID]bool)
+	for i := range tms {
+		teamRemoved, err := purgeTeam(ctx, &tms[i], r.result.Cutoff, r)
+		if err != nil {
+			return err
+		}
+		for id := range teamRemoved {
+			removed[id] = true
+		}
+	}
+	if len(removed) == 0 {
+		return nil
+	}
+	empIDs := make([]primitive.ObjectID, 0, len(removed))
+	for id := range removed {
+		empIDs = append(empIDs, id)
+	}
+	return purgeWork(ctx, time.Time{}, empIDs, removed, r)
+}
+
+func retainAuditEntries(ctx context.Context, r *retention) error {
+	entries, err := store.ListAuditEntries(ctx, primitive.NilObjectID, "",
+		time.Time{}, r.result.Cutoff)
+	if err != nil {
+		return err
+	}
+	var docs []interface{}
+	for i := range entries {
+		if entries[i].Date.Before(r.result.Cutoff) {
+			docs = append(docs, &entries[i])
+		}
+	}
+	if err = r.archiveDocuments(ctx, config.ColEmployeeAudit,
+		docs...); err != nil {
+		return err
+	}
+	r.result.Removed = int64(len(docs))
+	if r.dryRun {
+		return nil
+	}
+	r.result.Removed, err = store.PurgeAuditEntries(ctx, r.result.Cutoff)
+	return err
+}
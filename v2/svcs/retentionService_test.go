@@ -0,0 +1,161 @@
+package svcs
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/erneap/models/v2/config"
+	"github.com/erneap/models/v2/general"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestRetentionPolicyCutoff(t *testing.T) {
+	asOf := time.Date(2024, 6, 15, 10, 0, 0, 0, time.UTC)
+	before := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		keep   int
+		before time.Time
+		want   time.Time
+	}{
+		{keep: 0},
+		{keep: -1},
+		{keep: 1, want: time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)},
+		{keep: 3, want: time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)},
+		{keep: 3, before: before, want: before},
+		{before: before, want: before},
+	}
+	for _, tt := range tests {
+		p := RetentionPolicy{Collection: config.ColLogs, KeepYears: tt.keep,
+			Before: tt.before}
+		if got := p.Cutoff(asOf); !got.Equal(tt.want) {
+			t.Errorf("keeping %d years cutoff = %v, want %v", tt.keep, got,
+				tt.want)
+		}
+	}
+}
+
+func TestApplyRetentionLogs(t *testing.T) {
+	asOf := time.Date(2024, 6, 15, 0, 0, 0, 0, time.UTC)
+	policies := []RetentionPolicy{
+		{Collection: config.ColLogs, KeepYears: 2, Archive: true},
+		// the scheduler's logs are kept forever.
+		{Collection: config.ColLogs, Application: "scheduler"},
+	}
+	tests := []struct {
+		name     string
+		dryRun   bool
+		left     int
+		archived int
+	}{
+		{name: "dry run", dryRun: true, left: 4},
+		{name: "applied", left: 3, archived: 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			SetStore(NewMemoryStore())
+			ctx := context.Background()
+			for _, entry := range []general.LogEntry{
+				{Application: "metrics", EntryDate: time.Date(2021, 5, 1, 0, 0, 0,
+					0, time.UTC)},
+				{Application: "metrics", EntryDate: time.Date(2023, 5, 1, 0, 0, 0,
+					0, time.UTC)},
+				{Application: "scheduler", EntryDate: time.Date(2019, 5, 1, 0, 0,
+					0, 0, time.UTC)},
+				{Application: "scheduler", EntryDate: time.Date(2024, 5, 1, 0, 0,
+					0, 0, time.UTC)},
+			} {
+				entry.ID = primitive.NewObjectID()
+				if err := store.InsertLogEntry(ctx, &entry); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			report, err := ApplyRetention(policies, asOf, tt.dryRun)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if report.DryRun != tt.dryRun || len(report.Results) != 2 {
+				t.Fatalf("report = %+v", report)
+			}
+			// both runs report what the first policy removes.
+			if r := report.Results[0]; r.Removed != 1 || r.Archived != 1 ||
+				!r.Cutoff.Equal(time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)) {
+				t.Errorf("collection result = %+v", r)
+			}
+			if r := report.Results[1]; r.Removed != 0 || !r.Cutoff.IsZero() {
+				t.Errorf("scheduler result = %+v", r)
+			}
+			entries, err := store.ListLogEntries(ctx, "", "", time.Time{},
+				time.Time{})
+			if err != nil || len(entries) != tt.left {
+				t.Errorf("entries left = %d, %v", len(entries), err)
+			}
+			archives, err := store.ListArchives(ctx, config.ColLogs, time.Time{},
+				time.Time{})
+			if err != nil || len(archives) != tt.archived {
+				t.Errorf("archives = %d, %v", len(archives), err)
+			}
+		})
+	}
+}
+
+func TestPurgeLogs(t *testing.T) {
+	SetStore(NewMemoryStore())
+	ctx := context.Background()
+	for _, day := range []int{1, 2, 3} {
+		entry := general.LogEntry{
+			ID:          primitive.NewObjectID(),
+			Application: "scheduler",
+			EntryDate:   time.Date(2024, 5, day, 0, 0, 0, 0, time.UTC),
+		}
+		if err := store.InsertLogEntry(ctx, &entry); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := PurgeLogs(time.Date(2024, 5, 3, 0, 0, 0, 0, time.UTC)); err != nil {
+		t.Fatal(err)
+	}
+	entries, err := store.ListLogEntries(ctx, "", "", time.Time{}, time.Time{})
+	if err != nil || len(entries) != 1 || entries[0].EntryDate.Day() != 3 {
+		t.Errorf("entries left = %+v, %v", entries, err)
+	}
+}
+
+func TestApplyRetentionInvalidPolicies(t *testing.T) {
+	SetStore(NewMemoryStore())
+	tests := []struct {
+		name     string
+		policies []RetentionPolicy
+	}{
+		{
+			name:     "unknown collection",
+			policies: []RetentionPolicy{{Collection: "users", KeepYears: 1}},
+		},
+		{
+			name: "work by application",
+			policies: []RetentionPolicy{{Collection: config.ColEmployeeWork,
+				Application: "scheduler", KeepYears: 1}},
+		},
+		{
+			name: "logs by report type",
+			policies: []RetentionPolicy{{Collection: config.ColLogs,
+				ReportType: "leave", KeepYears: 1}},
+		},
+		{
+			name: "duplicate",
+			policies: []RetentionPolicy{
+				{Collection: config.ColLogs, Application: "a", KeepYears: 1},
+				{Collection: config.ColLogs, Application: "A", KeepYears: 2},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := ApplyRetention(tt.policies, time.Now(),
+				true); err == nil {
+				t.Error("ApplyRetention gave no error")
+			}
+		})
+	}
+}
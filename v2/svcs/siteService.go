@@ -1,11 +1,15 @@
 package svcs
 
 import (
+	"context"
+	"errors"
 	"sort"
 	"strings"
+	"time"
 
 	"github.com/erneap/models/v2/employees"
 	"github.com/erneap/models/v2/sites"
+	"github.com/erneap/models/v2/teams"
 )
 
 // Every service will have functions for completing the CRUD functions
@@ -13,35 +17,47 @@ import (
 // tea's sotes.
 
 func CreateSite(teamid string, id, name string) (*sites.Site, error) {
-	team, err := GetTeam(teamid)
-	if err != nil {
-		return nil, err
-	}
+	return CreateSiteContext(context.Background(), teamid, id, name)
+}
 
+func CreateSiteContext(ctx context.Context, teamid string, id, name string) (*sites.Site, error) {
+	ctx, cancel := writeContext(ctx)
+	defer cancel()
 	var answer *sites.Site
-
-	for _, site := range team.Sites {
-		if strings.EqualFold(site.ID, id) || strings.EqualFold(site.Name, name) {
-			answer = &site
+	_, err := ModifyTeamContext(ctx, teamid, func(team *teams.Team) error {
+		answer = nil
+		for _, site := range team.Sites {
+			if strings.EqualFold(site.ID, id) || strings.EqualFold(site.Name, name) {
+				answer = &site
+			}
 		}
-	}
-	if answer == nil {
-		answer = &sites.Site{
-			ID:   id,
-			Name: name,
+		if answer == nil {
+			answer = &sites.Site{
+				ID:   id,
+				Name: name,
+			}
+			team.Sites = append(team.Sites, *answer)
+		} else {
+			answer.ID = id
+			answer.Name = name
 		}
-		team.Sites = append(team.Sites, *answer)
-	} else {
-		answer.ID = id
-		answer.Name = name
+		return nil
+	})
+	if err != nil {
+		return nil, err
 	}
-	UpdateTeam(team)
 
 	return answer, nil
 }
 
 func GetSite(teamid, siteid string) (*sites.Site, error) {
-	team, err := GetTeam(teamid)
+	return GetSiteContext(context.Background(), teamid, siteid)
+}
+
+func GetSiteContext(ctx context.Context, teamid, siteid string) (*sites.Site, error) {
+	ctx, cancel := readContext(ctx)
+	defer cancel()
+	team, err := GetTeamContext(ctx, teamid)
 	if err != nil {
 		return nil, err
 	}
@@ -50,7 +66,7 @@ func GetSite(teamid, siteid string) (*sites.Site, error) {
 	for _, site := range team.Sites {
 		if strings.EqualFold(site.ID, siteid) {
 			answer = site
-			emps, _ := GetEmployees(teamid, siteid)
+			emps, _ := GetEmployeesContext(ctx, teamid, siteid)
 			answer.Employees = append(site.Employees, emps...)
 			sort.Sort(employees.ByEmployees(answer.Employees))
 		}
@@ -58,8 +74,66 @@ func GetSite(teamid, siteid string) (*sites.Site, error) {
 	return &answer, nil
 }
 
+// GetSiteLocation provides the location of the site's time zone.
+func GetSiteLocation(teamid, siteid string) (*time.Location, error) {
+	return GetSiteLocationContext(context.Background(), teamid, siteid)
+}
+
+func GetSiteLocationContext(ctx context.Context, teamid,
+	siteid string) (*time.Location, error) {
+	ctx, cancel := readContext(ctx)
+	defer cancel()
+	team, err := GetTeamContext(ctx, teamid)
+	if err != nil {
+		return nil, err
+	}
+	for _, site := range team.Sites {
+		if strings.EqualFold(site.ID, siteid) {
+			return site.GetLocation(), nil
+		}
+	}
+	return nil, errors.New("site not found")
+}
+
+// GetSiteCoverage provides the site's staffing coverage from start through
+// end, with the shifts below their minimums and the leave that caused it.
+func GetSiteCoverage(teamid, siteid string, start,
+	end time.Time) (*sites.CoverageReport, error) {
+	return GetSiteCoverageContext(context.Background(), teamid, siteid, start,
+		end)
+}
+
+func GetSiteCoverageContext(ctx context.Context, teamid, siteid string, start,
+	end time.Time) (*sites.CoverageReport, error) {
+	ctx, cancel := readContext(ctx)
+	defer cancel()
+	team, err := GetTeamContext(ctx, teamid)
+	if err != nil {
+		return nil, err
+	}
+	for _, site := range team.Sites {
+		if strings.EqualFold(site.ID, siteid) {
+			emps, err := GetEmployeesWithOptionsContext(ctx, teamid, site.ID,
+				EmployeeOptions{
+					WorkYears: WorkYears(start, end),
+				})
+			if err != nil {
+				return nil, err
+			}
+			return site.GetCoverage(emps, start, end), nil
+		}
+	}
+	return nil, errors.New("site not found")
+}
+
 func GetSites(teamid string) ([]sites.Site, error) {
-	team, err := GetTeam(teamid)
+	return GetSitesContext(context.Background(), teamid)
+}
+
+func GetSitesContext(ctx context.Context, teamid string) ([]sites.Site, error) {
+	ctx, cancel := readContext(ctx)
+	defer cancel()
+	team, err := GetTeamContext(ctx, teamid)
 	if err != nil {
 		return nil, err
 	}
@@ -69,33 +143,46 @@ func GetSites(teamid string) ([]sites.Site, error) {
 }
 
 func UpdateSite(teamid string, nsite sites.Site) error {
-	team, err := GetTeam(teamid)
-	if err != nil {
-		return err
-	}
+	return UpdateSiteContext(context.Background(), teamid, nsite)
+}
 
-	for s, site := range team.Sites {
-		if strings.EqualFold(site.ID, nsite.ID) {
-			team.Sites[s] = nsite
+func UpdateSiteContext(ctx context.Context, teamid string, nsite sites.Site) error {
+	ctx, cancel := writeContext(ctx)
+	defer cancel()
+	if nsite.TimeZone != "" {
+		if err := nsite.SetTimeZone(nsite.TimeZone); err != nil {
+			return err
 		}
 	}
-	return UpdateTeam(team)
+	_, err := ModifyTeamContext(ctx, teamid, func(team *teams.Team) error {
+		for s, site := range team.Sites {
+			if strings.EqualFold(site.ID, nsite.ID) {
+				team.Sites[s] = nsite
+			}
+		}
+		return nil
+	})
+	return err
 }
 
 func DeleteSite(teamid, siteid string) error {
-	team, err := GetTeam(teamid)
-	if err != nil {
-		return err
-	}
+	return DeleteSiteContext(context.Background(), teamid, siteid)
+}
 
-	found := -1
-	for s := 0; s < len(team.Sites) && found < 0; s++ {
-		if strings.EqualFold(team.Sites[s].ID, siteid) {
-			found = s
+func DeleteSiteContext(ctx context.Context, teamid, siteid string) error {
+	ctx, cancel := writeContext(ctx)
+	defer cancel()
+	_, err := ModifyTeamContext(ctx, teamid, func(team *teams.Team) error {
+		found := -1
+		for s := 0; s < len(team.Sites) && found < 0; s++ {
+			if strings.EqualFold(team.Sites[s].ID, siteid) {
+				found = s
+			}
 		}
-	}
-	if found >= 0 {
-		team.Sites = append(team.Sites[:found], team.Sites[found+1:]...)
-	}
-	return UpdateTeam(team)
+		if found >= 0 {
+			team.Sites = append(team.Sites[:found], team.Sites[found+1:]...)
+		}
+		return nil
+	})
+	return err
 }
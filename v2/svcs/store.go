@@ -0,0 +1,237 @@
+package svcs
+
+import (
+	"context"
+	"time"
+
+	"github.com/erneap/models/v2/employees"
+	"github.com/erneap/models/v2/general"
+	"github.com/erneap/models/v2/metrics"
+	"github.com/erneap/models/v2/notifications"
+	"github.com/erneap/models/v2/teams"
+	"github.com/erneap/models/v2/users"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// The service functions don't talk to the database directly, they work
+// through a set of storage interfaces, one per collection.  The default
+// storage is the mongo database, but another implementation (like the memory
+// store) can be injected with SetStore for testing and demonstrations.
+// Every find method returns mongo.ErrNoDocuments when nothing matches, so
+// callers see the same error no matter which store is in use.  Every delete
+// and purge method returns the number of documents removed.
+
+// EmployeeStore provides storage for the scheduler's employee records.
+type EmployeeStore interface {
+	InsertEmployee(ctx context.Context, emp *employees.Employee) error
+	FindEmployee(ctx context.Context, id primitive.ObjectID) (*employees.Employee, error)
+	// FindEmployeeByName matches first and last names only, a nil teamid
+	// matches any team.
+	FindEmployeeByName(ctx context.Context, teamid primitive.ObjectID, first,
+		last string) (*employees.Employee, error)
+	// FindEmployeeByFullName matches first, middle and last names, a nil teamid
+	// matches any team.
+	FindEmployeeByFullName(ctx context.Context, teamid primitive.ObjectID, first,
+		middle, last string) (*employees.Employee, error)
+	// ListEmployees provides the employees for a team and site, a nil teamid
+	// or a blank siteid aren't used in the selection.
+	ListEmployees(ctx context.Context, teamid primitive.ObjectID,
+		siteid string) ([]employees.Employee, error)
+	// ReplaceEmployee stores the employee only if the stored version is the
+	// employee's version, then the version is incremented.  Otherwise a
+	// ConflictError is returned.
+	ReplaceEmployee(ctx context.Context, emp *employees.Employee) error
+	DeleteEmployee(ctx context.Context, id primitive.ObjectID) (int64, error)
+}
+
+// TeamStore provides storage for the scheduler's teams, which also carry the
+// team's sites.
+type TeamStore interface {
+	InsertTeam(ctx context.Context, team *teams.Team) error
+	FindTeam(ctx context.Context, id primitive.ObjectID) (*teams.Team, error)
+	FindTeamByName(ctx context.Context, name string) (*teams.Team, error)
+	ListTeams(ctx context.Context) ([]teams.Team, error)
+	// ReplaceTeam stores the team (and its sites) only if the stored version is
+	// the team's version, then the version is incremented.  Otherwise a
+	// ConflictError is returned.
+	ReplaceTeam(ctx context.Context, team *teams.Team) error
+	DeleteTeam(ctx context.Context, id primitive.ObjectID) (int64, error)
+}
+
+// UserStore provides storage for the authentication users.
+type UserStore interface {
+	InsertUser(ctx context.Context, user *users.User) error
+	FindUser(ctx context.Context, id primitive.ObjectID) (*users.User, error)
+	FindUserByEmail(ctx context.Context, email string) (*users.User, error)
+	FindUserByName(ctx context.Context, first, last string) (*users.User, error)
+	// FindUsers provides the users with the ids given, in no particular order.
+	FindUsers(ctx context.Context, ids []primitive.ObjectID) ([]users.User, error)
+	ListUsers(ctx context.Context) ([]users.User, error)
+	ReplaceUser(ctx context.Context, user *users.User) error
+	DeleteUser(ctx context.Context, id primitive.ObjectID) (int64, error)
+}
+
+// WorkStore provides storage for the employee's yearly work records.
+type WorkStore interface {
+	InsertWork(ctx context.Context, work *employees.EmployeeWorkRecord) error
+	FindWork(ctx context.Context, empID primitive.ObjectID,
+		year uint) (*employees.EmployeeWorkRecord, error)
+	// ListWorkForEmployees provides the work records for the employees and
+	// years given, in no particular order.
+	ListWorkForEmployees(ctx context.Context, empIDs []primitive.ObjectID,
+		years []uint) ([]employees.EmployeeWorkRecord, error)
+	// ListWorkThrough provides the work records for the year given and before
+	// of the employees given, or of every employee for nil employee ids.
+	ListWorkThrough(ctx context.Context, year uint,
+		empIDs []primitive.ObjectID) ([]employees.EmployeeWorkRecord, error)
+	ReplaceWork(ctx context.Context, work *employees.EmployeeWorkRecord) error
+	DeleteWork(ctx context.Context, empID primitive.ObjectID,
+		year uint) (int64, error)
+}
+
+// AuditStore provides storage for the field-level changes made to employees.
+type AuditStore interface {
+	InsertAuditEntries(ctx context.Context, entries []employees.AuditEntry) error
+	// ListAuditEntries selects by employee, actor and change date range.  A
+	// nil employee id, a blank actor or a zero date isn't used in the
+	// selection.
+	ListAuditEntries(ctx context.Context, empID primitive.ObjectID, actor string,
+		start, end time.Time) ([]employees.AuditEntry, error)
+	// PurgeAuditEntries removes all entries before the date given.
+	PurgeAuditEntries(ctx context.Context, before time.Time) (int64, error)
+}
+
+// SwapStore provides storage for the shift swaps between employees.
+type SwapStore interface {
+	InsertShiftSwap(ctx context.Context, swap *employees.ShiftSwap) error
+	FindShiftSwap(ctx context.Context, id primitive.ObjectID) (*employees.ShiftSwap, error)
+	// ListShiftSwaps selects by team and by employee, as either the requester
+	// or the counterpart.  A nil id isn't used in the selection.
+	ListShiftSwaps(ctx context.Context, teamID,
+		empID primitive.ObjectID) ([]employees.ShiftSwap, error)
+	ReplaceShiftSwap(ctx context.Context, swap *employees.ShiftSwap) error
+}
+
+// LogStore provides storage for the general application log entries.
+type LogStore interface {
+	InsertLogEntry(ctx context.Context, entry *general.LogEntry) error
+	FindLogEntry(ctx context.Context, id primitive.ObjectID) (*general.LogEntry, error)
+	// ListLogEntries selects by application, category and entry date range.  A
+	// blank application or category, or a zero date, isn't used in the
+	// selection.
+	ListLogEntries(ctx context.Context, app, cat string,
+		start, end time.Time) ([]general.LogEntry, error)
+	ReplaceLogEntry(ctx context.Context, entry *general.LogEntry) error
+	DeleteLogEntry(ctx context.Context, id primitive.ObjectID) (int64, error)
+	// DeleteLogEntries removes the entries with the ids given.
+	DeleteLogEntries(ctx context.Context, ids []primitive.ObjectID) (int64, error)
+}
+
+// ReportStore provides storage for the stored reports and their report types.
+type ReportStore interface {
+	InsertReport(ctx context.Context, rpt *general.DBReport) error
+	FindReport(ctx context.Context, id primitive.ObjectID) (*general.DBReport, error)
+	// ListReports selects by report type and report date, with the start date
+	// inclusive and the end date exclusive.  A nil type id or a zero date
+	// isn't used in the selection.
+	ListReports(ctx context.Context, typeID primitive.ObjectID,
+		start, end time.Time) ([]general.DBReport, error)
+	ReplaceReport(ctx context.Context, rpt *general.DBReport) error
+	DeleteReport(ctx context.Context, id primitive.ObjectID) (int64, error)
+	// DeleteReports removes the reports with the ids given.
+	DeleteReports(ctx context.Context, ids []primitive.ObjectID) (int64, error)
+
+	InsertReportType(ctx context.Context, rptType *general.ReportType) error
+	FindReportType(ctx context.Context, id primitive.ObjectID) (*general.ReportType, error)
+	// ListReportTypes provides the report types for an application, or all of
+	// them if the application is blank.
+	ListReportTypes(ctx context.Context, app string) ([]general.ReportType, error)
+	ReplaceReportType(ctx context.Context, rptType *general.ReportType) error
+	DeleteReportType(ctx context.Context, id primitive.ObjectID) (int64, error)
+}
+
+// MessageStore provides storage for the scheduler's notification messages.
+type MessageStore interface {
+	InsertMessage(ctx context.Context, msg *notifications.Notification) error
+	FindMessage(ctx context.Context, id primitive.ObjectID) (*notifications.Notification, error)
+	// ListMessages provides the messages sent to the recipient, or all messages
+	// if the recipient is blank.
+	ListMessages(ctx context.Context, to string) ([]notifications.Notification, error)
+	DeleteMessage(ctx context.Context, id primitive.ObjectID) (int64, error)
+}
+
+// MetricStore provides storage for the metrics missions and ground outages.
+type MetricStore interface {
+	InsertMission(ctx context.Context, msn *metrics.Mission) error
+	// ListMissions provides the missions with a mission date in the range,
+	// inclusive at both ends.
+	ListMissions(ctx context.Context, start, end time.Time) ([]metrics.Mission, error)
+	InsertGroundOutage(ctx context.Context, outage *metrics.GroundOutage) error
+	// ListGroundOutages provides the outages with an outage date in the range,
+	// inclusive at both ends.
+	ListGroundOutages(ctx context.Context,
+		start, end time.Time) ([]metrics.GroundOutage, error)
+}
+
+// ArchiveStore keeps the copies of the documents removed by the retention
+// policies.
+type ArchiveStore interface {
+	InsertArchives(ctx context.Context, recs []general.ArchiveRecord) error
+	// ListArchives selects by collection and archive date range (inclusive).  A
+	// blank collection or zero date isn't used in the selection.
+	ListArchives(ctx context.Context, collection string,
+		start, end time.Time) ([]general.ArchiveRecord, error)
+}
+
+// MigrationStore records the schema migrations applied to the stored
+// documents.
+type MigrationStore interface {
+	InsertMigration(ctx context.Context, rec *general.MigrationRecord) error
+	ListMigrations(ctx context.Context) ([]general.MigrationRecord, error)
+}
+
+// Transactor runs a group of storage operations as one unit of work.  The
+// operations in fn must use the context given to fn, and fn may be called more
+// than once if the transaction has to be retried, so it shouldn't have side
+// effects outside the store.
+type Transactor interface {
+	WithTransaction(ctx context.Context, fn func(ctx context.Context) error) error
+}
+
+// Store combines all the storage interfaces used by the service functions.
+type Store interface {
+	Transactor
+	EmployeeStore
+	TeamStore
+	UserStore
+	WorkStore
+	AuditStore
+	SwapStore
+	LogStore
+	ReportStore
+	MessageStore
+	MetricStore
+	ArchiveStore
+	MigrationStore
+}
+
+var store Store = NewMongoStore()
+
+// SetStore replaces the storage used by all the service functions (and so the
+// reports).  It should be called before any service function is used.
+func SetStore(s Store) {
+	store = s
+}
+
+// GetStore provides the storage currently used by the service functions.
+func GetStore() Store {
+	return store
+}
+
+// WithTransaction runs fn as a single transaction in the current store, so the
+// service functions that change more than one collection either make all of
+// their changes or none of them.
+func WithTransaction(ctx context.Context,
+	fn func(ctx context.Context) error) error {
+	return store.WithTransaction(ctx, fn)
+}
@@ -0,0 +1,300 @@
+package svcs
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"time"
+
+	"github.com/erneap/models/v2/employees"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// A shift swap is requested by the employee giving up a shift, accepted by
+// the counterpart, then approved by a supervisor, which adds the variations
+// for the trade to both employees.  Cancelling an approved swap removes them.
+
+// CreateShiftSwap requests the trade of the requester's shift on the
+// requester's date for the counterpart's shift on the counterpart's date.
+// Both must work their date, and for different dates, each must be off on
+// the other's date.
+func CreateShiftSwap(requesterID, counterpartID string, requesterDate,
+	counterpartDate time.Time, comment string) (*employees.ShiftSwap, error) {
+	return CreateShiftSwapContext(context.Background(), requesterID,
+		counterpartID, requesterDate, counterpartDate, comment)
+}
+
+func CreateShiftSwapContext(ctx context.Context, requesterID,
+	counterpartID string, requesterDate, counterpartDate time.Time,
+	comment string) (*employees.ShiftSwap, error) {
+	ctx, cancel := writeContext(ctx)
+	defer cancel()
+	oReqID, err := primitive.ObjectIDFromHex(requesterID)
+	if err != nil {
+		return nil, err
+	}
+	oCtrID, err := primitive.ObjectIDFromHex(counterpartID)
+	if err != nil {
+		return nil, err
+	}
+	if oReqID == oCtrID {
+		return nil, errors.New("an employee can't swap with themself")
+	}
+	requester, err := store.FindEmployee(ctx, oReqID)
+	if err != nil {
+		return nil, err
+	}
+	counterpart, err := store.FindEmployee(ctx, oCtrID)
+	if err != nil {
+		return nil, err
+	}
+	if requester.TeamID != counterpart.TeamID {
+		return nil, errors.New("employees aren't on the same team")
+	}
+
+	requesterDate = time.Date(requesterDate.Year(), requesterDate.Month(),
+		requesterDate.Day(), 0, 0, 0, 0, time.UTC)
+	counterpartDate = time.Date(counterpartDate.Year(), counterpartDate.Month(),
+		counterpartDate.Day(), 0, 0, 0, 0, time.UTC)
+	rDay := requester.ResolveDay(requesterDate)
+	if !rDay.IsWorkday() {
+		return nil, errors.New("requester doesn't work on the requester's date")
+	}
+	cDay := counterpart.ResolveDay(counterpartDate)
+	if !cDay.IsWorkday() {
+		return nil, errors.New(
+			"counterpart doesn't work on the counterpart's date")
+	}
+	if !requesterDate.Equal(counterpartDate) {
+		if day := counterpart.ResolveDay(requesterDate); day.Code != "" {
+			return nil, errors.New(
+				"counterpart isn't free on the requester's date")
+		}
+		if day := requester.ResolveDay(counterpartDate); day.Code != "" {
+			return nil, errors.New(
+				"requester isn't free on the counterpart's date")
+		}
+	}
+
+	swap := &employees.ShiftSwap{
+		ID:            primitive.NewObjectID(),
+		TeamID:        requester.TeamID,
+		RequesterID:   oReqID,
+		CounterpartID: oCtrID,
+		RequestDate:   time.Now().UTC(),
+		RequesterDate: requesterDate,
+		RequesterWorkday: employees.Workday{
+			Workcenter: rDay.Workcenter,
+			Code:       rDay.Code,
+			Hours:      rDay.Hours,
+		},
+		CounterpartDate: counterpartDate,
+		CounterpartWorkday: employees.Workday{
+			Workcenter: cDay.Workcenter,
+			Code:       cDay.Code,
+			Hours:      cDay.Hours,
+		},
+		Status: employees.SwapRequested,
+	}
+	swap.AddComment(comment)
+	if err = store.InsertShiftSwap(ctx, swap); err != nil {
+		return nil, err
+	}
+	return swap, nil
+}
+
+func GetShiftSwap(id string) (*employees.ShiftSwap, error) {
+	return GetShiftSwapContext(context.Background(), id)
+}
+
+func GetShiftSwapContext(ctx context.Context,
+	id string) (*employees.ShiftSwap, error) {
+	ctx, cancel := readContext(ctx)
+	defer cancel()
+	oID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, err
+	}
+	return store.FindShiftSwap(ctx, oID)
+}
+
+// GetShiftSwapsForEmployee provides the swaps the employee requested or is the
+// counterpart of.
+func GetShiftSwapsForEmployee(empID string) ([]employees.ShiftSwap, error) {
+	return GetShiftSwapsForEmployeeContext(context.Background(), empID)
+}
+
+func GetShiftSwapsForEmployeeContext(ctx context.Context,
+	empID string) ([]employees.ShiftSwap, error) {
+	ctx, cancel := readContext(ctx)
+	defer cancel()
+	oEmpID, err := primitive.ObjectIDFromHex(empID)
+	if err != nil {
+		return nil, err
+	}
+	swaps, err := store.ListShiftSwaps(ctx, primitive.NilObjectID, oEmpID)
+	if err != nil {
+		return swaps, err
+	}
+	sort.Sort(employees.ByShiftSwap(swaps))
+	return swaps, nil
+}
+
+func GetShiftSwapsForTeam(teamID string) ([]employees.ShiftSwap, error) {
+	return GetShiftSwapsForTeamContext(context.Background(), teamID)
+}
+
+func GetShiftSwapsForTeamContext(ctx context.Context,
+	teamID string) ([]employees.ShiftSwap, error) {
+	ctx, cancel := readContext(ctx)
+	defer cancel()
+	oTeamID, err := primitive.ObjectIDFromHex(teamID)
+	if err != nil {
+		return nil, err
+	}
+	swaps, err := store.ListShiftSwaps(ctx, oTeamID, primitive.NilObjectID)
+	if err != nil {
+		return swaps, err
+	}
+	sort.Sort(employees.ByShiftSwap(swaps))
+	return swaps, nil
+}
+
+// AcceptShiftSwap records the counterpart accepting (or declining) the swap.
+func AcceptShiftSwap(id, empID string, accept bool,
+	comment string) (*employees.ShiftSwap, error) {
+	return AcceptShiftSwapContext(context.Background(), id, empID, accept,
+		comment)
+}
+
+func AcceptShiftSwapContext(ctx context.Context, id, empID string,
+	accept bool, comment string) (*employees.ShiftSwap, error) {
+	return changeShiftSwap(ctx, id, func(ctx context.Context,
+		swap *employees.ShiftSwap) error {
+		oEmpID, err := primitive.ObjectIDFromHex(empID)
+		if err != nil {
+			return err
+		}
+		return swap.Accept(oEmpID, accept, comment)
+	})
+}
+
+// ApproveShiftSwap records the supervisor approving (or rejecting) the swap,
+// and for an approval adds the swap's variations to both employees.
+func ApproveShiftSwap(id, approver string, approve bool,
+	comment string) (*employees.ShiftSwap, error) {
+	return ApproveShiftSwapContext(context.Background(), id, approver, approve,
+		comment)
+}
+
+func ApproveShiftSwapContext(ctx context.Context, id, approver string,
+	approve bool, comment string) (*employees.ShiftSwap, error) {
+	if GetActor(ctx) == "" {
+		ctx = WithActor(ctx, approver)
+	}
+	return changeShiftSwap(ctx, id, func(ctx context.Context,
+		swap *employees.ShiftSwap) error {
+		if err := swap.Approve(approver, approve, comment); err != nil {
+			return err
+		}
+		if !approve {
+			return nil
+		}
+		var err error
+		swap.RequesterVariations, err = addSwapVariations(ctx, swap,
+			swap.RequesterID)
+		if err != nil {
+			return err
+		}
+		swap.CounterpartVariations, err = addSwapVariations(ctx, swap,
+			swap.CounterpartID)
+		return err
+	})
+}
+
+// CancelShiftSwap cancels the swap, removing the variations of an approved
+// swap from both employees.
+func CancelShiftSwap(id, comment string) (*employees.ShiftSwap, error) {
+	return CancelShiftSwapContext(context.Background(), id, comment)
+}
+
+func CancelShiftSwapContext(ctx context.Context, id,
+	comment string) (*employees.ShiftSwap, error) {
+	return changeShiftSwap(ctx, id, func(ctx context.Context,
+		swap *employees.ShiftSwap) error {
+		approved := swap.Status == employees.SwapApproved
+		if err := swap.Cancel(comment); err != nil {
+			return err
+		}
+		if !approved {
+			return nil
+		}
+		_, err := ModifyEmployeeContext(ctx, swap.RequesterID.Hex(),
+			func(emp *employees.Employee) error {
+				emp.RemoveVariations(swap.RequesterVariations)
+				return nil
+			})
+		if err != nil {
+			return err
+		}
+		_, err = ModifyEmployeeContext(ctx, swap.CounterpartID.Hex(),
+			func(emp *employees.Employee) error {
+				emp.RemoveVariations(swap.CounterpartVariations)
+				return nil
+			})
+		return err
+	})
+}
+
+// changeShiftSwap reads the swap, changes it and stores it, all in a single
+// transaction with the employee changes the change makes.
+func changeShiftSwap(ctx context.Context, id string,
+	change func(ctx context.Context, swap *employees.ShiftSwap) error) (
+	*employees.ShiftSwap, error) {
+	ctx, cancel := writeContext(ctx)
+	defer cancel()
+	oID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, err
+	}
+	var answer *employees.ShiftSwap
+	err = WithTransaction(ctx, func(ctx context.Context) error {
+		swap, err := store.FindShiftSwap(ctx, oID)
+		if err != nil {
+			return err
+		}
+		if err = change(ctx, swap); err != nil {
+			return err
+		}
+		answer = swap
+		return store.ReplaceShiftSwap(ctx, swap)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return answer, nil
+}
+
+// addSwapVariations adds the employee's variations for the swap, providing
+// their ids.
+func addSwapVariations(ctx context.Context, swap *employees.ShiftSwap,
+	empID primitive.ObjectID) ([]uint, error) {
+	var ids []uint
+	_, err := ModifyEmployeeContext(ctx, empID.Hex(),
+		func(emp *employees.Employee) error {
+			ids = ids[:0]
+			changes := swap.SwapChanges(emp.ID)
+			var dates []time.Time
+			for date := range changes {
+				dates = append(dates, date)
+			}
+			sort.Slice(dates, func(i, j int) bool {
+				return dates[i].Before(dates[j])
+			})
+			for _, date := range dates {
+				ids = append(ids, emp.AddDayVariation(date, changes[date]))
+			}
+			return nil
+		})
+	return ids, err
+}
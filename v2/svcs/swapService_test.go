@@ -0,0 +1,111 @@
+package svcs
+
+import (
+	"testing"
+	"time"
+
+	"github.com/erneap/models/v2/employees"
+)
+
+func TestShiftSwapWorkflow(t *testing.T) {
+	SetStore(NewMemoryStore())
+	team := CreateTeam("swap", false)
+	start := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	// the requester works days Monday through Friday, the counterpart nights
+	// on Saturday.
+	requester := employees.Employee{
+		Name: employees.EmployeeName{FirstName: "R", LastName: "S"},
+	}
+	requester.AddAssignment("s", "w", start)
+	counterpart := employees.Employee{
+		Name: employees.EmployeeName{FirstName: "C", LastName: "S"},
+	}
+	counterpart.AddAssignment("s", "w", start)
+	for d := range counterpart.Assignments[0].Schedules[0].Workdays {
+		wd := &counterpart.Assignments[0].Schedules[0].Workdays[d]
+		wd.Code, wd.Hours, wd.Workcenter = "", 0, ""
+		if d == 6 {
+			wd.Code, wd.Hours, wd.Workcenter = "N", 8, "w"
+		}
+	}
+	r, err := CreateEmployee(requester, "pw", "", team.ID.Hex(), "s")
+	if err != nil {
+		t.Fatal(err)
+	}
+	c, err := CreateEmployee(counterpart, "pw", "", team.ID.Hex(), "s")
+	if err != nil {
+		t.Fatal(err)
+	}
+	monday := time.Date(2024, 6, 3, 0, 0, 0, 0, time.UTC)
+	saturday := time.Date(2024, 6, 8, 0, 0, 0, 0, time.UTC)
+
+	// the codes each works on the Monday and the Saturday.
+	check := func(step, rMonday, rSaturday, cMonday, cSaturday string) {
+		t.Helper()
+		for _, tt := range []struct {
+			id   string
+			date time.Time
+			want string
+		}{
+			{r.ID.Hex(), monday, rMonday},
+			{r.ID.Hex(), saturday, rSaturday},
+			{c.ID.Hex(), monday, cMonday},
+			{c.ID.Hex(), saturday, cSaturday},
+		} {
+			emp, err := GetEmployee(tt.id)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := emp.ResolveDay(tt.date).Code; got != tt.want {
+				t.Errorf("%s: %s works %q on %s, want %q", step,
+					emp.Name.FirstName, got, tt.date.Format("Mon"), tt.want)
+			}
+		}
+	}
+
+	if _, err := CreateShiftSwap(r.ID.Hex(), c.ID.Hex(), saturday, monday,
+		""); err == nil {
+		t.Error("swap of days not worked was created")
+	}
+	swap, err := CreateShiftSwap(r.ID.Hex(), c.ID.Hex(), monday, saturday,
+		"doctor's visit")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ApproveShiftSwap(swap.ID.Hex(), "boss", true,
+		""); err == nil {
+		t.Error("swap approved before it was accepted")
+	}
+	if _, err := AcceptShiftSwap(swap.ID.Hex(), r.ID.Hex(), true,
+		""); err == nil {
+		t.Error("swap accepted by the requester")
+	}
+	if _, err := AcceptShiftSwap(swap.ID.Hex(), c.ID.Hex(), true,
+		""); err != nil {
+		t.Fatal(err)
+	}
+	check("accepted", "D", "", "", "N")
+
+	swap, err = ApproveShiftSwap(swap.ID.Hex(), "boss", true, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if swap.Status != employees.SwapApproved ||
+		len(swap.RequesterVariations) != 2 ||
+		len(swap.CounterpartVariations) != 2 {
+		t.Fatalf("approved swap = %+v", swap)
+	}
+	check("approved", "", "N", "D", "")
+
+	swap, err = CancelShiftSwap(swap.ID.Hex(), "changed plans")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if swap.Status != employees.SwapCancelled || len(swap.Comments) != 2 {
+		t.Errorf("cancelled swap = %+v", swap)
+	}
+	check("cancelled", "D", "", "", "N")
+	if _, err := CancelShiftSwap(swap.ID.Hex(), ""); err == nil {
+		t.Error("swap cancelled twice")
+	}
+}
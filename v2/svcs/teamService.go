@@ -3,14 +3,13 @@ package svcs
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"io"
 	"log"
 	"os"
 
-	"github.com/erneap/models/v2/config"
 	"github.com/erneap/models/v2/labor"
 	"github.com/erneap/models/v2/teams"
-	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
@@ -20,15 +19,13 @@ import (
 
 // CRUD Create function
 func CreateTeam(name string, useCodes bool) *teams.Team {
-	teamCol := config.GetCollection(config.DB, "scheduler", "teams")
-
-	filter := bson.M{
-		"name": name,
-	}
-
-	var team *teams.Team
+	return CreateTeamContext(context.Background(), name, useCodes)
+}
 
-	teamCol.FindOne(context.TODO(), filter).Decode(team)
+func CreateTeamContext(ctx context.Context, name string, useCodes bool) *teams.Team {
+	ctx, cancel := writeContext(ctx)
+	defer cancel()
+	team, _ := store.FindTeamByName(ctx, name)
 	if team == nil {
 		team = &teams.Team{
 			ID:   primitive.NewObjectID(),
@@ -72,71 +69,79 @@ func CreateTeam(name string, useCodes bool) *teams.Team {
 				team.Workcodes = append(team.Workcodes, nwc)
 			}
 		}
-		teamCol.InsertOne(context.TODO(), team)
+		store.InsertTeam(ctx, team)
 	}
 	return team
 }
 
 // CRUD Retrieve function single and multiple(All)
 func GetTeam(id string) (*teams.Team, error) {
+	return GetTeamContext(context.Background(), id)
+}
+
+func GetTeamContext(ctx context.Context, id string) (*teams.Team, error) {
+	ctx, cancel := readContext(ctx)
+	defer cancel()
 	teamid, err := primitive.ObjectIDFromHex(id)
 	if err != nil {
 		return nil, err
 	}
-	teamCol := config.GetCollection(config.DB, "scheduler", "teams")
-
-	filter := bson.M{
-		"_id": teamid,
-	}
-
-	var team teams.Team
 
-	err = teamCol.FindOne(context.TODO(), filter).Decode(&team)
-	if err != nil {
-		return nil, err
-	}
-	return &team, nil
+	return store.FindTeam(ctx, teamid)
 }
 
 func GetTeams() ([]teams.Team, error) {
-	teamCol := config.GetCollection(config.DB, "scheduler", "teams")
-
-	var teams []teams.Team
-	cursor, err := teamCol.Find(context.TODO(), bson.M{})
-	if err != nil {
-		return teams, err
-	}
-
-	err = cursor.All(context.TODO(), &teams)
-	if err != nil {
-		return teams, err
-	}
+	return GetTeamsContext(context.Background())
+}
 
-	return teams, nil
+func GetTeamsContext(ctx context.Context) ([]teams.Team, error) {
+	ctx, cancel := readContext(ctx)
+	defer cancel()
+	return store.ListTeams(ctx)
 }
 
 // CRUD Update Function
 func UpdateTeam(team *teams.Team) error {
-	teamCol := config.GetCollection(config.DB, "scheduler", "teams")
+	return UpdateTeamContext(context.Background(), team)
+}
 
-	filter := bson.M{
-		"_id": team.ID,
-	}
+func UpdateTeamContext(ctx context.Context, team *teams.Team) error {
+	ctx, cancel := writeContext(ctx)
+	defer cancel()
+	return store.ReplaceTeam(ctx, team)
+}
 
-	_, err := teamCol.ReplaceOne(context.TODO(), filter, team)
+// GenerateCompanyHolidays fills in the dates of the company's holidays with
+// rules for the years from start through end.
+func GenerateCompanyHolidays(teamid, companyid string, start,
+	end int) (*teams.Team, error) {
+	return GenerateCompanyHolidaysContext(context.Background(), teamid,
+		companyid, start, end)
+}
 
-	return err
+func GenerateCompanyHolidaysContext(ctx context.Context, teamid,
+	companyid string, start, end int) (*teams.Team, error) {
+	ctx, cancel := writeContext(ctx)
+	defer cancel()
+	return ModifyTeamContext(ctx, teamid, func(team *teams.Team) error {
+		co := companyOf(team, companyid)
+		if co == nil {
+			return errors.New("company not found")
+		}
+		co.GenerateHolidays(start, end)
+		return nil
+	})
 }
 
 // CRUD Delete Function
 func DeleteTeam(id primitive.ObjectID) error {
-	teamCol := config.GetCollection(config.DB, "scheduler", "teams")
-
-	filter := bson.M{
-		"_id": id,
-	}
+	return DeleteTeamContext(context.Background(), id)
+}
 
-	_, err := teamCol.DeleteOne(context.TODO(), filter)
+func DeleteTeamContext(ctx context.Context, id primitive.ObjectID) error {
+	ctx, cancel := writeContext(ctx)
+	defer cancel()
+	_, err := store.DeleteTeam(ctx, id)
 
 	return err
 }
@@ -3,9 +3,7 @@ package svcs
 import (
 	"context"
 
-	"github.com/erneap/models/v2/config"
 	"github.com/erneap/models/v2/users"
-	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
@@ -15,15 +13,15 @@ import (
 // CRUD Create Function - New User
 
 func CreateUser(email, first, middle, last, password string) *users.User {
-	userCol := config.GetCollection(config.DB, "authenticate", "users")
-
-	filter := bson.M{
-		"emailAddress": email,
-	}
+	return CreateUserContext(context.Background(), email, first, middle, last, password)
+}
 
-	var user users.User
-	if err := userCol.FindOne(context.TODO(), filter).Decode(&user); err != nil {
-		user = users.User{
+func CreateUserContext(ctx context.Context, email, first, middle, last, password string) *users.User {
+	ctx, cancel := writeContext(ctx)
+	defer cancel()
+	user, err := store.FindUserByEmail(ctx, email)
+	if err != nil {
+		user = &users.User{
 			ID:           primitive.NewObjectID(),
 			EmailAddress: email,
 			FirstName:    first,
@@ -31,7 +29,7 @@ func CreateUser(email, first, middle, last, password string) *users.User {
 			LastName:     last,
 		}
 		user.SetPassword(password)
-		userCol.InsertOne(context.TODO(), user)
+		store.InsertUser(ctx, user)
 	} else {
 		user.EmailAddress = email
 		user.FirstName = first
@@ -39,87 +37,71 @@ func CreateUser(email, first, middle, last, password string) *users.User {
 		user.LastName = last
 		user.SetPassword(password)
 
-		userCol.ReplaceOne(context.TODO(), filter, user)
+		store.ReplaceUser(ctx, user)
 	}
-	return &user
+	return user
 }
 
 // Retrieve Functions for getting a user or users based on need.
 func GetUserByID(id string) (*users.User, error) {
-	userCol := config.GetCollection(config.DB, "authenticate", "users")
+	return GetUserByIDContext(context.Background(), id)
+}
+
+func GetUserByIDContext(ctx context.Context, id string) (*users.User, error) {
+	ctx, cancel := readContext(ctx)
+	defer cancel()
 	userid, err := primitive.ObjectIDFromHex(id)
 	if err != nil {
 		return nil, err
 	}
 
-	filter := bson.M{
-		"_id": userid,
-	}
-
-	var user users.User
-	err = userCol.FindOne(context.TODO(), filter).Decode(&user)
-	if err != nil {
-		return nil, err
-	}
-	return &user, nil
+	return store.FindUser(ctx, userid)
 }
 
 func GetUserByEMail(email string) (*users.User, error) {
-	userCol := config.GetCollection(config.DB, "authenticate", "users")
-
-	filter := bson.M{
-		"emailAddress": email,
-	}
+	return GetUserByEMailContext(context.Background(), email)
+}
 
-	var user users.User
-	err := userCol.FindOne(context.TODO(), filter).Decode(&user)
-	if err != nil {
-		return nil, err
-	}
-	return &user, nil
+func GetUserByEMailContext(ctx context.Context, email string) (*users.User, error) {
+	ctx, cancel := readContext(ctx)
+	defer cancel()
+	return store.FindUserByEmail(ctx, email)
 }
 
 func GetUsers() ([]users.User, error) {
-	var users []users.User
-
-	userCol := config.GetCollection(config.DB, "authenticate", "users")
-
-	cursor, err := userCol.Find(context.TODO(), bson.M{})
-	if err != nil {
-		return users, err
-	}
+	return GetUsersContext(context.Background())
+}
 
-	if err = cursor.All(context.TODO(), &users); err != nil {
-		return users, err
-	}
-	return users, nil
+func GetUsersContext(ctx context.Context) ([]users.User, error) {
+	ctx, cancel := readContext(ctx)
+	defer cancel()
+	return store.ListUsers(ctx)
 }
 
 // CRUD Update Function
 func UpdateUser(user users.User) error {
-	userCol := config.GetCollection(config.DB, "authenticate", "users")
-
-	filter := bson.M{
-		"_id": user.ID,
-	}
+	return UpdateUserContext(context.Background(), user)
+}
 
-	_, err := userCol.ReplaceOne(context.TODO(), filter, user)
-	return err
+func UpdateUserContext(ctx context.Context, user users.User) error {
+	ctx, cancel := writeContext(ctx)
+	defer cancel()
+	return store.ReplaceUser(ctx, &user)
 }
 
 // CRUD Delete Function
 func DeleteUser(id string) error {
-	userCol := config.GetCollection(config.DB, "authenticate", "users")
+	return DeleteUserContext(context.Background(), id)
+}
 
+func DeleteUserContext(ctx context.Context, id string) error {
+	ctx, cancel := writeContext(ctx)
+	defer cancel()
 	userid, err := primitive.ObjectIDFromHex(id)
 	if err != nil {
 		return err
 	}
 
-	filter := bson.M{
-		"_id": userid,
-	}
-
-	_, err = userCol.DeleteOne(context.TODO(), filter)
+	_, err = store.DeleteUser(ctx, userid)
 	return err
 }
@@ -0,0 +1,40 @@
+package svcs
+
+import (
+	"github.com/erneap/models/v2/employees"
+)
+
+// RejectInvalidEmployees makes the employee creates and updates check the
+// employee with Validate, rejecting the change with the problems found (a
+// employees.ValidationProblems error) when it has errors.  Only the errors
+// the change adds are counted, so an employee whose stored data already has
+// problems can still be updated.
+var RejectInvalidEmployees = true
+
+// checkEmployee provides the validation errors the employee has that the
+// previous version (if any) didn't have.
+func checkEmployee(emp, prev *employees.Employee) error {
+	if !RejectInvalidEmployees {
+		return nil
+	}
+	problems := emp.Validate().Errors()
+	if len(problems) == 0 {
+		return nil
+	}
+	had := make(map[string]bool)
+	if prev != nil {
+		for _, p := range prev.Validate().Errors() {
+			had[p.Path+"|"+p.Kind] = true
+		}
+	}
+	var answer employees.ValidationProblems
+	for _, p := range problems {
+		if !had[p.Path+"|"+p.Kind] {
+			answer = append(answer, p)
+		}
+	}
+	if len(answer) == 0 {
+		return nil
+	}
+	return answer
+}
@@ -0,0 +1,66 @@
+package svcs
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/erneap/models/v2/employees"
+)
+
+func TestUpdateEmployeeValidation(t *testing.T) {
+	SetStore(NewMemoryStore())
+	team := CreateTeam("validate", false)
+	emp := employees.Employee{
+		Name: employees.EmployeeName{FirstName: "V", LastName: "E"},
+	}
+	emp.AddAssignment("s", "w", time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC))
+	e, err := CreateEmployee(emp, "pw", "", team.ID.Hex(), "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// adding and removing schedules, which leaves no rotation days, is allowed.
+	e.Assignments[0].AddSchedule(7)
+	if err := UpdateEmployee(e); err != nil {
+		t.Fatalf("update adding a schedule = %v", err)
+	}
+	e.Assignments[0].RotationDays = 14
+	if err := UpdateEmployee(e); err != nil {
+		t.Fatalf("update setting rotation days = %v", err)
+	}
+	e.Assignments[0].RemoveSchedule(1)
+	if err := UpdateEmployee(e); err != nil {
+		t.Fatalf("update removing a schedule = %v", err)
+	}
+
+	// an update adding an error is rejected.
+	bad := *e
+	bad.Assignments = append([]employees.Assignment{}, e.Assignments...)
+	bad.Assignments[0].EndDate = bad.Assignments[0].StartDate.AddDate(0, 0, -1)
+	err = UpdateEmployee(&bad)
+	var problems employees.ValidationProblems
+	if !errors.Is(err, employees.ErrInvalidEmployee) ||
+		!errors.As(err, &problems) || len(problems) != 1 {
+		t.Fatalf("update error = %v", err)
+	}
+
+	// an employee stored with the error can still be updated.
+	stored, _ := store.FindEmployee(context.Background(), e.ID)
+	stored.Assignments[0].EndDate = bad.Assignments[0].EndDate
+	if err := store.ReplaceEmployee(context.Background(), stored); err != nil {
+		t.Fatal(err)
+	}
+	stored.Email = "v@e"
+	if err := UpdateEmployee(stored); err != nil {
+		t.Errorf("update of an employee already invalid = %v", err)
+	}
+
+	RejectInvalidEmployees = false
+	defer func() { RejectInvalidEmployees = true }()
+	stored.Assignments[0].Schedules = nil
+	if err := UpdateEmployee(stored); err != nil {
+		t.Errorf("update without rejection = %v", err)
+	}
+}
@@ -0,0 +1,46 @@
+package teams
+
+import (
+	"strings"
+	"time"
+
+	"github.com/erneap/models/v2/employees"
+)
+
+// GetApprovalChain provides the chain approving the site's leave requests,
+// the site's own or the team's, or nil when neither has one.
+func (t *Team) GetApprovalChain(siteid string) *employees.ApprovalChain {
+	for _, site := range t.Sites {
+		if strings.EqualFold(site.ID, siteid) && site.Approvals != nil {
+			return site.Approvals
+		}
+	}
+	return t.Approvals
+}
+
+// SetDelegation lets the delegate approve for the approver from start through
+// end, replacing the approver's delegation to the delegate.
+func (t *Team) SetDelegation(approver, delegate string, start,
+	end time.Time) {
+	start = time.Date(start.Year(), start.Month(), start.Day(), 0, 0, 0, 0,
+		time.UTC)
+	end = time.Date(end.Year(), end.Month(), end.Day(), 0, 0, 0, 0, time.UTC)
+	t.RemoveDelegation(approver, delegate)
+	t.Delegations = append(t.Delegations, employees.ApprovalDelegation{
+		Approver:  approver,
+		Delegate:  delegate,
+		StartDate: start,
+		EndDate:   end,
+	})
+}
+
+// RemoveDelegation removes the approver's delegation to the delegate.
+func (t *Team) RemoveDelegation(approver, delegate string) {
+	for i := len(t.Delegations) - 1; i >= 0; i-- {
+		d := t.Delegations[i]
+		if strings.EqualFold(d.Approver, approver) &&
+			strings.EqualFold(d.Delegate, delegate) {
+			t.Delegations = append(t.Delegations[:i], t.Delegations[i+1:]...)
+		}
+	}
+}
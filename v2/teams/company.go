@@ -4,13 +4,16 @@ import (
 	"sort"
 	"strings"
 	"time"
+
+	"github.com/erneap/models/v2/labor"
 )
 
 type CompanyHoliday struct {
-	ID          string      `json:"id" bson:"id"`
-	Name        string      `json:"name" bson:"name"`
-	SortID      uint        `json:"sort" bson:"sort"`
-	ActualDates []time.Time `json:"actualdates,omitempty" bson:"actualdates,omitempty"`
+	ID          string       `json:"id" bson:"id"`
+	Name        string       `json:"name" bson:"name"`
+	SortID      uint         `json:"sort" bson:"sort"`
+	ActualDates []time.Time  `json:"actualdates,omitempty" bson:"actualdates,omitempty"`
+	Rule        *HolidayRule `json:"rule,omitempty" bson:"rule,omitempty"`
 }
 
 type ByCompanyHoliday []CompanyHoliday
@@ -24,15 +27,72 @@ func (c ByCompanyHoliday) Less(i, j int) bool {
 }
 func (c ByCompanyHoliday) Swap(i, j int) { c[i], c[j] = c[j], c[i] }
 
+// GetActual provides the holiday's date in the year, from its actual dates,
+// or for a year without one, computed by its rule.
 func (ch *CompanyHoliday) GetActual(year int) *time.Time {
-	start := time.Date(year, 1, 1, 0, 0, 0, 0, time.UTC)
-	end := time.Date(year+1, 1, 1, 0, 0, 0, 0, time.UTC)
+	if actual := ch.findActual(year); actual != nil {
+		return actual
+	}
+	if ch.Rule != nil {
+		return ch.Rule.GetDate(year)
+	}
+	return nil
+}
+
+// findActual finds the holiday's actual date for the year.  For a holiday
+// with a rule that is the rule's date, which may be observed in the year
+// before or after, or a changed date in the rule date's year that isn't the
+// rule's date for the year before or after.  Without a rule it is the actual
+// date in the year.
+func (ch *CompanyHoliday) findActual(year int) *time.Time {
+	if ch.Rule == nil {
+		for _, actual := range ch.ActualDates {
+			if actual.Year() == year {
+				return &actual
+			}
+		}
+		return nil
+	}
+	date := ch.Rule.GetDate(year)
+	if date == nil {
+		return nil
+	}
+	var answer *time.Time
 	for _, actual := range ch.ActualDates {
-		if (actual.Equal(start) || actual.After(start)) && actual.Before(end) {
+		if actual.Equal(*date) {
 			return &actual
 		}
+		if answer == nil && actual.Year() == date.Year() &&
+			!ch.isRuleDate(actual, year-1) && !ch.isRuleDate(actual, year+1) {
+			answer = &actual
+		}
 	}
-	return nil
+	return answer
+}
+
+// isRuleDate tells if the date is the rule's date for the year.
+func (ch *CompanyHoliday) isRuleDate(date time.Time, year int) bool {
+	ruleDate := ch.Rule.GetDate(year)
+	return ruleDate != nil && ruleDate.Equal(date)
+}
+
+// GenerateDates adds the rule's date to the actual dates for each year from
+// start through end without one, so they can be seen and changed.
+func (ch *CompanyHoliday) GenerateDates(start, end int) {
+	if ch.Rule == nil {
+		return
+	}
+	for year := start; year <= end; year++ {
+		if ch.findActual(year) != nil {
+			continue
+		}
+		if date := ch.Rule.GetDate(year); date != nil {
+			ch.ActualDates = append(ch.ActualDates, *date)
+		}
+	}
+	sort.Slice(ch.ActualDates, func(i, j int) bool {
+		return ch.ActualDates[i].Before(ch.ActualDates[j])
+	})
 }
 
 func (ch *CompanyHoliday) Purge(date time.Time) {
@@ -44,14 +104,16 @@ func (ch *CompanyHoliday) Purge(date time.Time) {
 }
 
 type Company struct {
-	ID             string           `json:"id" bson:"id"`
-	Name           string           `json:"name" bson:"name"`
-	IngestType     string           `json:"ingest" bson:"ingest"`
-	IngestPeriod   int              `json:"ingestPeriod,omitempty" bson:"ingestPeriod,omitempty"`
-	IngestStartDay int              `json:"startDay,omitempty" bson:"startDay,omitempty"`
-	IngestPwd      string           `json:"ingestPwd" bson:"ingestPwd"`
-	Holidays       []CompanyHoliday `json:"holidays,omitempty" bson:"holidays,omitempty"`
-	ModPeriods     []ModPeriod      `json:"modperiods,omitempty" bson:"modperiods,omitempty"`
+	ID             string                `json:"id" bson:"id"`
+	Name           string                `json:"name" bson:"name"`
+	IngestType     string                `json:"ingest" bson:"ingest"`
+	IngestPeriod   int                   `json:"ingestPeriod,omitempty" bson:"ingestPeriod,omitempty"`
+	IngestStartDay int                   `json:"startDay,omitempty" bson:"startDay,omitempty"`
+	IngestPwd      string                `json:"ingestPwd" bson:"ingestPwd"`
+	Holidays       []CompanyHoliday      `json:"holidays,omitempty" bson:"holidays,omitempty"`
+	ModPeriods     []ModPeriod           `json:"modperiods,omitempty" bson:"modperiods,omitempty"`
+	Overtime       *labor.OvertimeRules  `json:"overtime,omitempty" bson:"overtime,omitempty"`
+	Accruals       []labor.AccrualPolicy `json:"accruals,omitempty" bson:"accruals,omitempty"`
 }
 
 type ByCompany []Company
@@ -62,6 +124,74 @@ func (c ByCompany) Less(i, j int) bool {
 }
 func (c ByCompany) Swap(i, j int) { c[i], c[j] = c[j], c[i] }
 
+// GetOvertimeRules provides the company's overtime rules, or the default
+// rules for a company without its own.
+func (c *Company) GetOvertimeRules() labor.OvertimeRules {
+	if c.Overtime == nil {
+		return labor.DefaultOvertimeRules()
+	}
+	return *c.Overtime
+}
+
+// GetAccrualPolicy provides the company's accrual policy for the leave code,
+// the default policy for vacation ("V") without one, or nil.
+func (c *Company) GetAccrualPolicy(code string) *labor.AccrualPolicy {
+	for _, policy := range c.Accruals {
+		if strings.EqualFold(policy.Code, code) {
+			return &policy
+		}
+	}
+	if strings.EqualFold(code, "V") {
+		policy := labor.DefaultAccrualPolicy()
+		return &policy
+	}
+	return nil
+}
+
+// GetHolidayDates provides the actual dates of the company's holidays.
+func (c *Company) GetHolidayDates() []time.Time {
+	var answer []time.Time
+	for _, hol := range c.Holidays {
+		answer = append(answer, hol.ActualDates...)
+	}
+	return answer
+}
+
+// GetHolidayDatesBetween provides the dates of the company's holidays from
+// start through end, with the dates computed by their rules for the years
+// without actual dates.  A holiday observed in the year before or after its
+// own is included by its observed date.
+func (c *Company) GetHolidayDatesBetween(start, end time.Time) []time.Time {
+	var answer []time.Time
+	for year := start.Year() - 1; year <= end.Year()+1; year++ {
+		for _, hol := range c.Holidays {
+			actual := hol.GetActual(year)
+			if actual == nil || actual.Before(start) || actual.After(end) {
+				continue
+			}
+			found := false
+			for _, date := range answer {
+				if date.Equal(*actual) {
+					found = true
+				}
+			}
+			if !found {
+				answer = append(answer, *actual)
+			}
+		}
+	}
+	return answer
+}
+
+// GenerateHolidays fills in the dates of the holidays with rules for the
+// years from start through end.
+func (c *Company) GenerateHolidays(start, end int) {
+	for h, hol := range c.Holidays {
+		hol.GenerateDates(start, end)
+		c.Holidays[h] = hol
+	}
+}
+
 func (c *Company) Purge(date time.Time) {
 	for h, hol := range c.Holidays {
 		hol.Purge(date)
@@ -0,0 +1,91 @@
+package teams
+
+import "time"
+
+// The holiday rule types.  A fixed holiday is on the month and day, an nth
+// weekday holiday on the week's weekday of the month (like the fourth
+// Thursday of November), and a last weekday holiday on the month's last
+// weekday.  A floating holiday has no date of its own; the employee picks the
+// day.
+const (
+	HolidayFixed       = "fixed"
+	HolidayNthWeekday  = "nthweekday"
+	HolidayLastWeekday = "lastweekday"
+	HolidayFloating    = "floating"
+)
+
+// The observed rules, moving a holiday that falls on a weekend.  Nearest moves
+// a Saturday holiday to Friday and a Sunday one to Monday.
+const (
+	ObservedNone    = ""
+	ObservedNearest = "nearest"
+	ObservedMonday  = "monday"
+	ObservedFriday  = "friday"
+)
+
+// HolidayRule computes a holiday's date each year.  Offset moves the date
+// by days (like one for the day after Thanksgiving) before the observed rule
+// is applied.
+type HolidayRule struct {
+	Type     string       `json:"type" bson:"type"`
+	Month    time.Month   `json:"month,omitempty" bson:"month,omitempty"`
+	Day      int          `json:"day,omitempty" bson:"day,omitempty"`
+	Weekday  time.Weekday `json:"weekday,omitempty" bson:"weekday,omitempty"`
+	Week     int          `json:"week,omitempty" bson:"week,omitempty"`
+	Offset   int          `json:"offset,omitempty" bson:"offset,omitempty"`
+	Observed string       `json:"observed,omitempty" bson:"observed,omitempty"`
+}
+
+// GetDate provides the holiday's date in the year, or nil for a floating
+// holiday or a rule without a valid date.
+func (r *HolidayRule) GetDate(year int) *time.Time {
+	if r.Month < time.January || r.Month > time.December {
+		return nil
+	}
+	var date time.Time
+	switch r.Type {
+	case HolidayFixed:
+		date = time.Date(year, r.Month, r.Day, 0, 0, 0, 0, time.UTC)
+		if r.Day < 1 || date.Month() != r.Month {
+			return nil
+		}
+	case HolidayNthWeekday:
+		if r.Week < 1 || r.Week > 5 {
+			return nil
+		}
+		date = time.Date(year, r.Month, 1, 0, 0, 0, 0, time.UTC)
+		for date.Weekday() != r.Weekday {
+			date = date.AddDate(0, 0, 1)
+		}
+		date = date.AddDate(0, 0, 7*(r.Week-1))
+		if date.Month() != r.Month {
+			return nil
+		}
+	case HolidayLastWeekday:
+		date = time.Date(year, r.Month+1, 0, 0, 0, 0, 0, time.UTC)
+		for date.Weekday() != r.Weekday {
+			date = date.AddDate(0, 0, -1)
+		}
+	default:
+		return nil
+	}
+	date = date.AddDate(0, 0, r.Offset)
+
+	switch date.Weekday() {
+	case time.Saturday:
+		switch r.Observed {
+		case ObservedNearest, ObservedFriday:
+			date = date.AddDate(0, 0, -1)
+		case ObservedMonday:
+			date = date.AddDate(0, 0, 2)
+		}
+	case time.Sunday:
+		switch r.Observed {
+		case ObservedNearest, ObservedMonday:
+			date = date.AddDate(0, 0, 1)
+		case ObservedFriday:
+			date = date.AddDate(0, 0, -2)
+		}
+	}
+	return &date
+}
@@ -0,0 +1,224 @@
+package teams
+
+import (
+	"testing"
+	"time"
+)
+
+func date(year int, month time.Month, day int) time.Time {
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+}
+
+func TestHolidayRuleGetDate(t *testing.T) {
+	tests := []struct {
+		name string
+		rule HolidayRule
+		year int
+		// a zero date is no date.
+		want time.Time
+	}{
+		{
+			name: "fixed",
+			rule: HolidayRule{Type: HolidayFixed, Month: time.July, Day: 4},
+			year: 2024,
+			want: date(2024, time.July, 4),
+		},
+		{
+			name: "fixed on saturday observed nearest",
+			rule: HolidayRule{Type: HolidayFixed, Month: time.July, Day: 4,
+				Observed: ObservedNearest},
+			year: 2026,
+			want: date(2026, time.July, 3),
+		},
+		{
+			name: "fixed on sunday observed nearest",
+			rule: HolidayRule{Type: HolidayFixed, Month: time.December, Day: 25,
+				Observed: ObservedNearest},
+			year: 2022,
+			want: date(2022, time.December, 26),
+		},
+		{
+			name: "fixed on sunday observed friday",
+			rule: HolidayRule{Type: HolidayFixed, Month: time.December, Day: 25,
+				Observed: ObservedFriday},
+			year: 2022,
+			want: date(2022, time.December, 23),
+		},
+		{
+			name: "fixed on saturday observed monday",
+			rule: HolidayRule{Type: HolidayFixed, Month: time.December, Day: 25,
+				Observed: ObservedMonday},
+			year: 2021,
+			want: date(2021, time.December, 27),
+		},
+		{
+			name: "fixed observed in the year before",
+			rule: HolidayRule{Type: HolidayFixed, Month: time.January, Day: 1,
+				Observed: ObservedNearest},
+			year: 2022,
+			want: date(2021, time.December, 31),
+		},
+		{
+			name: "fixed on saturday not observed",
+			rule: HolidayRule{Type: HolidayFixed, Month: time.July, Day: 4},
+			year: 2026,
+			want: date(2026, time.July, 4),
+		},
+		{
+			name: "nth weekday",
+			rule: HolidayRule{Type: HolidayNthWeekday, Month: time.November,
+				Weekday: time.Thursday, Week: 4},
+			year: 2024,
+			want: date(2024, time.November, 28),
+		},
+		{
+			name: "nth weekday with offset",
+			rule: HolidayRule{Type: HolidayNthWeekday, Month: time.November,
+				Weekday: time.Thursday, Week: 4, Offset: 1},
+			year: 2024,
+			want: date(2024, time.November, 29),
+		},
+		{
+			name: "fifth weekday",
+			rule: HolidayRule{Type: HolidayNthWeekday, Month: time.February,
+				Weekday: time.Thursday, Week: 5},
+			year: 2024,
+			want: date(2024, time.February, 29),
+		},
+		{
+			name: "fifth weekday missing",
+			rule: HolidayRule{Type: HolidayNthWeekday, Month: time.February,
+				Weekday: time.Thursday, Week: 5},
+			year: 2023,
+		},
+		{
+			name: "last weekday",
+			rule: HolidayRule{Type: HolidayLastWeekday, Month: time.May,
+				Weekday: time.Monday},
+			year: 2024,
+			want: date(2024, time.May, 27),
+		},
+		{
+			name: "invalid day",
+			rule: HolidayRule{Type: HolidayFixed, Month: time.February, Day: 30},
+			year: 2024,
+		},
+		{
+			name: "no month",
+			rule: HolidayRule{Type: HolidayFixed, Day: 1},
+			year: 2024,
+		},
+		{
+			name: "floating",
+			rule: HolidayRule{Type: HolidayFloating, Month: time.January},
+			year: 2024,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tt.rule.GetDate(tt.year)
+			switch {
+			case tt.want.IsZero() && got != nil:
+				t.Errorf("GetDate(%d) = %v, want nil", tt.year, *got)
+			case !tt.want.IsZero() && got == nil:
+				t.Errorf("GetDate(%d) = nil, want %v", tt.year, tt.want)
+			case got != nil && !got.Equal(tt.want):
+				t.Errorf("GetDate(%d) = %v, want %v", tt.year, *got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCompanyHolidayGenerateDates(t *testing.T) {
+	newYears := &HolidayRule{Type: HolidayFixed, Month: time.January, Day: 1,
+		Observed: ObservedNearest}
+	tests := []struct {
+		name   string
+		actual []time.Time
+		start  int
+		end    int
+		want   []time.Time
+	}{
+		{
+			name:  "observed in the year before",
+			start: 2021,
+			end:   2023,
+			want: []time.Time{date(2021, time.January, 1),
+				date(2021, time.December, 31), date(2023, time.January, 2)},
+		},
+		{
+			name: "changed date kept",
+			actual: []time.Time{date(2021, time.January, 4),
+				date(2021, time.December, 31)},
+			start: 2021,
+			end:   2022,
+			want: []time.Time{date(2021, time.January, 4),
+				date(2021, time.December, 31)},
+		},
+		{
+			name:   "next year's date isn't this year's",
+			actual: []time.Time{date(2021, time.December, 31)},
+			start:  2021,
+			end:    2021,
+			want: []time.Time{date(2021, time.January, 1),
+				date(2021, time.December, 31)},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ch := CompanyHoliday{
+				ActualDates: append([]time.Time{}, tt.actual...),
+				Rule:        newYears,
+			}
+			// generating again doesn't add any dates.
+			for i := 0; i < 2; i++ {
+				ch.GenerateDates(tt.start, tt.end)
+				if len(ch.ActualDates) != len(tt.want) {
+					t.Fatalf("run %d: dates = %v, want %v", i+1, ch.ActualDates,
+						tt.want)
+				}
+				for d, want := range tt.want {
+					if !ch.ActualDates[d].Equal(want) {
+						t.Fatalf("run %d: dates = %v, want %v", i+1,
+							ch.ActualDates, tt.want)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestCompanyGetHolidayDatesBetween(t *testing.T) {
+	co := Company{
+		Holidays: []CompanyHoliday{
+			{
+				ID: "H",
+				Rule: &HolidayRule{Type: HolidayFixed, Month: time.January, Day: 1,
+					Observed: ObservedNearest},
+			},
+			{
+				ID: "H",
+				Rule: &HolidayRule{Type: HolidayFixed, Month: time.December,
+					Day: 25, Observed: ObservedNearest},
+			},
+		},
+	}
+	got := co.GetHolidayDatesBetween(date(2021, time.December, 1),
+		date(2021, time.December, 31))
+	want := []time.Time{date(2021, time.December, 24),
+		date(2021, time.December, 31)}
+	if len(got) != len(want) {
+		t.Fatalf("dates = %v, want %v", got, want)
+	}
+	for _, w := range want {
+		found := false
+		for _, g := range got {
+			if g.Equal(w) {
+				found = true
+			}
+		}
+		if !found {
+			t.Errorf("dates = %v, missing %v", got, w)
+		}
+	}
+}
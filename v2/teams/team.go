@@ -5,19 +5,23 @@ import (
 	"strings"
 	"time"
 
+	"github.com/erneap/models/v2/employees"
 	"github.com/erneap/models/v2/labor"
 	"github.com/erneap/models/v2/sites"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
 type Team struct {
-	ID             primitive.ObjectID `json:"id" bson:"_id"`
-	Name           string             `json:"name" bson:"name"`
-	Workcodes      []labor.Workcode   `json:"workcodes" bson:"workcodes"`
-	Sites          []sites.Site       `json:"sites" bson:"sites"`
-	Companies      []Company          `json:"companies,omitempty" bson:"companies,omitempty"`
-	ContactTypes   []ContactType      `json:"contacttypes,omitempty" bson:"contacttypes,omitempty"`
-	SpecialtyTypes []SpecialtyType    `json:"specialties,omitempty" bson:"specialties,omitempty"`
+	ID             primitive.ObjectID             `json:"id" bson:"_id"`
+	Name           string                         `json:"name" bson:"name"`
+	Workcodes      []labor.Workcode               `json:"workcodes" bson:"workcodes"`
+	Sites          []sites.Site                   `json:"sites" bson:"sites"`
+	Companies      []Company                      `json:"companies,omitempty" bson:"companies,omitempty"`
+	ContactTypes   []ContactType                  `json:"contacttypes,omitempty" bson:"contacttypes,omitempty"`
+	SpecialtyTypes []SpecialtyType                `json:"specialties,omitempty" bson:"specialties,omitempty"`
+	Approvals      *employees.ApprovalChain       `json:"approvals,omitempty" bson:"approvals,omitempty"`
+	Delegations    []employees.ApprovalDelegation `json:"delegations,omitempty" bson:"delegations,omitempty"`
+	Version        int64                          `json:"version" bson:"version"`
 }
 
 type ByTeam []Team